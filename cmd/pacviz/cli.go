@@ -0,0 +1,548 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/sjsanc/pacviz/v3/internal/app"
+	"github.com/sjsanc/pacviz/v3/internal/aur"
+	"github.com/sjsanc/pacviz/v3/internal/config"
+	"github.com/sjsanc/pacviz/v3/internal/domain"
+	"github.com/sjsanc/pacviz/v3/internal/repository"
+	"github.com/sjsanc/pacviz/v3/internal/ui/column"
+	"github.com/sjsanc/pacviz/v3/internal/ui/viewport"
+)
+
+// Exit codes of the headless commands. Scripts rely on them, so they must
+// not change.
+const (
+	exitOK       = 0
+	exitError    = 1 // the packages could not be read or written
+	exitUsage    = 2 // unknown command, flag or argument
+	exitNotFound = 3 // no package matched
+)
+
+// subcommand runs a headless command, writing its result to w.
+type subcommand func(cfg *config.Config, w io.Writer, args []string) error
+
+var subcommands = map[string]subcommand{
+	"export": runExport,
+	"list":   runList,
+	"info":   runInfo,
+	"search": runSearch,
+}
+
+// usageError is a bad command line. shown is set when the flag package
+// already printed it along with the usage.
+type usageError struct {
+	err   error
+	shown bool
+}
+
+func (e usageError) Error() string { return e.err.Error() }
+func (e usageError) Unwrap() error { return e.err }
+
+// notFoundError reports that nothing matched. An empty message prints
+// nothing, for listings whose empty output already says so.
+type notFoundError struct {
+	msg string
+}
+
+func (e notFoundError) Error() string { return e.msg }
+
+// errOutput receives the errors of the headless commands.
+var errOutput io.Writer = os.Stderr
+
+// exitCode prints a subcommand's error and returns the matching exit code.
+func exitCode(err error) int {
+	var usage usageError
+	var notFound notFoundError
+	switch {
+	case err == nil, errors.Is(err, flag.ErrHelp):
+		return exitOK
+	case errors.As(err, &usage):
+		if !usage.shown {
+			fmt.Fprintf(errOutput, "Error: %v\n", err)
+		}
+		return exitUsage
+	case errors.As(err, &notFound):
+		if notFound.msg != "" {
+			fmt.Fprintf(errOutput, "Error: %v\n", err)
+		}
+		return exitNotFound
+	default:
+		fmt.Fprintf(errOutput, "Error: %v\n", err)
+		return exitError
+	}
+}
+
+// parseFlags parses flags given before, between or after the positional
+// arguments, so "pacviz search firefox --aur" works, and returns the
+// positional arguments.
+func parseFlags(flags *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := flags.Parse(args); err != nil {
+			return nil, usageError{err: err, shown: true}
+		}
+		args = flags.Args()
+		if len(args) == 0 {
+			return positional, nil
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+// tableFlags are the output flags shared by list and search.
+type tableFlags struct {
+	columns  *string
+	format   *string
+	noHeader *bool
+}
+
+func addTableFlags(flags *flag.FlagSet, columns string) tableFlags {
+	return tableFlags{
+		columns:  flags.String("columns", columns, "Comma-separated columns to print, by identifier (e.g. name,size,install_date)"),
+		format:   flags.String("format", "text", "Output format: text, csv, json or md"),
+		noHeader: flags.Bool("no-header", false, "Leave out the header line of text and csv output"),
+	}
+}
+
+// writeRows prints rows as an aligned text table, or in one of the :export
+// formats.
+func (f tableFlags) writeRows(w io.Writer, rows []*domain.Row) error {
+	columns, err := parseColumns(*f.columns)
+	if err != nil {
+		return err
+	}
+
+	if *f.format == "text" {
+		return writeText(w, rows, columns, !*f.noHeader)
+	}
+	format, ok := domain.ParseTableFormat(*f.format)
+	if !ok {
+		return usageError{err: fmt.Errorf("invalid format %q (valid: text, csv, json, md)", *f.format)}
+	}
+	if *f.noHeader && format == domain.TableCSV {
+		// The CSV writer has no option to skip the header, so drop its first line.
+		w = &skipLineWriter{w: w}
+	}
+	return domain.ExportTable(w, rows, columns, format)
+}
+
+// parseColumns looks up a comma-separated list of column identifiers.
+func parseColumns(list string) ([]*column.Column, error) {
+	all := allColumns()
+
+	var columns []*column.Column
+	for _, name := range strings.Split(list, ",") {
+		col := findColumn(all, column.Type(strings.TrimSpace(name)))
+		if col == nil {
+			return nil, usageError{err: fmt.Errorf("unknown column %q (valid: %s)", name, columnNames(all))}
+		}
+		columns = append(columns, col)
+	}
+	return columns, nil
+}
+
+// allColumns returns the table's columns followed by the info fields the
+// table has no column for, so that any of them can be printed.
+func allColumns() []*column.Column {
+	columns := column.DefaultColumns()
+	for _, field := range infoFields {
+		if findColumn(columns, field.col) == nil {
+			columns = append(columns, &column.Column{Type: field.col, Name: field.label})
+		}
+	}
+	return columns
+}
+
+func findColumn(columns []*column.Column, t column.Type) *column.Column {
+	for _, col := range columns {
+		if col.Type == t {
+			return col
+		}
+	}
+	return nil
+}
+
+func columnNames(columns []*column.Column) string {
+	names := make([]string, len(columns))
+	for i, col := range columns {
+		names[i] = string(col.Type)
+	}
+	return strings.Join(names, ", ")
+}
+
+// writeText prints rows as space-aligned columns. Empty cells print as "-"
+// so that every line has the same number of fields for awk and cut.
+func writeText(w io.Writer, rows []*domain.Row, columns []*column.Column, header bool) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	cells := make([]string, len(columns))
+
+	if header {
+		for i, col := range columns {
+			cells[i] = col.Name
+		}
+		fmt.Fprintln(tw, strings.Join(cells, "\t"))
+	}
+	for i, row := range rows {
+		for j, col := range columns {
+			switch cell := row.Cells[col.Type]; {
+			case col.Type == column.ColIndex:
+				cells[j] = fmt.Sprint(i + 1)
+			case cell == "":
+				cells[j] = "-"
+			default:
+				cells[j] = cell
+			}
+		}
+		fmt.Fprintln(tw, strings.Join(cells, "\t"))
+	}
+
+	if err := tw.Flush(); err != nil {
+		return fmt.Errorf("failed to write table: %w", err)
+	}
+	return nil
+}
+
+// skipLineWriter drops everything up to and including the first newline.
+type skipLineWriter struct {
+	w       io.Writer
+	skipped bool
+}
+
+func (s *skipLineWriter) Write(p []byte) (int, error) {
+	if s.skipped {
+		return s.w.Write(p)
+	}
+	n := len(p)
+	i := bytes.IndexByte(p, '\n')
+	if i < 0 {
+		return n, nil
+	}
+	s.skipped = true
+	if _, err := s.w.Write(p[i+1:]); err != nil {
+		return 0, err
+	}
+	return n, nil
+}
+
+func openRepository(cfg *config.Config) (repository.Repository, error) {
+	repo, err := app.OpenRepository(cfg.Pacman)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize repository: %w", err)
+	}
+	return repo, nil
+}
+
+func newAURClient(cfg *config.Config) *aur.Client {
+	timeout := time.Duration(cfg.AUR.Timeout) * time.Second
+	cacheTTL := time.Duration(cfg.AUR.CacheTTL) * time.Second
+	return aur.NewClient(timeout, cacheTTL)
+}
+
+// runList prints the installed packages of a preset, like the table in the
+// TUI. It exits with exitNotFound when no package matched.
+func runList(cfg *config.Config, w io.Writer, args []string) error {
+	flags := flag.NewFlagSet("list", flag.ContinueOnError)
+	presetName := flags.String("preset", string(domain.PresetAll), "Preset to list: "+presetNames())
+	sortBy := flags.String("sort", string(column.ColName), "Column to sort by")
+	reverse := flags.Bool("reverse", false, "Sort in descending order")
+	filter := flags.String("filter", "", "Only list packages matching a filter, as typed after /")
+	table := addTableFlags(flags, "repo,name,version,size")
+	positional, err := parseFlags(flags, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) > 0 {
+		return usageError{err: fmt.Errorf("unexpected argument %q", positional[0])}
+	}
+
+	var preset *domain.Preset
+	for _, p := range domain.DefaultPresets() {
+		if string(p.Type) == *presetName {
+			preset = &p
+			break
+		}
+	}
+	if preset == nil {
+		return usageError{err: fmt.Errorf("unknown preset %q (valid: %s)", *presetName, presetNames())}
+	}
+	sortCol := findColumn(column.DefaultColumns(), column.Type(*sortBy))
+	if sortCol == nil || !sortCol.Sortable {
+		return usageError{err: fmt.Errorf("cannot sort by %q", *sortBy)}
+	}
+	if _, err := parseColumns(*table.columns); err != nil {
+		return err
+	}
+
+	repo, err := openRepository(cfg)
+	if err != nil {
+		return err
+	}
+	pkgs, err := repo.GetInstalled()
+	if err != nil {
+		return fmt.Errorf("failed to read installed packages: %w", err)
+	}
+
+	// Telling AUR packages from other foreign ones takes an AUR lookup,
+	// which the TUI does when switching to these presets.
+	if (preset.Type == domain.PresetAUR || preset.Type == domain.PresetForeign) && !cfg.AUR.Disabled {
+		if err := markAURPackages(newAURClient(cfg), pkgs); err != nil {
+			return err
+		}
+	}
+
+	var matched []*domain.Package
+	for _, pkg := range pkgs {
+		if preset.Filter(pkg) {
+			matched = append(matched, pkg)
+		}
+	}
+
+	vp := viewport.New()
+	vp.SetRows(domain.PackagesToRows(matched))
+	vp.ApplySort(sortCol.Type, *reverse)
+	if err := vp.ApplyFilter(*filter); err != nil {
+		return usageError{err: fmt.Errorf("invalid filter: %w", err)}
+	}
+
+	if err := table.writeRows(w, vp.VisibleRows); err != nil {
+		return err
+	}
+	if len(vp.VisibleRows) == 0 {
+		return notFoundError{}
+	}
+	return nil
+}
+
+func presetNames() string {
+	var names []string
+	for _, p := range domain.DefaultPresets() {
+		names = append(names, string(p.Type))
+	}
+	return strings.Join(names, ", ")
+}
+
+// markAURPackages marks the foreign packages the AUR knows as AUR packages.
+func markAURPackages(client *aur.Client, pkgs []*domain.Package) error {
+	var foreign []string
+	for _, pkg := range pkgs {
+		if pkg.IsForeign {
+			foreign = append(foreign, pkg.Name)
+		}
+	}
+	if len(foreign) == 0 {
+		return nil
+	}
+
+	found, err := client.Info(foreign)
+	if err != nil {
+		return err
+	}
+	for _, pkg := range pkgs {
+		if found[pkg.Name] {
+			pkg.IsAUR = true
+			pkg.Repository = "aur"
+		}
+	}
+	return nil
+}
+
+// infoFields are the fields pacviz info prints, in pacman -Qi order.
+var infoFields = []struct {
+	label string
+	col   column.Type
+}{
+	{"Repository", column.ColRepo},
+	{"Name", column.ColName},
+	{"Version", column.ColVersion},
+	{"Description", column.ColDescription},
+	{"Architecture", column.ColArchitecture},
+	{"URL", column.ColURL},
+	{"Licenses", column.ColLicenses},
+	{"Groups", column.ColGroups},
+	{"Provides", column.ColProvides},
+	{"Depends On", column.ColDependencies},
+	{"Optional Deps", column.ColOptDepends},
+	{"Required By", column.ColRequired},
+	{"Conflicts With", column.ColConflicts},
+	{"Replaces", column.ColReplaces},
+	{"Installed Size", column.ColSize},
+	{"Packager", column.ColPackager},
+	{"Build Date", column.ColBuildDate},
+	{"Install Date", column.ColInstallDate},
+	{"Install Reason", column.ColInstallReason},
+	{"Latest Version", column.ColNewVersion},
+}
+
+// runInfo prints the details of packages, installed ones first and
+// otherwise from the sync databases. It exits with exitNotFound when one
+// of them does not exist.
+func runInfo(cfg *config.Config, w io.Writer, args []string) error {
+	flags := flag.NewFlagSet("info", flag.ContinueOnError)
+	format := flags.String("format", "text", "Output format: text or json")
+	names, err := parseFlags(flags, args)
+	if err != nil {
+		return err
+	}
+	if len(names) == 0 {
+		return usageError{err: errors.New("no package given")}
+	}
+	if *format != "text" && *format != string(domain.TableJSON) {
+		return usageError{err: fmt.Errorf("invalid format %q (valid: text, json)", *format)}
+	}
+
+	repo, err := openRepository(cfg)
+	if err != nil {
+		return err
+	}
+	installed, err := repo.GetInstalled()
+	if err != nil {
+		return fmt.Errorf("failed to read installed packages: %w", err)
+	}
+	byName := make(map[string]*domain.Package, len(installed))
+	for _, pkg := range installed {
+		byName[pkg.Name] = pkg
+	}
+
+	var pkgs []*domain.Package
+	var missing []string
+	for _, name := range names {
+		pkg, ok := byName[name]
+		if !ok {
+			if pkg, err = findSyncPackage(repo, name); err != nil {
+				return err
+			}
+		}
+		if pkg == nil {
+			missing = append(missing, name)
+			continue
+		}
+		pkgs = append(pkgs, pkg)
+	}
+
+	if len(pkgs) > 0 {
+		rows := domain.PackagesToRows(pkgs)
+		if *format == "text" {
+			err = writeInfo(w, rows)
+		} else {
+			columns := make([]*column.Column, len(infoFields))
+			all := allColumns()
+			for i, field := range infoFields {
+				columns[i] = findColumn(all, field.col)
+			}
+			err = domain.ExportTable(w, rows, columns, domain.TableJSON)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(missing) > 0 {
+		return notFoundError{msg: "package not found: " + strings.Join(missing, ", ")}
+	}
+	return nil
+}
+
+// findSyncPackage looks a package up by its exact name in the sync
+// databases, returning nil when no repository has it.
+func findSyncPackage(repo repository.Repository, name string) (*domain.Package, error) {
+	results, err := repo.Search(name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search sync databases: %w", err)
+	}
+	for _, pkg := range results {
+		if pkg.Name == name {
+			return pkg, nil
+		}
+	}
+	return nil, nil
+}
+
+// writeInfo prints each package as "Label : value" lines, separated by a
+// blank line, with "None" for empty fields like pacman.
+func writeInfo(w io.Writer, rows []*domain.Row) error {
+	width := 0
+	for _, field := range infoFields {
+		width = max(width, len(field.label))
+	}
+
+	var b strings.Builder
+	for i, row := range rows {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		for _, field := range infoFields {
+			value := row.Cells[field.col]
+			switch {
+			case field.col == column.ColInstallDate && row.Package.InstallDate.IsZero(),
+				field.col == column.ColBuildDate && row.Package.BuildDate.IsZero(),
+				field.col == column.ColInstallReason && !row.Package.Installed,
+				value == "":
+				value = "None"
+			}
+			fmt.Fprintf(&b, "%-*s : %s\n", width, field.label, value)
+		}
+	}
+
+	if _, err := io.WriteString(w, b.String()); err != nil {
+		return fmt.Errorf("failed to write package info: %w", err)
+	}
+	return nil
+}
+
+// runSearch searches the sync databases, and the AUR with --aur, like the
+// remote mode of the TUI. It exits with exitNotFound when nothing matched.
+func runSearch(cfg *config.Config, w io.Writer, args []string) error {
+	flags := flag.NewFlagSet("search", flag.ContinueOnError)
+	withAUR := flags.Bool("aur", false, "Search the AUR too")
+	table := addTableFlags(flags, "repo,name,version,installed,description")
+	terms, err := parseFlags(flags, args)
+	if err != nil {
+		return err
+	}
+	if len(terms) == 0 {
+		return usageError{err: errors.New("no search query given")}
+	}
+	if *withAUR && cfg.AUR.Disabled {
+		return usageError{err: errors.New("AUR support is disabled in the config")}
+	}
+	if _, err := parseColumns(*table.columns); err != nil {
+		return err
+	}
+	query := strings.Join(terms, " ")
+
+	repo, err := openRepository(cfg)
+	if err != nil {
+		return err
+	}
+	pkgs, err := repo.Search(query)
+	if err != nil {
+		return fmt.Errorf("failed to search sync databases: %w", err)
+	}
+	if *withAUR {
+		aurPkgs, err := newAURClient(cfg).Search(query)
+		if err != nil {
+			return err
+		}
+		pkgs = app.MergeSearchResults(pkgs, aurPkgs)
+	}
+
+	if err := table.writeRows(w, domain.PackagesToRows(pkgs)); err != nil {
+		return err
+	}
+	if len(pkgs) == 0 {
+		return notFoundError{msg: "no packages found for: " + query}
+	}
+	return nil
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/sjsanc/pacviz/v3/internal/config"
+)
+
+func newTestConfig() *config.Config {
+	cfg := config.DefaultConfig()
+	cfg.Pacman.Fixture = "../../internal/app/testdata/fixture.json"
+	cfg.AUR.Disabled = true
+	return cfg
+}
+
+func TestSubcommands(t *testing.T) {
+	errOutput = io.Discard
+	defer func() { errOutput = os.Stderr }()
+
+	tests := []struct {
+		name     string
+		command  string
+		args     []string
+		wantCode int
+		want     string
+	}{
+		{
+			name:     "list orphans as json",
+			command:  "list",
+			args:     []string{"--preset", "orphans", "--sort", "size", "--columns", "name,size", "--format", "json"},
+			wantCode: exitOK,
+			want:     "[\n  {\"name\": \"libfoo\", \"size\": 1048576}\n]\n",
+		},
+		{
+			name:     "list sorted by size",
+			command:  "list",
+			args:     []string{"-preset", "dependency", "-sort", "size", "-reverse", "-columns", "index,name,size"},
+			wantCode: exitOK,
+			want:     "#  Name      Size\n1  glibc     46.0 MB\n2  bash      9.0 MB\n3  libfoo    1.0 MB\n4  readline  920.0 KB\n",
+		},
+		{
+			name:     "list filtered csv without header",
+			command:  "list",
+			args:     []string{"--filter", "shell", "--columns", "name,install_reason", "--format", "csv", "--no-header"},
+			wantCode: exitOK,
+			want:     "bash,dependency\n",
+		},
+		{
+			name:     "list with no match",
+			command:  "list",
+			args:     []string{"--preset", "aur", "--columns", "name", "--no-header"},
+			wantCode: exitNotFound,
+			want:     "",
+		},
+		{
+			name:     "list unknown preset",
+			command:  "list",
+			args:     []string{"--preset", "nope"},
+			wantCode: exitUsage,
+		},
+		{
+			name:     "list unknown column",
+			command:  "list",
+			args:     []string{"--columns", "name,nope"},
+			wantCode: exitUsage,
+		},
+		{
+			name:     "info falls back to the sync databases",
+			command:  "info",
+			args:     []string{"ripgrep"},
+			wantCode: exitOK,
+			want:     "Name           : ripgrep\n",
+		},
+		{
+			name:     "info reports missing packages",
+			command:  "info",
+			args:     []string{"libfoo", "nope"},
+			wantCode: exitNotFound,
+			want:     "Install Reason : Dependency\n",
+		},
+		{
+			name:     "info without a package",
+			command:  "info",
+			wantCode: exitUsage,
+		},
+		{
+			name:     "search with flags after the query",
+			command:  "search",
+			args:     []string{"lib", "--columns", "name,installed", "--no-header"},
+			wantCode: exitOK,
+			want:     "glibc     Yes\nreadline  Yes\nlibfoo    Yes\n",
+		},
+		{
+			name:     "search without results",
+			command:  "search",
+			args:     []string{"nothing"},
+			wantCode: exitNotFound,
+		},
+		{
+			name:     "search the AUR when it is disabled",
+			command:  "search",
+			args:     []string{"--aur", "yay"},
+			wantCode: exitUsage,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			err := subcommands[tt.command](newTestConfig(), &out, tt.args)
+			if code := exitCode(err); code != tt.wantCode {
+				t.Fatalf("exit code = %d, want %d (err: %v)", code, tt.wantCode, err)
+			}
+			if tt.wantCode == exitOK || tt.want != "" {
+				if !strings.Contains(out.String(), tt.want) {
+					t.Errorf("output = %q, want it to contain %q", out.String(), tt.want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/sjsanc/pacviz/v3/internal/config"
+	"github.com/sjsanc/pacviz/v3/internal/domain"
+)
+
+// runExport writes the explicitly installed packages as a manifest that
+// pacviz import (or :import) can compare another machine against.
+func runExport(cfg *config.Config, w io.Writer, args []string) error {
+	flags := flag.NewFlagSet("export", flag.ContinueOnError)
+	versions := flags.Bool("versions", false, "Pin each package to its installed version")
+	format := flags.String("format", "", "Manifest format, text or toml (default from the output file's extension)")
+	output := flags.String("o", "", "Write the manifest to a file instead of standard output")
+	positional, err := parseFlags(flags, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) > 0 {
+		return usageError{err: fmt.Errorf("unexpected argument %q", positional[0])}
+	}
+
+	manifestFormat := domain.ManifestFormatFor(*output)
+	switch *format {
+	case "":
+	case string(domain.ManifestText), string(domain.ManifestTOML):
+		manifestFormat = domain.ManifestFormat(*format)
+	default:
+		return usageError{err: fmt.Errorf("invalid format %q (valid: text, toml)", *format)}
+	}
+
+	repo, err := openRepository(cfg)
+	if err != nil {
+		return err
+	}
+	pkgs, err := repo.GetInstalled()
+	if err != nil {
+		return fmt.Errorf("failed to read installed packages: %w", err)
+	}
+	manifest := domain.NewManifest(pkgs, *versions)
+
+	if *output == "" {
+		return domain.WriteManifest(w, manifest, manifestFormat)
+	}
+
+	f, err := os.Create(*output)
+	if err != nil {
+		return fmt.Errorf("failed to create manifest: %w", err)
+	}
+	defer f.Close()
+
+	if err := domain.WriteManifest(f, manifest, manifestFormat); err != nil {
+		return err
+	}
+	return f.Close()
+}
@@ -14,6 +14,27 @@ func main() {
 	var configPath string
 	flag.StringVar(&configPath, "c", "", "Path to config file (TOML format)")
 	flag.StringVar(&configPath, "config", "", "Path to config file (TOML format)")
+	var pacmanConf, rootDir, dbPath, cacheDir, logFile, demo, mirror string
+	flag.StringVar(&pacmanConf, "pacman-conf", "", "Path to pacman.conf (default /etc/pacman.conf)")
+	flag.StringVar(&rootDir, "root", "", "Installation root (overrides RootDir in pacman.conf)")
+	flag.StringVar(&dbPath, "dbpath", "", "Database directory (overrides DBPath in pacman.conf)")
+	flag.StringVar(&cacheDir, "cachedir", "", "Package cache directory (overrides CacheDir in pacman.conf)")
+	flag.StringVar(&logFile, "logfile", "", "pacman log read by :history (overrides LogFile in pacman.conf)")
+	flag.StringVar(&demo, "demo", "", "Load packages from a JSON fixture or database copy instead of the system")
+	flag.StringVar(&mirror, "mirror", "", "Mirror URL used by :sync, may contain $repo and $arch")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: pacviz [flags] [command]\n\n")
+		fmt.Fprintf(flag.CommandLine.Output(), "Commands:\n")
+		fmt.Fprintf(flag.CommandLine.Output(), "  list [-preset name] [-sort column] [-columns list]  List installed packages\n")
+		fmt.Fprintf(flag.CommandLine.Output(), "  info <package>...                                  Show package details\n")
+		fmt.Fprintf(flag.CommandLine.Output(), "  search [-aur] <query>                              Search the sync databases, and the AUR with -aur\n")
+		fmt.Fprintf(flag.CommandLine.Output(), "  export [-versions] [-format text|toml] [-o file]   Write the explicit packages as a manifest\n")
+		fmt.Fprintf(flag.CommandLine.Output(), "  import <manifest>                                  Compare the installed packages with a manifest\n\n")
+		fmt.Fprintf(flag.CommandLine.Output(), "Run \"pacviz <command> -h\" for a command's flags. Commands exit with 0 on success,\n")
+		fmt.Fprintf(flag.CommandLine.Output(), "1 on errors, 2 on bad usage and 3 when no package matched.\n\n")
+		fmt.Fprintf(flag.CommandLine.Output(), "Flags:\n")
+		flag.PrintDefaults()
+	}
 	flag.Parse()
 
 	// Load configuration
@@ -23,8 +44,44 @@ func main() {
 		os.Exit(1)
 	}
 
+	// Command-line flags take precedence over the config file
+	if pacmanConf != "" {
+		cfg.Pacman.ConfigPath = pacmanConf
+	}
+	if rootDir != "" {
+		cfg.Pacman.RootDir = rootDir
+	}
+	if dbPath != "" {
+		cfg.Pacman.DBPath = dbPath
+	}
+	if cacheDir != "" {
+		cfg.Pacman.CacheDir = cacheDir
+	}
+	if logFile != "" {
+		cfg.Pacman.LogFile = logFile
+	}
+	if demo != "" {
+		cfg.Pacman.Fixture = demo
+	}
+	if mirror != "" {
+		cfg.Pacman.Mirror = mirror
+	}
+
+	var importPath string
+	switch args := flag.Args(); {
+	case len(args) == 0:
+	case args[0] == "import" && len(args) == 2:
+		importPath = args[1]
+	case subcommands[args[0]] != nil:
+		os.Exit(exitCode(subcommands[args[0]](cfg, os.Stdout, args[1:])))
+	default:
+		flag.Usage()
+		os.Exit(exitUsage)
+	}
+
 	// Create model with loaded config
 	model := app.NewModel(cfg)
+	model.ImportPath = importPath
 
 	p := tea.NewProgram(model, tea.WithAltScreen(), tea.WithMouseCellMotion())
 
@@ -3,13 +3,16 @@ module github.com/sjsanc/pacviz/v3
 go 1.25.4
 
 require (
-	github.com/BurntSushi/toml v1.5.0 // indirect
-	github.com/Jguer/go-alpm/v2 v2.3.1 // indirect
-	github.com/Morganamilo/go-pacmanconf v0.0.0-20210502114700-cff030e927a5 // indirect
+	github.com/BurntSushi/toml v1.5.0
+	github.com/Jguer/go-alpm/v2 v2.3.1
+	github.com/Morganamilo/go-pacmanconf v0.0.0-20210502114700-cff030e927a5
+	github.com/charmbracelet/bubbletea v1.3.10
+	github.com/charmbracelet/lipgloss v1.1.0
+)
+
+require (
 	github.com/aymanbagabas/go-osc52/v2 v2.0.1 // indirect
-	github.com/charmbracelet/bubbletea v1.3.10 // indirect
 	github.com/charmbracelet/colorprofile v0.2.3-0.20250311203215-f60798e515dc // indirect
-	github.com/charmbracelet/lipgloss v1.1.0 // indirect
 	github.com/charmbracelet/x/ansi v0.10.1 // indirect
 	github.com/charmbracelet/x/cellbuf v0.0.13-0.20250311204145-2c3ea96c31dd // indirect
 	github.com/charmbracelet/x/term v0.2.1 // indirect
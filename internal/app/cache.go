@@ -0,0 +1,197 @@
+package app
+
+import (
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/sjsanc/pacviz/v3/internal/domain"
+)
+
+type cacheLoadedMsg struct {
+	filter   string
+	archives []domain.CachedPackage
+	err      error
+}
+
+// OpenCache loads the package cache and shows it, filtered to package names
+// containing filter.
+func (m *Model) OpenCache(filter string) tea.Cmd {
+	repo := m.Repo
+	return func() tea.Msg {
+		archives, err := repo.CachedPackages()
+		return cacheLoadedMsg{filter: filter, archives: archives, err: err}
+	}
+}
+
+// reloadCache refreshes an open cache browser after a transaction, keeping
+// its filter and cursor.
+func (m Model) reloadCache() tea.Cmd {
+	if !m.ShowCache {
+		return nil
+	}
+	return m.OpenCache(m.Cache.Filter)
+}
+
+func (m Model) handleCacheLoaded(msg cacheLoadedMsg) (tea.Model, tea.Cmd) {
+	if msg.err != nil {
+		m.RemoteError = fmt.Sprintf("Failed to read package cache: %v", msg.err)
+		return m, nil
+	}
+	if len(msg.archives) == 0 {
+		m.RemoteError = "Package cache is empty"
+		return m, nil
+	}
+
+	var selected string
+	if archive := m.selectedArchive(); archive != nil {
+		selected = archive.Path
+	}
+
+	m.CloseTree()
+	m.CloseFiles()
+	m.CloseHistory()
+	m.CloseConfigs()
+	m.CloseDiskUsage()
+	m.CloseManifest()
+	m.Cache = domain.NewCacheList(msg.archives)
+	m.Cache.SetFilter(msg.filter)
+	m.ShowCache = true
+	m.ShowDetailPanel = false
+	m.CacheFiltering = false
+	m.CacheOffset = 0
+	if i := m.Cache.Find(selected); i >= 0 {
+		m.setCacheCursor(i)
+	} else {
+		m.setCacheCursor(1) // the first archive, below its package header
+	}
+	return m, nil
+}
+
+func (m *Model) CloseCache() {
+	m.ShowCache = false
+	m.Cache = nil
+	m.CacheFiltering = false
+}
+
+func (m *Model) moveCacheCursor(delta int) {
+	m.setCacheCursor(m.CacheCursor + delta)
+}
+
+func (m *Model) setCacheCursor(index int) {
+	index = min(index, len(m.Cache.Lines)-1)
+	m.CacheCursor = max(index, 0)
+
+	bodyRows := m.listPaneHeight() - 1
+	if m.CacheCursor < m.CacheOffset {
+		m.CacheOffset = m.CacheCursor
+	}
+	if m.CacheCursor >= m.CacheOffset+bodyRows {
+		m.CacheOffset = m.CacheCursor - bodyRows + 1
+	}
+}
+
+// selectedArchive returns the archive under the cursor, or nil on a package
+// header or when the cache browser is closed.
+func (m Model) selectedArchive() *domain.CachedPackage {
+	if !m.ShowCache {
+		return nil
+	}
+	return m.Cache.Archive(m.CacheCursor)
+}
+
+// InitiateDowngrade asks for confirmation to install a cached archive,
+// typically an older version of an installed package (pacman -U).
+func (m *Model) InitiateDowngrade(archive *domain.CachedPackage) {
+	if archive == nil {
+		m.RemoteError = "Select a cached version to install"
+		return
+	}
+	if archive.Installed() {
+		m.RemoteError = archive.Label() + " is already installed"
+		return
+	}
+	if m.transactionRunning() {
+		m.RemoteError = "A transaction is already running"
+		return
+	}
+
+	m.PendingInstall = true
+	m.InstallingPkgs = []string{archive.Label()}
+	m.InstallArchives = []string{archive.Path}
+	m.InstallViaAUR = false
+	m.InstallError = ""
+}
+
+// setCacheFilter narrows the cache browser and keeps the cursor in range.
+func (m *Model) setCacheFilter(filter string) {
+	m.Cache.SetFilter(filter)
+	m.CacheOffset = 0
+	m.setCacheCursor(1)
+}
+
+func (m Model) handleCacheFilterInput(key string) (tea.Model, tea.Cmd) {
+	filter := m.Cache.Filter
+
+	switch key {
+	case "esc":
+		m.CacheFiltering = false
+		m.setCacheFilter("")
+	case "enter":
+		m.CacheFiltering = false
+	case "backspace":
+		if len(filter) > 0 {
+			runes := []rune(filter)
+			m.setCacheFilter(string(runes[:len(runes)-1]))
+		}
+	case "ctrl+u":
+		m.setCacheFilter("")
+	default:
+		if len([]rune(key)) == 1 {
+			m.setCacheFilter(filter + key)
+		}
+	}
+
+	return m, nil
+}
+
+func (m Model) handleCacheInput(key string) (tea.Model, tea.Cmd) {
+	if m.CacheFiltering {
+		return m.handleCacheFilterInput(key)
+	}
+
+	half := max((m.listPaneHeight()-1)/2, 1)
+	m.RemoteError = ""
+
+	switch key {
+	case "ctrl+c", "q":
+		return m, tea.Quit
+	case "esc":
+		if m.Cache.Filter != "" {
+			m.setCacheFilter("")
+		} else {
+			m.CloseCache()
+		}
+	case "c":
+		m.CloseCache()
+	case "/":
+		m.CacheFiltering = true
+	case ":":
+		m.EnterCommandMode()
+	case "up", "k":
+		m.moveCacheCursor(-1)
+	case "down", "j":
+		m.moveCacheCursor(1)
+	case "ctrl+u", "pgup":
+		m.moveCacheCursor(-half)
+	case "ctrl+d", "pgdown":
+		m.moveCacheCursor(half)
+	case "home", "g":
+		m.setCacheCursor(0)
+	case "G", "end":
+		m.setCacheCursor(len(m.Cache.Lines) - 1)
+	case "enter", "d":
+		m.InitiateDowngrade(m.selectedArchive())
+	}
+
+	return m, nil
+}
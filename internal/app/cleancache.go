@@ -0,0 +1,135 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/sjsanc/pacviz/v3/internal/domain"
+)
+
+type cleanupPlannedMsg struct {
+	plan *domain.CacheCleanup
+	err  error
+}
+
+type cleanCompleteMsg struct {
+	count int
+	size  int64
+	err   error
+}
+
+// PlanCleanCache works out what policy would delete from the package cache
+// and shows it for confirmation.
+func (m *Model) PlanCleanCache(policy domain.CachePolicy) tea.Cmd {
+	repo := m.Repo
+	return func() tea.Msg {
+		archives, err := repo.CachedPackages()
+		if err != nil {
+			return cleanupPlannedMsg{err: err}
+		}
+		return cleanupPlannedMsg{plan: domain.PlanCacheCleanup(archives, policy, time.Now())}
+	}
+}
+
+func (m Model) handleCleanupPlanned(msg cleanupPlannedMsg) (tea.Model, tea.Cmd) {
+	if msg.err != nil {
+		m.RemoteError = fmt.Sprintf("Failed to read package cache: %v", msg.err)
+		return m, nil
+	}
+	if len(msg.plan.Remove) == 0 {
+		m.RemoteError = fmt.Sprintf("Nothing to clean (%s)", msg.plan.Policy)
+		return m, nil
+	}
+	if m.transactionRunning() {
+		m.RemoteError = "A transaction is already running"
+		return m, nil
+	}
+
+	m.PendingClean = true
+	m.CleanPlan = msg.plan
+	m.CleanError = ""
+
+	m.StartOutput(fmt.Sprintf("Clean package cache: %d archives (%s reclaimable) [%s]",
+		len(msg.plan.Remove), domain.FormatSize(msg.plan.Size), msg.plan.Policy))
+	m.OutputFollow = false
+	for _, line := range formatCleanupPlan(msg.plan) {
+		m.AppendOutput(line)
+	}
+	return m, nil
+}
+
+// formatCleanupPlan lists each archive a cleanup deletes with its size and
+// the rule that selected it.
+func formatCleanupPlan(plan *domain.CacheCleanup) []string {
+	pathWidth := 0
+	for _, archive := range plan.Remove {
+		pathWidth = max(pathWidth, len(archive.Path))
+	}
+
+	lines := []string{fmt.Sprintf("Archives (%d):", len(plan.Remove))}
+	for i, archive := range plan.Remove {
+		lines = append(lines, fmt.Sprintf("  %s%s  %9s  (%s)",
+			archive.Path, strings.Repeat(" ", pathWidth-len(archive.Path)),
+			domain.FormatSize(archive.Size), plan.Reasons[i]))
+	}
+	return lines
+}
+
+func (m *Model) CancelClean() {
+	m.PendingClean = false
+	m.CleanPlan = nil
+	m.DismissOutput()
+}
+
+// CleanCache deletes the archives of the pending cleanup plan.
+func (m *Model) CleanCache(password string) tea.Cmd {
+	plan := m.CleanPlan
+
+	m.Cleaning = true
+	m.PendingClean = false
+	m.CleanPlan = nil
+	m.CleanError = ""
+	m.StartOutput(fmt.Sprintf("Removing %d cached packages", len(plan.Remove)))
+
+	return tea.Batch(
+		m.doClean(plan, password),
+		tickSpinner(),
+	)
+}
+
+func (m Model) doClean(plan *domain.CacheCleanup, password string) tea.Cmd {
+	return startTransaction(func(out chan<- string) tea.Msg {
+		err := m.Repo.RemoveCachedPackages(plan.Paths(), password, out)
+		return cleanCompleteMsg{count: len(plan.Remove), size: plan.Size, err: err}
+	})
+}
+
+func (m Model) handleCleanComplete(msg cleanCompleteMsg) (tea.Model, tea.Cmd) {
+	m.Cleaning = false
+	m.OutputTitle = fmt.Sprintf("Removed %d cached packages (%s freed)", msg.count, domain.FormatSize(msg.size))
+
+	if msg.err != nil {
+		m.CleanError = msg.err.Error()
+		m.OutputTitle = "Failed to clean package cache"
+	}
+
+	return m, m.reloadCache()
+}
+
+func (m Model) handlePendingCleanInput(key string) (tea.Model, tea.Cmd) {
+	switch key {
+	case "enter":
+		if !IsRunningAsRoot() {
+			m.EnterPasswordMode()
+			return m, nil
+		}
+		return m, m.CleanCache("")
+	case "esc", "ctrl+c":
+		m.CancelClean()
+		return m, nil
+	default:
+		return m.handleOutputPaneInput(key)
+	}
+}
@@ -0,0 +1,301 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/sjsanc/pacviz/v3/internal/domain"
+)
+
+// configDiffContext is the number of unchanged lines shown around each
+// change in a configuration diff.
+const configDiffContext = 3
+
+type configsLoadedMsg struct {
+	orphaned []domain.ConfigFile
+	err      error
+}
+
+type configDiffMsg struct {
+	file domain.ConfigFile
+	diff []string
+	err  error
+}
+
+type configResolvedMsg struct {
+	file    domain.ConfigFile
+	replace bool
+	err     error
+}
+
+// OpenConfigs lists the .pacnew and .pacsave files of the installed
+// packages, and looks for the .pacsave files of removed packages in the
+// background.
+func (m *Model) OpenConfigs() tea.Cmd {
+	repo := m.Repo
+	return func() tea.Msg {
+		orphaned, err := repo.OrphanedConfigs()
+		return configsLoadedMsg{orphaned: orphaned, err: err}
+	}
+}
+
+func (m Model) handleConfigsLoaded(msg configsLoadedMsg) (tea.Model, tea.Cmd) {
+	if msg.err != nil {
+		m.RemoteError = fmt.Sprintf("Failed to find .pacsave files: %v", msg.err)
+		return m, nil
+	}
+
+	files := append(domain.PendingConfigFiles(m.installedPackages()), msg.orphaned...)
+	if len(files) == 0 {
+		m.RemoteError = "No .pacnew or .pacsave files to merge"
+		return m, nil
+	}
+
+	m.CloseTree()
+	m.CloseFiles()
+	m.CloseCache()
+	m.CloseHistory()
+	m.CloseDiskUsage()
+	m.CloseManifest()
+	m.ConfigFiles = files
+	m.ShowConfigs = true
+	m.ShowDetailPanel = false
+	m.ConfigOffset = 0
+	m.setConfigCursor(0)
+	return m, nil
+}
+
+func (m *Model) CloseConfigs() {
+	m.ShowConfigs = false
+	m.ConfigFiles = nil
+}
+
+func (m *Model) moveConfigCursor(delta int) {
+	m.setConfigCursor(m.ConfigCursor + delta)
+}
+
+func (m *Model) setConfigCursor(index int) {
+	index = min(index, len(m.ConfigFiles)-1)
+	m.ConfigCursor = max(index, 0)
+
+	bodyRows := m.listPaneHeight() - 1
+	if m.ConfigCursor < m.ConfigOffset {
+		m.ConfigOffset = m.ConfigCursor
+	}
+	if m.ConfigCursor >= m.ConfigOffset+bodyRows {
+		m.ConfigOffset = m.ConfigCursor - bodyRows + 1
+	}
+}
+
+// selectedConfig returns the file under the cursor, or nil when the list is
+// closed or empty.
+func (m Model) selectedConfig() *domain.ConfigFile {
+	if !m.ShowConfigs || m.ConfigCursor >= len(m.ConfigFiles) {
+		return nil
+	}
+	return &m.ConfigFiles[m.ConfigCursor]
+}
+
+// ShowConfigDiff compares a live configuration file with its .pacnew or
+// .pacsave file. A missing live file, usual for a .pacsave left by a
+// removed package, compares as empty.
+func (m *Model) ShowConfigDiff(file domain.ConfigFile) tea.Cmd {
+	repo := m.Repo
+	return func() tea.Msg {
+		live, err := repo.ReadConfigFile(file.Path)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return configDiffMsg{file: file, err: err}
+		}
+		pending, err := repo.ReadConfigFile(file.New)
+		if err != nil {
+			return configDiffMsg{file: file, err: err}
+		}
+		return configDiffMsg{file: file, diff: domain.UnifiedDiff(file.Path, file.New, live, pending, configDiffContext)}
+	}
+}
+
+func (m Model) handleConfigDiff(msg configDiffMsg) (tea.Model, tea.Cmd) {
+	if msg.err != nil {
+		m.RemoteError = fmt.Sprintf("Failed to compare %s: %v", msg.file.New, msg.err)
+		return m, nil
+	}
+
+	file := msg.file
+	m.ConfigDiff = &file
+	m.StartOutput(fmt.Sprintf("%s (%s)", file.New, file.Owner()))
+	m.OutputFollow = false
+	if len(msg.diff) == 0 {
+		m.AppendOutput(fmt.Sprintf("%s is identical to %s", file.New, file.Path))
+	}
+	for _, line := range msg.diff {
+		m.AppendOutput(line)
+	}
+	return m, nil
+}
+
+func (m *Model) closeConfigDiff() {
+	m.ConfigDiff = nil
+	m.DismissOutput()
+}
+
+// InitiateConfigResolve asks for confirmation to replace the live file with
+// file.New, or to delete file.New and keep the live file.
+func (m *Model) InitiateConfigResolve(file *domain.ConfigFile, replace bool) {
+	if file == nil {
+		return
+	}
+	if m.transactionRunning() {
+		m.RemoteError = "A transaction is already running"
+		return
+	}
+
+	target := *file
+	m.PendingConfig = true
+	m.ConfigTarget = &target
+	m.ConfigReplace = replace
+	m.ConfigError = ""
+}
+
+func (m *Model) CancelConfigResolve() {
+	m.PendingConfig = false
+	m.ConfigTarget = nil
+}
+
+// describeConfigAction names the pending action, as "replace /etc/foo with
+// /etc/foo.pacnew" or "delete /etc/foo.pacnew".
+func describeConfigAction(file *domain.ConfigFile, replace bool) string {
+	if replace {
+		return fmt.Sprintf("replace %s with %s", file.Path, file.New)
+	}
+	return "delete " + file.New
+}
+
+// ResolveConfig carries out the pending replace or delete.
+func (m *Model) ResolveConfig(password string) tea.Cmd {
+	file, replace := *m.ConfigTarget, m.ConfigReplace
+
+	m.ResolvingConfig = true
+	m.PendingConfig = false
+	m.ConfigDiff = nil
+	m.ConfigError = ""
+	if replace {
+		m.StartOutput("Replacing " + file.Path)
+	} else {
+		m.StartOutput("Deleting " + file.New)
+	}
+
+	return tea.Batch(
+		m.doResolveConfig(file, replace, password),
+		tickSpinner(),
+	)
+}
+
+func (m Model) doResolveConfig(file domain.ConfigFile, replace bool, password string) tea.Cmd {
+	return startTransaction(func(out chan<- string) tea.Msg {
+		err := m.Repo.ResolveConfigFile(file, replace, password, out)
+		return configResolvedMsg{file: file, replace: replace, err: err}
+	})
+}
+
+func (m Model) handleConfigResolved(msg configResolvedMsg) (tea.Model, tea.Cmd) {
+	m.ResolvingConfig = false
+	m.ConfigTarget = nil
+
+	if msg.err != nil {
+		m.ConfigError = msg.err.Error()
+		m.OutputTitle = "Failed to " + describeConfigAction(&msg.file, msg.replace)
+		return m, nil
+	}
+
+	if msg.replace {
+		m.OutputTitle = fmt.Sprintf("Replaced %s with its %s", msg.file.Path, msg.file.Kind())
+	} else {
+		m.OutputTitle = "Deleted " + msg.file.New
+	}
+
+	// Drop the file from the open list rather than waiting for the reload.
+	for i, file := range m.ConfigFiles {
+		if file.New == msg.file.New {
+			m.ConfigFiles = append(m.ConfigFiles[:i:i], m.ConfigFiles[i+1:]...)
+			break
+		}
+	}
+	if m.ShowConfigs {
+		m.setConfigCursor(m.ConfigCursor)
+	}
+
+	return m, m.refreshRepository(true)
+}
+
+func (m Model) handlePendingConfigInput(key string) (tea.Model, tea.Cmd) {
+	switch key {
+	case "enter":
+		if !IsRunningAsRoot() {
+			m.EnterPasswordMode()
+			return m, nil
+		}
+		return m, m.ResolveConfig("")
+	case "esc", "ctrl+c":
+		m.CancelConfigResolve()
+		return m, nil
+	default:
+		if m.ShowOutput {
+			return m.handleOutputPaneInput(key)
+		}
+		return m, nil
+	}
+}
+
+// handleConfigDiffInput adds the replace and delete actions to the output
+// pane while it shows a configuration diff. Closing it keeps both files.
+func (m Model) handleConfigDiffInput(key string) (tea.Model, tea.Cmd) {
+	switch key {
+	case "r":
+		m.InitiateConfigResolve(m.ConfigDiff, true)
+	case "x":
+		m.InitiateConfigResolve(m.ConfigDiff, false)
+	case "enter", "esc":
+		m.closeConfigDiff()
+	default:
+		return m.handleOutputPaneInput(key)
+	}
+	return m, nil
+}
+
+func (m Model) handleConfigsInput(key string) (tea.Model, tea.Cmd) {
+	half := max((m.listPaneHeight()-1)/2, 1)
+	m.RemoteError = ""
+
+	switch key {
+	case "ctrl+c", "q":
+		return m, tea.Quit
+	case "esc":
+		m.CloseConfigs()
+	case ":":
+		m.EnterCommandMode()
+	case "up", "k":
+		m.moveConfigCursor(-1)
+	case "down", "j":
+		m.moveConfigCursor(1)
+	case "ctrl+u", "pgup":
+		m.moveConfigCursor(-half)
+	case "ctrl+d", "pgdown":
+		m.moveConfigCursor(half)
+	case "home", "g":
+		m.setConfigCursor(0)
+	case "G", "end":
+		m.setConfigCursor(len(m.ConfigFiles) - 1)
+	case "enter", "d":
+		if file := m.selectedConfig(); file != nil {
+			return m, m.ShowConfigDiff(*file)
+		}
+	case "r":
+		m.InitiateConfigResolve(m.selectedConfig(), true)
+	case "x":
+		m.InitiateConfigResolve(m.selectedConfig(), false)
+	}
+
+	return m, nil
+}
@@ -0,0 +1,158 @@
+package app
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/sjsanc/pacviz/v3/internal/domain"
+)
+
+type diskUsageMsg struct {
+	usage  *domain.DiskUsage
+	reload bool // refresh an open view rather than open it
+}
+
+// OpenDiskUsage computes the disk usage breakdown of the installed packages
+// in the background, since every explicit package's footprint walks the
+// dependency graph.
+func (m *Model) OpenDiskUsage() tea.Cmd {
+	installed := m.installedPackages()
+	return func() tea.Msg {
+		return diskUsageMsg{usage: domain.NewDiskUsage(installed)}
+	}
+}
+
+// reloadDiskUsage refreshes an open disk usage view after the package list
+// changes, keeping its section and cursor.
+func (m Model) reloadDiskUsage() tea.Cmd {
+	if !m.ShowDiskUsage {
+		return nil
+	}
+	installed := m.installedPackages()
+	return func() tea.Msg {
+		return diskUsageMsg{usage: domain.NewDiskUsage(installed), reload: true}
+	}
+}
+
+func (m Model) handleDiskUsage(msg diskUsageMsg) (tea.Model, tea.Cmd) {
+	if msg.reload {
+		if m.ShowDiskUsage {
+			m.DiskUsage = msg.usage
+			m.setUsageCursor(m.UsageCursor)
+		}
+		return m, nil
+	}
+
+	if msg.usage.Packages == 0 {
+		m.RemoteError = "No installed packages"
+		return m, nil
+	}
+
+	m.CloseTree()
+	m.CloseFiles()
+	m.CloseCache()
+	m.CloseHistory()
+	m.CloseConfigs()
+	m.CloseManifest()
+	m.DiskUsage = msg.usage
+	m.ShowDiskUsage = true
+	m.ShowDetailPanel = false
+	m.UsageSection = domain.UsageLargest
+	m.UsageOffset = 0
+	m.setUsageCursor(0)
+	return m, nil
+}
+
+func (m *Model) CloseDiskUsage() {
+	m.ShowDiskUsage = false
+	m.DiskUsage = nil
+}
+
+func (m *Model) moveUsageCursor(delta int) {
+	m.setUsageCursor(m.UsageCursor + delta)
+}
+
+func (m *Model) setUsageCursor(index int) {
+	index = min(index, m.DiskUsage.Len(m.UsageSection)-1)
+	m.UsageCursor = max(index, 0)
+
+	bodyRows := m.listPaneHeight() - 1
+	if m.UsageCursor < m.UsageOffset {
+		m.UsageOffset = m.UsageCursor
+	}
+	if m.UsageCursor >= m.UsageOffset+bodyRows {
+		m.UsageOffset = m.UsageCursor - bodyRows + 1
+	}
+}
+
+// setUsageSection switches the disk usage view to another list.
+func (m *Model) setUsageSection(section domain.UsageSection) {
+	m.UsageSection = section
+	m.UsageOffset = 0
+	m.setUsageCursor(0)
+}
+
+// selectedFootprint returns the footprint under the cursor, or nil outside
+// the largest packages section.
+func (m Model) selectedFootprint() *domain.Footprint {
+	if !m.ShowDiskUsage || m.UsageSection != domain.UsageLargest || m.UsageCursor >= len(m.DiskUsage.Footprints) {
+		return nil
+	}
+	return &m.DiskUsage.Footprints[m.UsageCursor]
+}
+
+// openUsageEntry jumps to the package under the cursor, or switches to the
+// preset under it.
+func (m *Model) openUsageEntry() tea.Cmd {
+	if fp := m.selectedFootprint(); fp != nil {
+		return m.JumpToPackage(fp.Package.Name)
+	}
+	if m.UsageSection != domain.UsagePresets || m.UsageCursor >= len(m.DiskUsage.Presets) {
+		return nil
+	}
+
+	name := m.DiskUsage.Presets[m.UsageCursor].Name
+	for _, preset := range m.Presets {
+		if preset.Name == name {
+			m.CloseDiskUsage()
+			_, cmd := m.SetPreset(string(preset.Type))
+			return cmd
+		}
+	}
+	return nil
+}
+
+func (m Model) handleDiskUsageInput(key string) (tea.Model, tea.Cmd) {
+	half := max((m.listPaneHeight()-1)/2, 1)
+	m.RemoteError = ""
+
+	switch key {
+	case "ctrl+c", "q":
+		return m, tea.Quit
+	case "esc", "D":
+		m.CloseDiskUsage()
+	case ":":
+		m.EnterCommandMode()
+	case "tab":
+		m.setUsageSection(m.UsageSection.Next())
+	case "up", "k":
+		m.moveUsageCursor(-1)
+	case "down", "j":
+		m.moveUsageCursor(1)
+	case "ctrl+u", "pgup":
+		m.moveUsageCursor(-half)
+	case "ctrl+d", "pgdown":
+		m.moveUsageCursor(half)
+	case "home", "g":
+		m.setUsageCursor(0)
+	case "G", "end":
+		m.setUsageCursor(m.DiskUsage.Len(m.UsageSection) - 1)
+	case "enter":
+		return m, m.openUsageEntry()
+	case "r":
+		// Preview pacman -Rs: the confirmation shows what would be freed.
+		if fp := m.selectedFootprint(); fp != nil {
+			m.InitiateRemoval([]*domain.Package{fp.Package}, domain.RemoveOptions{Recursive: true})
+		}
+	}
+
+	return m, nil
+}
@@ -0,0 +1,62 @@
+package app
+
+import (
+	"fmt"
+	"os"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/sjsanc/pacviz/v3/internal/domain"
+	"github.com/sjsanc/pacviz/v3/internal/ui/column"
+)
+
+type tableExportedMsg struct {
+	path  string
+	count int
+	err   error
+}
+
+// ExportTable writes the visible rows, with the visible columns in their
+// current order, to path. An empty format follows the file extension.
+func (m *Model) ExportTable(path, format string) tea.Cmd {
+	tableFormat, ok := domain.ParseTableFormat(format)
+	if format == "" {
+		tableFormat, ok = domain.TableFormatFor(path)
+	}
+	if !ok {
+		m.RemoteError = fmt.Sprintf("Cannot tell the format of %s; add csv, json or md", path)
+		return nil
+	}
+
+	rows := m.Viewport.VisibleRows
+	var columns []*column.Column
+	for _, col := range m.Viewport.Columns {
+		if col.Visible {
+			columns = append(columns, col)
+		}
+	}
+
+	return func() tea.Msg {
+		return tableExportedMsg{path: path, count: len(rows), err: writeTable(path, rows, columns, tableFormat)}
+	}
+}
+
+func writeTable(path string, rows []*domain.Row, columns []*column.Column, format domain.TableFormat) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", path, err)
+	}
+	if err := domain.ExportTable(f, rows, columns, format); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func (m Model) handleTableExported(msg tableExportedMsg) (tea.Model, tea.Cmd) {
+	if msg.err != nil {
+		m.RemoteError = fmt.Sprintf("Export failed: %v", msg.err)
+		return m, nil
+	}
+	m.RemoteError = fmt.Sprintf("Exported %d packages to %s", msg.count, msg.path)
+	return m, nil
+}
@@ -0,0 +1,187 @@
+package app
+
+import (
+	"encoding/base64"
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/sjsanc/pacviz/v3/internal/domain"
+)
+
+type filesLoadedMsg struct {
+	pkgName string
+	files   []domain.PackageFile
+	err     error
+}
+
+// OpenFiles loads the file list of pkg and shows it once loaded.
+func (m *Model) OpenFiles(pkg *domain.Package) tea.Cmd {
+	if pkg == nil {
+		m.RemoteError = "No package selected"
+		return nil
+	}
+	if !pkg.Installed {
+		m.RemoteError = "File list is only available for installed packages"
+		return nil
+	}
+
+	repo := m.Repo
+	name := pkg.Name
+	return func() tea.Msg {
+		files, err := repo.Files(name)
+		return filesLoadedMsg{pkgName: name, files: files, err: err}
+	}
+}
+
+func (m Model) handleFilesLoaded(msg filesLoadedMsg) (tea.Model, tea.Cmd) {
+	if msg.err != nil {
+		m.RemoteError = fmt.Sprintf("Failed to list files: %v", msg.err)
+		return m, nil
+	}
+
+	m.FileList = domain.NewFileTree(msg.pkgName, msg.files)
+	m.ShowFiles = true
+	m.ShowDetailPanel = false
+	m.FilesCursor = 0
+	m.FilesOffset = 0
+	m.FilesFiltering = false
+	return m, nil
+}
+
+func (m *Model) CloseFiles() {
+	m.ShowFiles = false
+	m.FileList = nil
+	m.FilesFiltering = false
+}
+
+func (m *Model) moveFilesCursor(delta int) {
+	m.setFilesCursor(m.FilesCursor + delta)
+}
+
+func (m *Model) setFilesCursor(index int) {
+	index = min(index, len(m.FileList.Nodes)-1)
+	m.FilesCursor = max(index, 0)
+
+	bodyRows := m.listPaneHeight() - 1
+	if m.FilesCursor < m.FilesOffset {
+		m.FilesOffset = m.FilesCursor
+	}
+	if m.FilesCursor >= m.FilesOffset+bodyRows {
+		m.FilesOffset = m.FilesCursor - bodyRows + 1
+	}
+}
+
+// selectedFile returns the file under the cursor, if the list is open.
+func (m Model) selectedFile() *domain.PackageFile {
+	if !m.ShowFiles || m.FilesCursor >= len(m.FileList.Nodes) {
+		return nil
+	}
+	return &m.FileList.Nodes[m.FilesCursor].File
+}
+
+// CopySelectedPath puts the path under the cursor on the clipboard.
+func (m *Model) CopySelectedPath() {
+	file := m.selectedFile()
+	if file == nil {
+		m.RemoteError = "No file selected"
+		return
+	}
+	m.RemoteError = "Copied " + file.Path
+	m.Clipboard = clipboardSequence(file.Path)
+}
+
+// clipboardSequence is the OSC 52 escape sequence that asks the terminal to
+// set the system clipboard, which also works over SSH. View emits it with
+// the next frame so it never interleaves with the renderer's own output.
+func clipboardSequence(text string) string {
+	return "\x1b]52;c;" + base64.StdEncoding.EncodeToString([]byte(text)) + "\a"
+}
+
+// setFilesFilter narrows the file list and keeps the cursor in range.
+func (m *Model) setFilesFilter(filter string) {
+	m.FileList.SetFilter(filter)
+	m.FilesOffset = 0
+	m.setFilesCursor(0)
+}
+
+func (m Model) handleFilesFilterInput(key string) (tea.Model, tea.Cmd) {
+	filter := m.FileList.Filter
+
+	switch key {
+	case "esc":
+		m.FilesFiltering = false
+		m.setFilesFilter("")
+	case "enter":
+		m.FilesFiltering = false
+	case "backspace":
+		if len(filter) > 0 {
+			runes := []rune(filter)
+			m.setFilesFilter(string(runes[:len(runes)-1]))
+		}
+	case "ctrl+u":
+		m.setFilesFilter("")
+	default:
+		if len([]rune(key)) == 1 {
+			m.setFilesFilter(filter + key)
+		}
+	}
+
+	return m, nil
+}
+
+func (m Model) handleFilesInput(key string) (tea.Model, tea.Cmd) {
+	if m.FilesFiltering {
+		return m.handleFilesFilterInput(key)
+	}
+
+	half := max((m.listPaneHeight()-1)/2, 1)
+	list := m.FileList
+	m.RemoteError = ""
+
+	switch key {
+	case "ctrl+c", "q":
+		return m, tea.Quit
+	case "esc":
+		if list.Filter != "" {
+			m.setFilesFilter("")
+		} else {
+			m.CloseFiles()
+		}
+	case "f":
+		m.CloseFiles()
+	case "/":
+		m.FilesFiltering = true
+	case ":":
+		m.EnterCommandMode()
+	case "up", "k":
+		m.moveFilesCursor(-1)
+	case "down", "j":
+		m.moveFilesCursor(1)
+	case "ctrl+u", "pgup":
+		m.moveFilesCursor(-half)
+	case "ctrl+d", "pgdown":
+		m.moveFilesCursor(half)
+	case "home", "g":
+		m.setFilesCursor(0)
+	case "G", "end":
+		m.setFilesCursor(len(list.Nodes) - 1)
+	case "right", "l":
+		if m.FilesCursor < len(list.Nodes) && list.Nodes[m.FilesCursor].Expanded {
+			m.moveFilesCursor(1)
+		} else {
+			list.Expand(m.FilesCursor)
+		}
+	case "left", "h":
+		if !list.Collapse(m.FilesCursor) {
+			if parent := list.Parent(m.FilesCursor); parent >= 0 {
+				m.setFilesCursor(parent)
+			}
+		}
+	case " ", "space", "enter":
+		list.Toggle(m.FilesCursor)
+	case "y":
+		m.CopySelectedPath()
+	}
+
+	return m, nil
+}
@@ -0,0 +1,218 @@
+package app
+
+import (
+	"fmt"
+	"log"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/sjsanc/pacviz/v3/internal/domain"
+	"github.com/sjsanc/pacviz/v3/internal/repository"
+)
+
+// maxHistoryEvents is how many events the detail panel shows before
+// deferring to :history.
+const maxHistoryEvents = 5
+
+type historyLoadedMsg struct {
+	transactions []domain.Transaction
+	summaries    map[string][]string // detail panel lines by package name
+	err          error
+	open         bool // show the history browser once loaded
+	filter       string
+}
+
+// loadHistory reads pacman.log in the background for the detail panel.
+func (m Model) loadHistory() tea.Msg {
+	return readHistory(m.Repo)
+}
+
+// OpenHistory loads pacman.log and shows the history browser, filtered by
+// package names and dates as described by domain.ParseHistoryFilter.
+func (m *Model) OpenHistory(filter string) tea.Cmd {
+	repo := m.Repo
+	return func() tea.Msg {
+		msg := readHistory(repo)
+		msg.open, msg.filter = true, filter
+		return msg
+	}
+}
+
+// readHistory reads pacman.log, which the repository only parses again once
+// it changes, and prepares each package's detail panel summary.
+func readHistory(repo repository.Repository) historyLoadedMsg {
+	transactions, err := repo.History()
+	if err != nil {
+		return historyLoadedMsg{err: err}
+	}
+	return historyLoadedMsg{transactions: transactions, summaries: historySummaries(transactions)}
+}
+
+func (m Model) handleHistoryLoaded(msg historyLoadedMsg) (tea.Model, tea.Cmd) {
+	if msg.err != nil {
+		if msg.open {
+			m.RemoteError = fmt.Sprintf("Failed to read package history: %v", msg.err)
+		} else {
+			log.Printf("Failed to read package history: %v", msg.err)
+		}
+		return m, nil
+	}
+
+	m.HistorySummaries = msg.summaries
+
+	if !msg.open {
+		// Keep an open browser in step with the log after a transaction.
+		if m.ShowHistory {
+			filter := m.History.Filter
+			m.History = domain.NewHistoryList(msg.transactions)
+			m.History.SetFilter(filter)
+			m.setHistoryCursor(m.HistoryCursor)
+		}
+		return m, nil
+	}
+
+	if len(msg.transactions) == 0 {
+		m.RemoteError = "No package history in pacman.log"
+		return m, nil
+	}
+
+	m.CloseTree()
+	m.CloseFiles()
+	m.CloseCache()
+	m.CloseConfigs()
+	m.CloseDiskUsage()
+	m.CloseManifest()
+	m.History = domain.NewHistoryList(msg.transactions)
+	m.History.SetFilter(msg.filter)
+	m.ShowHistory = true
+	m.ShowDetailPanel = false
+	m.HistoryFiltering = false
+	m.HistoryOffset = 0
+	m.setHistoryCursor(1) // the first event, below its transaction header
+	return m, nil
+}
+
+func (m *Model) CloseHistory() {
+	m.ShowHistory = false
+	m.History = nil
+	m.HistoryFiltering = false
+}
+
+func (m *Model) moveHistoryCursor(delta int) {
+	m.setHistoryCursor(m.HistoryCursor + delta)
+}
+
+func (m *Model) setHistoryCursor(index int) {
+	index = min(index, len(m.History.Lines)-1)
+	m.HistoryCursor = max(index, 0)
+
+	bodyRows := m.listPaneHeight() - 1
+	if m.HistoryCursor < m.HistoryOffset {
+		m.HistoryOffset = m.HistoryCursor
+	}
+	if m.HistoryCursor >= m.HistoryOffset+bodyRows {
+		m.HistoryOffset = m.HistoryCursor - bodyRows + 1
+	}
+}
+
+// historySummary returns the detail panel's most recent changes to pkg.
+func (m Model) historySummary(pkg *domain.Package) []string {
+	if pkg == nil {
+		return nil
+	}
+	return m.HistorySummaries[pkg.Name]
+}
+
+// historySummaries lists each package's most recent changes for the detail
+// panel, deferring to :history beyond maxHistoryEvents.
+func historySummaries(transactions []domain.Transaction) map[string][]string {
+	summaries := make(map[string][]string)
+	for name, events := range domain.HistoryByPackage(transactions) {
+		lines := make([]string, 0, maxHistoryEvents+1)
+		for _, event := range events[:min(len(events), maxHistoryEvents)] {
+			lines = append(lines, fmt.Sprintf("%s  %s %s", event.Time.Format("2006-01-02 15:04"), event.Action, event.Versions()))
+		}
+		if len(events) > maxHistoryEvents {
+			lines = append(lines, fmt.Sprintf("… and %d more (:history %s)", len(events)-maxHistoryEvents, name))
+		}
+		summaries[name] = lines
+	}
+	return summaries
+}
+
+// setHistoryFilter narrows the history browser and keeps the cursor in range.
+func (m *Model) setHistoryFilter(filter string) {
+	m.History.SetFilter(filter)
+	m.HistoryOffset = 0
+	m.setHistoryCursor(1)
+}
+
+func (m Model) handleHistoryFilterInput(key string) (tea.Model, tea.Cmd) {
+	filter := m.History.Filter
+
+	switch key {
+	case "esc":
+		m.HistoryFiltering = false
+		m.setHistoryFilter("")
+	case "enter":
+		m.HistoryFiltering = false
+	case "backspace":
+		if len(filter) > 0 {
+			runes := []rune(filter)
+			m.setHistoryFilter(string(runes[:len(runes)-1]))
+		}
+	case "ctrl+u":
+		m.setHistoryFilter("")
+	default:
+		if len([]rune(key)) == 1 {
+			m.setHistoryFilter(filter + key)
+		} else if key == "space" {
+			m.setHistoryFilter(filter + " ")
+		}
+	}
+
+	return m, nil
+}
+
+func (m Model) handleHistoryInput(key string) (tea.Model, tea.Cmd) {
+	if m.HistoryFiltering {
+		return m.handleHistoryFilterInput(key)
+	}
+
+	half := max((m.listPaneHeight()-1)/2, 1)
+	m.RemoteError = ""
+
+	switch key {
+	case "ctrl+c", "q":
+		return m, tea.Quit
+	case "esc":
+		if m.History.Filter != "" {
+			m.setHistoryFilter("")
+		} else {
+			m.CloseHistory()
+		}
+	case "H":
+		m.CloseHistory()
+	case "/":
+		m.HistoryFiltering = true
+	case ":":
+		m.EnterCommandMode()
+	case "up", "k":
+		m.moveHistoryCursor(-1)
+	case "down", "j":
+		m.moveHistoryCursor(1)
+	case "ctrl+u", "pgup":
+		m.moveHistoryCursor(-half)
+	case "ctrl+d", "pgdown":
+		m.moveHistoryCursor(half)
+	case "home", "g":
+		m.setHistoryCursor(0)
+	case "G", "end":
+		m.setHistoryCursor(len(m.History.Lines) - 1)
+	case "enter":
+		if event := m.History.Event(m.HistoryCursor); event != nil {
+			return m, m.JumpToPackage(event.Package)
+		}
+	}
+
+	return m, nil
+}
@@ -0,0 +1,171 @@
+package app
+
+import (
+	"fmt"
+	"os"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/sjsanc/pacviz/v3/internal/domain"
+)
+
+type manifestLoadedMsg struct {
+	path     string
+	manifest domain.Manifest
+	err      error
+}
+
+// readManifest parses a manifest file written by pacviz export.
+func readManifest(path string) (domain.Manifest, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return domain.Manifest{}, fmt.Errorf("failed to open manifest: %w", err)
+	}
+	defer f.Close()
+
+	return domain.ParseManifest(f, domain.ManifestFormatFor(path))
+}
+
+// OpenManifest reads a manifest and shows how the installed packages differ
+// from it.
+func (m *Model) OpenManifest(path string) tea.Cmd {
+	return func() tea.Msg {
+		manifest, err := readManifest(path)
+		return manifestLoadedMsg{path: path, manifest: manifest, err: err}
+	}
+}
+
+func (m Model) handleManifestLoaded(msg manifestLoadedMsg) (tea.Model, tea.Cmd) {
+	if msg.err != nil {
+		m.RemoteError = fmt.Sprintf("Failed to import %s: %v", msg.path, msg.err)
+		return m, nil
+	}
+
+	m.CloseTree()
+	m.CloseFiles()
+	m.CloseCache()
+	m.CloseHistory()
+	m.CloseConfigs()
+	m.CloseDiskUsage()
+	m.ManifestPath = msg.path
+	m.Manifest = msg.manifest
+	m.ShowManifest = true
+	m.ShowDetailPanel = false
+	m.ManifestOffset = 0
+	m.diffManifest()
+	m.setManifestCursor(1) // the first entry, below its section header
+	return m, nil
+}
+
+// diffManifest compares the open manifest with the installed packages again,
+// after a transaction or reload changed them.
+func (m *Model) diffManifest() {
+	m.ManifestDiff = domain.DiffManifest(m.Manifest, m.installedPackages())
+	m.ManifestLines = m.ManifestDiff.Lines()
+	m.setManifestCursor(m.ManifestCursor)
+}
+
+func (m *Model) CloseManifest() {
+	m.ShowManifest = false
+	m.ManifestLines = nil
+}
+
+func (m *Model) moveManifestCursor(delta int) {
+	m.setManifestCursor(m.ManifestCursor + delta)
+}
+
+func (m *Model) setManifestCursor(index int) {
+	index = min(index, len(m.ManifestLines)-1)
+	m.ManifestCursor = max(index, 0)
+
+	bodyRows := m.listPaneHeight() - 1
+	if m.ManifestCursor < m.ManifestOffset {
+		m.ManifestOffset = m.ManifestCursor
+	}
+	if m.ManifestCursor >= m.ManifestOffset+bodyRows {
+		m.ManifestOffset = m.ManifestCursor - bodyRows + 1
+	}
+}
+
+// manifestAction starts the transaction that resolves the given entries of
+// a section: installing missing packages, removing extra ones or marking
+// dependencies as explicit. Version differences only jump to the package.
+func (m *Model) manifestAction(section domain.ManifestSection, indexes []int) tea.Cmd {
+	if len(indexes) == 0 {
+		return nil
+	}
+	if m.transactionRunning() {
+		m.RemoteError = "A transaction is already running"
+		return nil
+	}
+
+	diff := m.ManifestDiff
+	switch section {
+	case domain.SectionMissing:
+		pkgs := make([]*domain.Package, len(indexes))
+		for i, idx := range indexes {
+			missing := diff.Missing[idx]
+			pkgs[i] = &domain.Package{Name: missing.Name, IsAUR: missing.Foreign}
+		}
+		m.InitiateInstall(pkgs)
+	case domain.SectionExtra:
+		pkgs := make([]*domain.Package, len(indexes))
+		for i, idx := range indexes {
+			pkgs[i] = diff.Extra[idx]
+		}
+		m.InitiateRemoval(pkgs, domain.RemoveOptions{})
+	case domain.SectionReason:
+		pkgs := make([]*domain.Package, len(indexes))
+		for i, idx := range indexes {
+			pkgs[i] = diff.Reason[idx]
+		}
+		m.InitiateReasonChange(pkgs, domain.ReasonExplicit)
+	case domain.SectionVersions:
+		return m.JumpToPackage(diff.Versions[indexes[0]].Package.Name)
+	}
+	return nil
+}
+
+func (m Model) handleManifestInput(key string) (tea.Model, tea.Cmd) {
+	half := max((m.listPaneHeight()-1)/2, 1)
+	m.RemoteError = ""
+
+	var line *domain.ManifestLine
+	if m.ManifestCursor < len(m.ManifestLines) {
+		line = &m.ManifestLines[m.ManifestCursor]
+	}
+
+	switch key {
+	case "ctrl+c", "q":
+		return m, tea.Quit
+	case "esc":
+		m.CloseManifest()
+	case ":":
+		m.EnterCommandMode()
+	case "up", "k":
+		m.moveManifestCursor(-1)
+	case "down", "j":
+		m.moveManifestCursor(1)
+	case "ctrl+u", "pgup":
+		m.moveManifestCursor(-half)
+	case "ctrl+d", "pgdown":
+		m.moveManifestCursor(half)
+	case "home", "g":
+		m.setManifestCursor(0)
+	case "G", "end":
+		m.setManifestCursor(len(m.ManifestLines) - 1)
+	case "enter":
+		if line != nil && line.Index >= 0 {
+			return m, m.manifestAction(line.Section, []int{line.Index})
+		}
+	case "a":
+		if line != nil && line.Section != domain.SectionVersions {
+			indexes := make([]int, m.ManifestDiff.Len(line.Section))
+			for i := range indexes {
+				indexes[i] = i
+			}
+			return m, m.manifestAction(line.Section, indexes)
+		}
+	}
+
+	return m, nil
+}
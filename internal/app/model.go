@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"strings"
 	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
@@ -43,32 +44,133 @@ type Model struct {
 	Error    string
 	Ready    bool
 
-	Mode   InputMode
-	Buffer string
+	Mode        InputMode
+	Buffer      string
+	FilterError string // why the filter being typed does not parse
 
 	Presets       []domain.Preset
 	CurrentPreset int
 
 	ShowDetailPanel bool
+	WhyPackage      *domain.Package // the package WhyLines explains
+	WhyLines        []string        // the detail panel's why summary
 
 	ViewMode      ViewMode
 	RemoteQuery   string
+	RemoteFiles   bool // remote results come from a file search
 	RemoteLoading bool
 	RemoteError   string
 	LocalRows     []*domain.Row
 	SpinnerFrame  int
 
-	PendingInstall bool
-	Installing     bool
-	InstallingPkg  string
-	InstallError   string
-	InstallOutput  string
+	PendingInstall  bool
+	Installing      bool
+	InstallingPkgs  []string
+	InstallViaAUR   bool
+	InstallArchives []string // package files to install with pacman -U instead of names
+	InstallError    string
 
 	PendingRemoval bool
-	RemovingPkg    string
+	RemovingPkgs   []string
+	RemovalPlan    *domain.RemovalPlan
 	Removing       bool
 	RemoveError    string
-	RemoveOutput   string
+
+	PendingUpgrade bool
+	Upgrading      bool
+	UpgradeAUR     bool
+	Upgrades       []domain.Upgrade
+	UpgradeError   string
+
+	Syncing   bool
+	SyncError string
+	Synced    bool // the sync databases were refreshed with :sync
+
+	PendingClean bool
+	CleanPlan    *domain.CacheCleanup
+	Cleaning     bool
+	CleanError   string
+
+	PendingReason bool
+	SettingReason bool
+	ReasonPkgs    []string
+	NewReason     domain.InstallReason
+
+	// Dependency tree of a single package
+	ShowTree   bool
+	Tree       *domain.DependencyTree
+	TreeCursor int
+	TreeOffset int
+
+	// File list of a single package
+	ShowFiles      bool
+	FileList       *domain.FileTree
+	FilesCursor    int
+	FilesOffset    int
+	FilesFiltering bool
+	Clipboard      string // OSC 52 sequence written with the next frames
+
+	// Package cache browser
+	ShowCache      bool
+	Cache          *domain.CacheList
+	CacheCursor    int
+	CacheOffset    int
+	CacheFiltering bool
+
+	// Recent changes per package from pacman.log, and the history browser
+	HistorySummaries map[string][]string
+	ShowHistory      bool
+	History          *domain.HistoryList
+	HistoryCursor    int
+	HistoryOffset    int
+	HistoryFiltering bool
+
+	// Disk usage breakdown with each explicit package's exclusive footprint
+	ShowDiskUsage bool
+	DiskUsage     *domain.DiskUsage
+	UsageSection  domain.UsageSection
+	UsageCursor   int
+	UsageOffset   int
+
+	// Package manifest imported with :import, and its differences from the
+	// installed packages
+	ImportPath     string // manifest to open once the packages have loaded
+	ShowManifest   bool
+	ManifestPath   string
+	Manifest       domain.Manifest
+	ManifestDiff   domain.ManifestDiff
+	ManifestLines  []domain.ManifestLine
+	ManifestCursor int
+	ManifestOffset int
+
+	// .pacnew/.pacsave files to merge, and the one being reviewed or resolved
+	ShowConfigs     bool
+	ConfigFiles     []domain.ConfigFile
+	ConfigCursor    int
+	ConfigOffset    int
+	ConfigDiff      *domain.ConfigFile // file whose diff the output pane shows
+	PendingConfig   bool
+	ConfigTarget    *domain.ConfigFile
+	ConfigReplace   bool // replace the live file rather than delete ConfigTarget.New
+	ResolvingConfig bool
+	ConfigError     string
+
+	// Background file verification (:verify)
+	Verifying       bool
+	VerifyCancelled bool
+	VerifyDone      int // packages checked so far
+	VerifyTotal     int
+	VerifyFiles     int
+	VerifyIssues    int
+	VerifyBroken    int // packages with at least one problem
+	verifyStream    *verifyStream
+
+	// Output pane for streamed transaction output
+	ShowOutput   bool
+	OutputTitle  string
+	OutputLines  []string
+	OutputOffset int
+	OutputFollow bool
 
 	PasswordBuffer string
 	NeedsPassword  bool
@@ -96,15 +198,13 @@ type remoteSearchResultMsg struct {
 type spinnerTickMsg struct{}
 
 type installCompleteMsg struct {
-	pkgName string
-	output  string
-	err     error
+	pkgNames []string
+	err      error
 }
 
 type removeCompleteMsg struct {
-	pkgName string
-	output  string
-	err     error
+	pkgNames []string
+	err      error
 }
 
 type repositoryRefreshedMsg struct {
@@ -139,7 +239,7 @@ func executeCommandMsg(commandStr string) tea.Cmd {
 
 // NewModel creates a new application model.
 func NewModel(cfg *config.Config) *Model {
-	repo, err := repository.NewAlpmRepository()
+	repo, err := OpenRepository(cfg.Pacman)
 	if err != nil {
 		log.Printf("Failed to initialize repository: %v", err)
 		return &Model{
@@ -151,6 +251,11 @@ func NewModel(cfg *config.Config) *Model {
 		}
 	}
 
+	return NewModelWithRepository(cfg, repo)
+}
+
+// NewModelWithRepository creates a model backed by the given repository.
+func NewModelWithRepository(cfg *config.Config, repo repository.Repository) *Model {
 	m := &Model{
 		Viewport:      viewport.New(),
 		Repo:          repo,
@@ -170,6 +275,24 @@ func NewModel(cfg *config.Config) *Model {
 	return m
 }
 
+// OpenRepository opens the fixture repository in demo mode, or the system
+// ALPM databases otherwise.
+func OpenRepository(cfg config.PacmanConfig) (repository.Repository, error) {
+	if cfg.Fixture != "" {
+		return repository.NewFixtureRepository(cfg.Fixture)
+	}
+
+	return repository.NewAlpmRepository(repository.Options{
+		ConfigPath: cfg.ConfigPath,
+		RootDir:    cfg.RootDir,
+		DBPath:     cfg.DBPath,
+		Mirror:     cfg.Mirror,
+		SyncDBPath: cfg.SyncDBPath,
+		CacheDir:   cfg.CacheDir,
+		LogFile:    cfg.LogFile,
+	})
+}
+
 func (m Model) Init() tea.Cmd {
 	return m.loadPackages
 }
@@ -282,6 +405,18 @@ func (m *Model) applyCurrentPreset() tea.Cmd {
 	preset := m.Presets[m.CurrentPreset]
 	m.Viewport.ApplyPresetFilter(preset.Filter)
 
+	for _, col := range m.Viewport.Columns {
+		if col.Type == column.ColUnneeded {
+			col.Visible = preset.Type == domain.PresetUnneeded
+		}
+		if col.Type == column.ColUnsatisfied {
+			col.Visible = preset.Type == domain.PresetBroken
+		}
+		if col.Type == column.ColPendingConfigs {
+			col.Visible = preset.Type == domain.PresetPacnew
+		}
+	}
+
 	// If switching to AUR preset, do a lazy Info() lookup
 	if preset.Type == domain.PresetAUR && m.AUREnabled && m.AURClient != nil {
 		return m.doAURInfoLookup()
@@ -291,9 +426,20 @@ func (m *Model) applyCurrentPreset() tea.Cmd {
 }
 
 func (m *Model) EnterRemoteMode(query string) tea.Cmd {
+	return m.enterRemoteMode(query, false)
+}
+
+// EnterFileSearchMode lists the sync packages containing a file matching
+// query, like pacman -F, as remote results that can be installed.
+func (m *Model) EnterFileSearchMode(query string) tea.Cmd {
+	return m.enterRemoteMode(query, true)
+}
+
+func (m *Model) enterRemoteMode(query string, files bool) tea.Cmd {
 	m.LocalRows = m.Viewport.AllRows
 	m.ViewMode = ViewRemote
 	m.RemoteQuery = query
+	m.RemoteFiles = files
 	m.RemoteLoading = true
 	m.RemoteError = ""
 	m.SpinnerFrame = 0
@@ -310,6 +456,9 @@ func (m *Model) EnterRemoteMode(query string) tea.Cmd {
 		if col.Type == column.ColInstalled {
 			col.Visible = true
 		}
+		if col.Type == column.ColMatchedFiles {
+			col.Visible = files
+		}
 	}
 
 	cmds := []tea.Cmd{
@@ -317,7 +466,7 @@ func (m *Model) EnterRemoteMode(query string) tea.Cmd {
 		tickSpinner(),
 	}
 
-	if m.AUREnabled {
+	if m.searchesAUR() {
 		cmds = append(cmds, m.doAURSearch(query))
 	}
 
@@ -327,6 +476,7 @@ func (m *Model) EnterRemoteMode(query string) tea.Cmd {
 func (m *Model) ExitRemoteMode() {
 	m.ViewMode = ViewLocal
 	m.RemoteQuery = ""
+	m.RemoteFiles = false
 	m.RemoteLoading = false
 	m.RemoteError = ""
 
@@ -337,6 +487,9 @@ func (m *Model) ExitRemoteMode() {
 		if col.Type == column.ColInstalled {
 			col.Visible = false
 		}
+		if col.Type == column.ColMatchedFiles {
+			col.Visible = false
+		}
 	}
 
 	if m.LocalRows != nil {
@@ -352,9 +505,20 @@ func (m *Model) ExitRemoteMode() {
 	_ = m.applyCurrentPreset()
 }
 
+// searchesAUR reports whether remote searches also query the AUR. File
+// searches only cover the sync databases.
+func (m Model) searchesAUR() bool {
+	return m.AUREnabled && !m.RemoteFiles
+}
+
 func (m Model) doRemoteSearch(query string) tea.Cmd {
+	search := m.Repo.Search
+	if m.RemoteFiles {
+		search = m.Repo.SearchFiles
+	}
+
 	return func() tea.Msg {
-		packages, err := m.Repo.Search(query)
+		packages, err := search(query)
 		return remoteSearchResultMsg{
 			packages: packages,
 			query:    query,
@@ -374,8 +538,8 @@ func (m Model) doAURSearch(query string) tea.Cmd {
 	}
 }
 
-// mergeSearchResults merges sync and AUR results. Sync wins on name collision.
-func mergeSearchResults(syncPkgs, aurPkgs []*domain.Package) []*domain.Package {
+// MergeSearchResults merges sync and AUR results. Sync wins on name collision.
+func MergeSearchResults(syncPkgs, aurPkgs []*domain.Package) []*domain.Package {
 	seen := make(map[string]bool, len(syncPkgs))
 	for _, pkg := range syncPkgs {
 		seen[pkg.Name] = true
@@ -421,96 +585,98 @@ func (m Model) GetSpinner() string {
 	return spinnerFrames[m.SpinnerFrame%len(spinnerFrames)]
 }
 
-func (m *Model) InitiateInstall(pkgName string) {
+// targetPackages returns the marked packages, or the package under the
+// cursor when nothing is marked.
+func (m Model) targetPackages() []*domain.Package {
+	if marked := m.Viewport.MarkedPackages(); len(marked) > 0 {
+		return marked
+	}
+	if pkg := m.Viewport.GetSelectedPackage(); pkg != nil {
+		return []*domain.Package{pkg}
+	}
+	return nil
+}
+
+// describeTargets names up to three packages, or counts them.
+func describeTargets(pkgNames []string) string {
+	if len(pkgNames) <= 3 {
+		return strings.Join(pkgNames, ", ")
+	}
+	return fmt.Sprintf("%d packages", len(pkgNames))
+}
+
+func packageNames(pkgs []*domain.Package) []string {
+	names := make([]string, len(pkgs))
+	for i, pkg := range pkgs {
+		names[i] = pkg.Name
+	}
+	return names
+}
+
+// InitiateInstall asks for confirmation to install pkgs. If any of them is
+// from the AUR, the whole batch goes through the AUR helper.
+func (m *Model) InitiateInstall(pkgs []*domain.Package) {
 	m.PendingInstall = true
-	m.InstallingPkg = pkgName
+	m.InstallingPkgs = packageNames(pkgs)
+	m.InstallArchives = nil
+	m.InstallViaAUR = false
+	for _, pkg := range pkgs {
+		if pkg.IsAUR {
+			m.InstallViaAUR = true
+		}
+	}
 	m.InstallError = ""
 }
 
 func (m *Model) CancelInstall() {
 	m.PendingInstall = false
-	m.InstallingPkg = ""
+	m.InstallingPkgs = nil
+	m.InstallArchives = nil
+	m.InstallViaAUR = false
 }
 
-func (m *Model) InstallPackage(pkgName string, password string) tea.Cmd {
+func (m *Model) InstallPackages(pkgNames []string, password string) tea.Cmd {
 	m.Installing = true
 	m.PendingInstall = false
-	m.InstallingPkg = pkgName
+	m.InstallingPkgs = pkgNames
 	m.InstallError = ""
+	m.StartOutput("Installing " + describeTargets(pkgNames))
 
 	return tea.Batch(
-		m.doInstall(pkgName, password),
+		m.doInstall(pkgNames, password),
 		tickSpinner(),
 	)
 }
 
-func (m Model) doInstall(pkgName string, password string) tea.Cmd {
-	return func() tea.Msg {
-		output, err := m.Repo.Install([]string{pkgName}, password)
+func (m Model) doInstall(pkgNames []string, password string) tea.Cmd {
+	return startTransaction(func(out chan<- string) tea.Msg {
+		var err error
+		if len(m.InstallArchives) > 0 {
+			err = m.Repo.InstallFiles(m.InstallArchives, password, out)
+		} else {
+			err = m.Repo.Install(pkgNames, password, out)
+		}
 		return installCompleteMsg{
-			pkgName: pkgName,
-			output:  output,
-			err:     err,
+			pkgNames: pkgNames,
+			err:      err,
 		}
-	}
-}
-
-func (m *Model) InitiateRemoval(pkgName string) {
-	m.PendingRemoval = true
-	m.RemovingPkg = pkgName
-	m.RemoveError = ""
-}
-
-func (m Model) isSelectedPackageAUR() bool {
-	if m.Viewport.SelectedRow < 0 || m.Viewport.SelectedRow >= len(m.Viewport.VisibleRows) {
-		return false
-	}
-	row := m.Viewport.VisibleRows[m.Viewport.SelectedRow]
-	return row.Package != nil && row.Package.IsAUR
+	})
 }
 
 func IsRunningAsRoot() bool {
 	return os.Geteuid() == 0
 }
 
-func (m *Model) CancelRemoval() {
-	m.PendingRemoval = false
-	m.RemovingPkg = ""
-}
-
-func (m *Model) RemovePackage(pkgName string, password string) tea.Cmd {
-	m.Removing = true
-	m.PendingRemoval = false
-	m.RemovingPkg = pkgName
-	m.RemoveError = ""
-
-	return tea.Batch(
-		m.doRemove(pkgName, password),
-		tickSpinner(),
-	)
-}
-
 // doAURInstall suspends the TUI and runs the AUR helper interactively.
-func (m Model) doAURInstall(pkgName string) tea.Cmd {
+func (m Model) doAURInstall(pkgNames []string) tea.Cmd {
 	if m.AURHelper == nil {
 		return func() tea.Msg {
 			return aurInstallCompleteMsg{err: fmt.Errorf("no AUR helper found (install yay, paru, pikaur, or trizen)")}
 		}
 	}
 
-	cmd := aur.InstallCmd(m.AURHelper, []string{pkgName})
+	cmd := aur.InstallCmd(m.AURHelper, pkgNames)
 	return tea.ExecProcess(cmd, func(err error) tea.Msg {
 		return aurInstallCompleteMsg{err: err}
 	})
 }
-
-func (m Model) doRemove(pkgName string, password string) tea.Cmd {
-	return func() tea.Msg {
-		output, err := m.Repo.Remove([]string{pkgName}, false, password)
-		return removeCompleteMsg{
-			pkgName: pkgName,
-			output:  output,
-			err:     err,
-		}
-	}
-}
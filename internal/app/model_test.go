@@ -0,0 +1,939 @@
+package app
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/sjsanc/pacviz/v3/internal/aur"
+	"github.com/sjsanc/pacviz/v3/internal/command"
+	"github.com/sjsanc/pacviz/v3/internal/config"
+	"github.com/sjsanc/pacviz/v3/internal/domain"
+	"github.com/sjsanc/pacviz/v3/internal/repository"
+	"github.com/sjsanc/pacviz/v3/internal/ui/column"
+)
+
+// newTestModel returns a loaded model backed by the JSON fixture.
+func newTestModel(t *testing.T) Model {
+	t.Helper()
+
+	repo, err := repository.NewFixtureRepository("testdata/fixture.json")
+	if err != nil {
+		t.Fatalf("failed to load fixture: %v", err)
+	}
+
+	cfg := config.DefaultConfig()
+	cfg.AUR.Disabled = true
+
+	m := *NewModelWithRepository(cfg, repo)
+	updated, _ := m.Update(tea.WindowSizeMsg{Width: 120, Height: 30})
+	updated, _ = updated.Update(m.loadPackages())
+	return updated.(Model)
+}
+
+func TestModel_LoadsFixture(t *testing.T) {
+	m := newTestModel(t)
+
+	if !m.Ready {
+		t.Fatal("expected model to be ready after loading packages")
+	}
+	if len(m.Viewport.AllRows) != 6 {
+		t.Errorf("expected 6 rows, got %d", len(m.Viewport.AllRows))
+	}
+
+	// Explicit preset is the default
+	for _, row := range m.Viewport.VisibleRows {
+		if row.Package.InstallReason != domain.ReasonExplicit {
+			t.Errorf("%s is not explicit", row.Package.Name)
+		}
+	}
+}
+
+func TestModel_SetPreset(t *testing.T) {
+	m := newTestModel(t)
+
+	if ok, _ := m.SetPreset(string(domain.PresetOrphans)); !ok {
+		t.Fatal("SetPreset(orphans) failed")
+	}
+	if len(m.Viewport.VisibleRows) != 1 || m.Viewport.VisibleRows[0].Package.Name != "libfoo" {
+		t.Errorf("expected only libfoo in orphans preset, got %d rows", len(m.Viewport.VisibleRows))
+	}
+
+	if ok, _ := m.SetPreset(string(domain.PresetUpdatable)); !ok {
+		t.Fatal("SetPreset(updatable) failed")
+	}
+	if len(m.Viewport.VisibleRows) != 1 || m.Viewport.VisibleRows[0].Package.Name != "glibc" {
+		t.Errorf("expected only glibc in updatable preset, got %d rows", len(m.Viewport.VisibleRows))
+	}
+
+	if ok, _ := m.SetPreset("broken"); !ok {
+		t.Fatal("SetPreset(broken) failed")
+	}
+	if len(m.Viewport.VisibleRows) != 1 || m.Viewport.VisibleRows[0].Package.Name != "yay-bin" {
+		t.Errorf("expected only yay-bin in broken preset, got %d rows", len(m.Viewport.VisibleRows))
+	}
+}
+
+// runTransaction feeds the messages of a transaction command back into the
+// model until the completion message has been handled.
+func runTransaction(t *testing.T, m Model, cmd tea.Cmd) Model {
+	t.Helper()
+
+	for cmd != nil {
+		msg := cmd()
+		updated, next := m.Update(msg)
+		m = updated.(Model)
+		if _, ok := msg.(transactionOutputMsg); !ok {
+			break
+		}
+		cmd = next
+	}
+	return m
+}
+
+func TestModel_RemoveStreamsOutput(t *testing.T) {
+	m := newTestModel(t)
+	m.SetPreset(string(domain.PresetOrphans))
+
+	m.Removing = true
+	m.StartOutput("Removing libfoo")
+	m = runTransaction(t, m, m.doRemove([]string{"libfoo"}, domain.RemoveOptions{}, ""))
+
+	if m.Removing {
+		t.Error("expected removal to be complete")
+	}
+	if m.RemoveError != "" {
+		t.Fatalf("unexpected remove error: %s", m.RemoveError)
+	}
+	if !m.ShowOutput || len(m.OutputLines) != 1 || m.OutputLines[0] != "removing libfoo (1.0-1)..." {
+		t.Errorf("OutputLines = %q", m.OutputLines)
+	}
+
+	updated, _ := m.Update(m.loadPackages())
+	m = updated.(Model)
+	if len(m.Viewport.VisibleRows) != 0 {
+		t.Errorf("expected no orphans after removal, got %d", len(m.Viewport.VisibleRows))
+	}
+
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = updated.(Model)
+	if m.ShowOutput || m.OutputLines != nil {
+		t.Error("expected Enter to dismiss the output pane")
+	}
+}
+
+func TestModel_BatchRemove(t *testing.T) {
+	m := newTestModel(t)
+	m.SetPreset(string(domain.PresetAll))
+
+	for i, row := range m.Viewport.VisibleRows {
+		if row.Package.Name == "libfoo" || row.Package.Name == "yay-bin" {
+			m.Viewport.SelectRow(i)
+			m.Viewport.ToggleMark()
+		}
+	}
+	if m.Viewport.MarkedCount() != 2 {
+		t.Fatalf("expected 2 marked rows, got %d", m.Viewport.MarkedCount())
+	}
+
+	updated, _ := m.Update(commandResultMsg{Result: command.Execute("remove")})
+	m = updated.(Model)
+	if !m.PendingRemoval || len(m.RemovingPkgs) != 2 {
+		t.Fatalf("expected removal of both marked packages to be pending, got %q", m.RemovingPkgs)
+	}
+
+	m.Removing = true
+	m.PendingRemoval = false
+	m.StartOutput("Removing " + describeTargets(m.RemovingPkgs))
+	m = runTransaction(t, m, m.doRemove(m.RemovingPkgs, domain.RemoveOptions{}, ""))
+
+	if m.RemoveError != "" {
+		t.Fatalf("unexpected remove error: %s", m.RemoveError)
+	}
+	if len(m.OutputLines) != 2 {
+		t.Errorf("expected one output pane for both packages, got %q", m.OutputLines)
+	}
+	if m.OutputTitle != "Removed libfoo, yay-bin" {
+		t.Errorf("OutputTitle = %q", m.OutputTitle)
+	}
+}
+
+func TestModel_RemovalPreview(t *testing.T) {
+	m := newTestModel(t)
+	m.SetPreset(string(domain.PresetAll))
+
+	for i, row := range m.Viewport.VisibleRows {
+		if row.Package.Name == "readline" {
+			m.Viewport.SelectRow(i)
+		}
+	}
+
+	updated, _ := m.Update(commandResultMsg{Result: command.Execute("remove")})
+	m = updated.(Model)
+	plan := m.RemovalPlan
+	if !m.PendingRemoval || plan == nil {
+		t.Fatal("expected a pending removal with a plan")
+	}
+	if len(plan.Broken) != 1 || plan.Broken[0].RequiredBy[0] != "bash" {
+		t.Errorf("expected removing readline to break bash, got %+v", plan.Broken)
+	}
+	if !m.ShowOutput || len(m.OutputLines) == 0 {
+		t.Error("expected the plan to be previewed in the output pane")
+	}
+
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	m = updated.(Model)
+	if m.PendingRemoval || m.ShowOutput {
+		t.Error("expected Esc to cancel the removal and close the preview")
+	}
+
+	updated, _ = m.Update(commandResultMsg{Result: command.Execute("remove -c")})
+	m = updated.(Model)
+	if len(m.RemovalPlan.Broken) != 0 || len(m.RemovalPlan.Removed) != 3 {
+		t.Errorf("expected cascade to take bash and base along, got %d packages", len(m.RemovalPlan.Removed))
+	}
+}
+
+func TestModel_SetInstallReason(t *testing.T) {
+	m := newTestModel(t)
+	m.SetPreset(string(domain.PresetOrphans))
+
+	m.SettingReason = true
+	m = runTransaction(t, m, m.doSetInstallReason([]string{"libfoo"}, domain.ReasonExplicit, ""))
+
+	if m.SettingReason || m.ShowOutput {
+		t.Fatalf("expected reason change to finish quietly, output %q", m.OutputLines)
+	}
+	if m.RemoteError != "Marked libfoo as explicit" {
+		t.Errorf("RemoteError = %q", m.RemoteError)
+	}
+
+	updated, _ := m.Update(m.loadPackages())
+	m = updated.(Model)
+	if len(m.Viewport.VisibleRows) != 0 {
+		t.Errorf("expected libfoo to no longer be an orphan, got %d orphans", len(m.Viewport.VisibleRows))
+	}
+}
+
+func TestModel_ReasonChangePreview(t *testing.T) {
+	m := newTestModel(t)
+	m.SetPreset(string(domain.PresetAll))
+	m.Viewport.SelectPackage("base")
+
+	updated, _ := m.Update(commandResultMsg{Result: command.Execute("asdeps")})
+	m = updated.(Model)
+	if !m.PendingReason || m.SettingReason || !reflect.DeepEqual(m.OutputLines, []string{"base: explicit → dependency"}) {
+		t.Fatalf("expected a preview before marking, got pending %v, output %q", m.PendingReason, m.OutputLines)
+	}
+
+	updated, _ = m.handleNormalModeInput("esc")
+	m = updated.(Model)
+	if m.PendingReason || m.ShowOutput || m.ReasonPkgs != nil {
+		t.Errorf("expected Esc to cancel the reason change")
+	}
+	if pkg := m.Viewport.GetSelectedPackage(); pkg.InstallReason != domain.ReasonExplicit {
+		t.Errorf("expected base to stay explicit, got %v", pkg.InstallReason)
+	}
+}
+
+func TestModel_AutoRemove(t *testing.T) {
+	m := newTestModel(t)
+
+	if ok, _ := m.SetPreset(string(domain.PresetUnneeded)); !ok {
+		t.Fatal("SetPreset(unneeded) failed")
+	}
+	if len(m.Viewport.VisibleRows) != 1 || m.Viewport.VisibleRows[0].Package.Name != "libfoo" {
+		t.Errorf("expected only libfoo to be unneeded, got %d rows", len(m.Viewport.VisibleRows))
+	}
+
+	updated, _ := m.Update(commandResultMsg{Result: command.Execute("autoremove")})
+	m = updated.(Model)
+	if !m.PendingRemoval || len(m.RemovingPkgs) != 1 || m.RemovingPkgs[0] != "libfoo" {
+		t.Errorf("expected autoremove to propose libfoo, got %q", m.RemovingPkgs)
+	}
+}
+
+func TestModel_DependencyTree(t *testing.T) {
+	m := newTestModel(t)
+	m.Viewport.SelectPackage("base")
+
+	press := func(key string) {
+		t.Helper()
+		updated, _ := m.handleNormalModeInput(key)
+		m = updated.(Model)
+	}
+
+	press("t")
+	if !m.ShowTree || m.Tree.Root != "base" || len(m.Tree.Nodes) != 3 {
+		t.Fatalf("expected the depends tree of base with two children, got %+v", m.Tree)
+	}
+
+	press("E")
+	var names []string
+	for _, node := range m.Tree.Nodes {
+		names = append(names, node.Name)
+	}
+	if want := []string{"base", "bash", "glibc", "readline", "glibc", "glibc"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("expanded tree = %q, want %q", names, want)
+	}
+
+	press("j")
+	press("j")
+	press("j")
+	press("enter")
+	if m.ShowTree {
+		t.Error("enter should close the tree")
+	}
+	if pkg := m.Viewport.GetSelectedPackage(); pkg == nil || pkg.Name != "readline" {
+		t.Errorf("expected readline to be selected after the jump, got %v", pkg)
+	}
+}
+
+func TestModel_Why(t *testing.T) {
+	m := newTestModel(t)
+
+	if got := m.whySummary(&domain.Package{Name: "readline", Installed: true, InstallReason: domain.ReasonDependency}); !reflect.DeepEqual(got, []string{"base → bash → readline"}) {
+		t.Errorf("whySummary(readline) = %q", got)
+	}
+
+	m.ShowDetailPanel = true
+	m.SetPreset(string(domain.PresetDependency))
+	for i, row := range m.Viewport.VisibleRows {
+		if row.Package.Name == "readline" {
+			m.Viewport.SelectedRow = i
+		}
+	}
+	updated, _ := m.Update(spinnerTickMsg{})
+	m = updated.(Model)
+	if m.WhyPackage == nil || m.WhyPackage.Name != "readline" || !reflect.DeepEqual(m.WhyLines, []string{"base → bash → readline"}) {
+		t.Errorf("expected the why summary to follow the selection, got %q", m.WhyLines)
+	}
+
+	updated, _ = m.Update(commandResultMsg{Result: command.Execute("why glibc")})
+	m = updated.(Model)
+	if !m.ShowOutput || m.OutputTitle != "Why is glibc installed?" {
+		t.Fatalf("expected :why to open the output pane, got title %q", m.OutputTitle)
+	}
+	if !reflect.DeepEqual(m.OutputLines, []string{"base → glibc"}) {
+		t.Errorf(":why glibc = %q, want [base → glibc]", m.OutputLines)
+	}
+
+	updated, _ = m.Update(commandResultMsg{Result: command.Execute("why nonexistent")})
+	m = updated.(Model)
+	if m.RemoteError != "nonexistent is not installed" {
+		t.Errorf("unexpected error for unknown package: %q", m.RemoteError)
+	}
+}
+
+func TestModel_Files(t *testing.T) {
+	m := newTestModel(t)
+	m.Viewport.SelectPackage("base")
+
+	updated, cmd := m.handleNormalModeInput("f")
+	m = updated.(Model)
+	updated, _ = m.Update(cmd())
+	m = updated.(Model)
+	if !m.ShowFiles || m.FileList.Package != "base" || len(m.FileList.Nodes) != 0 {
+		t.Fatalf("expected an empty file list for base, got %+v", m.FileList)
+	}
+
+	if ok, _ := m.SetPreset(string(domain.PresetAll)); !ok {
+		t.Fatal("SetPreset(all) failed")
+	}
+	m.CloseFiles()
+	m.Viewport.SelectPackage("bash")
+	updated, _ = m.Update(m.OpenFiles(m.Viewport.GetSelectedPackage())())
+	m = updated.(Model)
+	if m.FileList.FileCount() != 4 {
+		t.Errorf("expected 4 files for bash, got %d", m.FileList.FileCount())
+	}
+
+	for _, key := range []string{"/", "b", "a", "s", "h", "enter"} {
+		updated, _ = m.handleNormalModeInput(key)
+		m = updated.(Model)
+	}
+	if m.FilesFiltering || m.FileList.Filter != "bash" {
+		t.Errorf("expected the filter to be applied, got %q (filtering %v)", m.FileList.Filter, m.FilesFiltering)
+	}
+
+	m.setFilesCursor(len(m.FileList.Nodes) - 1)
+	if file := m.selectedFile(); file == nil || file.Path != "/usr/share/doc/bash/CHANGES" {
+		t.Errorf("unexpected selected file %+v", file)
+	}
+	m.CopySelectedPath()
+	if m.RemoteError != "Copied /usr/share/doc/bash/CHANGES" {
+		t.Errorf("expected the path to be copied, status %q", m.RemoteError)
+	}
+	if want := "\x1b]52;c;L3Vzci9zaGFyZS9kb2MvYmFzaC9DSEFOR0VT\a"; !strings.HasPrefix(m.View(), want) {
+		t.Errorf("expected the frame to start with the clipboard sequence %q", want)
+	}
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyDown})
+	if strings.Contains(updated.View(), "\x1b]52;") {
+		t.Error("expected the clipboard sequence to be sent only until the next key")
+	}
+}
+
+func TestModel_Owns(t *testing.T) {
+	m := newTestModel(t)
+
+	updated, cmd := m.Update(commandResultMsg{Result: command.Execute("owns /usr/bin/sh")})
+	updated, _ = updated.Update(cmd())
+	m = updated.(Model)
+	if m.RemoteError != "/usr/bin/sh is owned by bash 5.2.026-2" {
+		t.Errorf("unexpected status %q", m.RemoteError)
+	}
+	if pkg := m.Viewport.GetSelectedPackage(); pkg == nil || pkg.Name != "bash" {
+		t.Errorf("expected bash to be selected, got %v", pkg)
+	}
+
+	m.Viewport.ToggleMark()
+	updated, _ = m.Update(m.FindOwners([]string{"/usr/bin/*", "/opt/none"})())
+	m = updated.(Model)
+	want := []string{
+		"/usr/bin/bash is owned by bash 5.2.026-2",
+		"/usr/bin/sh is owned by bash 5.2.026-2",
+		"No package owns /opt/none",
+	}
+	if !m.ShowOutput || !reflect.DeepEqual(m.OutputLines, want) {
+		t.Errorf("owns report = %q, want %q", m.OutputLines, want)
+	}
+	if marked := m.Viewport.MarkedPackages(); len(marked) != 1 || marked[0].Name != "bash" {
+		t.Errorf("owns should keep the user's marks, got %v", marked)
+	}
+}
+
+func TestModel_FileSearch(t *testing.T) {
+	m := newTestModel(t)
+
+	search := func(query string) Model {
+		m.EnterFileSearchMode(query)
+		updated, _ := m.Update(m.doRemoteSearch(query)())
+		return updated.(Model)
+	}
+
+	m = search("rg")
+	pkg := m.Viewport.GetSelectedPackage()
+	if pkg == nil || pkg.Name != "ripgrep" || pkg.Repository != "extra" || pkg.Installed {
+		t.Fatalf("expected an installable ripgrep, got %+v", pkg)
+	}
+	if !reflect.DeepEqual(pkg.MatchedFiles, []string{"/usr/bin/rg"}) {
+		t.Errorf("MatchedFiles = %q", pkg.MatchedFiles)
+	}
+
+	visible := func() bool {
+		for _, col := range m.Viewport.Columns {
+			if col.Type == column.ColMatchedFiles {
+				return col.Visible
+			}
+		}
+		return false
+	}
+	if !visible() {
+		t.Error("expected the Matched Files column in file search results")
+	}
+
+	m = search("/usr/lib/libfoo.so*")
+	if len(m.Viewport.VisibleRows) != 1 || len(m.Viewport.GetSelectedPackage().MatchedFiles) != 2 {
+		t.Errorf("unexpected glob results: %d rows", len(m.Viewport.VisibleRows))
+	}
+
+	m = search("nothing")
+	if m.RemoteError != "No package contains a file matching: nothing" {
+		t.Errorf("unexpected status %q", m.RemoteError)
+	}
+
+	m.ExitRemoteMode()
+	if m.RemoteFiles || visible() {
+		t.Error("expected leaving remote mode to end the file search")
+	}
+}
+
+func TestModel_CacheDowngrade(t *testing.T) {
+	m := newTestModel(t)
+
+	updated, cmd := m.Update(commandResultMsg{Result: command.Execute("cache bash")})
+	updated, _ = updated.Update(cmd())
+	m = updated.(Model)
+	if !m.ShowCache || len(m.Cache.Lines) != 3 {
+		t.Fatalf("expected the bash archives, got %+v", m.Cache)
+	}
+
+	m.InitiateDowngrade(m.selectedArchive())
+	if m.PendingInstall || m.RemoteError != "bash 5.2.026-2 is already installed" {
+		t.Fatalf("expected the installed archive to be refused, status %q", m.RemoteError)
+	}
+
+	m.moveCacheCursor(1)
+	m.InitiateDowngrade(m.selectedArchive())
+	if !m.PendingInstall || !reflect.DeepEqual(m.InstallingPkgs, []string{"bash 5.2.015-5"}) {
+		t.Fatalf("expected a pending downgrade, got %q", m.InstallingPkgs)
+	}
+
+	m.Installing = true
+	m = runTransaction(t, m, m.doInstall(m.InstallingPkgs, ""))
+	if m.InstallError != "" || m.InstallArchives != nil {
+		t.Fatalf("downgrade failed: %s", m.InstallError)
+	}
+
+	updated, _ = m.Update(m.loadPackages())
+	updated, _ = updated.Update(m.OpenCache("bash")())
+	m = updated.(Model)
+	if archive := m.selectedArchive(); archive == nil || !archive.Installed() || archive.Version != "5.2.015-5" {
+		t.Errorf("expected the cursor on the now installed archive, got %+v", archive)
+	}
+}
+
+func TestModel_CacheReloadsAfterRefresh(t *testing.T) {
+	m := newTestModel(t)
+	updated, cmd := m.Update(commandResultMsg{Result: command.Execute("cache bash")})
+	updated, _ = updated.Update(cmd())
+	m = updated.(Model)
+
+	updated, cmd = m.Update(installCompleteMsg{pkgNames: []string{"bash 5.2.015-5"}})
+	msg := cmd()
+	if _, ok := msg.(repositoryRefreshedMsg); !ok {
+		t.Fatalf("expected only the repository refresh to run, got %T", msg)
+	}
+
+	_, cmd = updated.Update(msg)
+	batch, ok := cmd().(tea.BatchMsg)
+	if !ok {
+		t.Fatalf("expected the reloads to be batched after the refresh")
+	}
+	var reloaded bool
+	for _, c := range batch {
+		if _, ok := c().(cacheLoadedMsg); ok {
+			reloaded = true
+		}
+	}
+	if !reloaded {
+		t.Error("expected the open cache browser to reload after the refresh")
+	}
+}
+
+func TestModel_CleanCache(t *testing.T) {
+	m := newTestModel(t)
+
+	updated, cmd := m.Update(commandResultMsg{Result: command.Execute("cleancache -k 1 -u")})
+	updated, _ = updated.Update(cmd())
+	m = updated.(Model)
+	if !m.PendingClean || len(m.CleanPlan.Remove) != 3 {
+		t.Fatalf("expected three archives to clean, got %+v", m.CleanPlan)
+	}
+	want := "  /var/cache/pacman/pkg/ripgrep-13.0.0-3-x86_64.pkg.tar.zst       1.4 MB  (not installed)"
+	if len(m.OutputLines) != 4 || m.OutputLines[3] != want {
+		t.Errorf("cleanup preview = %q", m.OutputLines)
+	}
+
+	m.PendingClean, m.Cleaning = false, true
+	m = runTransaction(t, m, m.doClean(m.CleanPlan, ""))
+	if m.Cleaning || m.CleanError != "" {
+		t.Fatalf("cleanup failed: %s", m.CleanError)
+	}
+
+	updated, _ = m.Update(m.PlanCleanCache(domain.CachePolicy{Keep: 1, Uninstalled: true})())
+	m = updated.(Model)
+	if m.PendingClean || m.RemoteError != "Nothing to clean (keep 1, uninstalled)" {
+		t.Errorf("expected nothing left to clean, status %q", m.RemoteError)
+	}
+}
+
+func TestModel_OutputScroll(t *testing.T) {
+	m := newTestModel(t)
+	m.StartOutput("test")
+	for i := 0; i < 100; i++ {
+		m.AppendOutput("line")
+	}
+
+	bottom := m.maxOutputOffset()
+	if m.OutputOffset != bottom {
+		t.Fatalf("expected pane to follow output, offset %d want %d", m.OutputOffset, bottom)
+	}
+
+	m.ScrollOutput(-10)
+	m.AppendOutput("line")
+	if m.OutputFollow || m.OutputOffset != bottom-10 {
+		t.Errorf("expected scrolled pane to stay put, offset %d", m.OutputOffset)
+	}
+
+	m.ScrollOutput(1000)
+	if !m.OutputFollow || m.OutputOffset != m.maxOutputOffset() {
+		t.Error("expected scrolling to the end to resume following")
+	}
+}
+
+func TestModel_Upgrade(t *testing.T) {
+	m := newTestModel(t)
+
+	updated, cmd := m.Update(commandResultMsg{Result: command.Execute("upgrade")})
+	m = updated.(Model)
+	updated, _ = m.Update(cmd())
+	m = updated.(Model)
+
+	if !m.PendingUpgrade || len(m.Upgrades) != 1 {
+		t.Fatalf("expected one pending upgrade, got %+v", m.Upgrades)
+	}
+	if u := m.Upgrades[0]; u.Name != "glibc" || u.OldVersion != "2.38-7" || u.NewVersion != "2.39-1" {
+		t.Errorf("unexpected upgrade %+v", u)
+	}
+	if !m.ShowOutput || len(m.OutputLines) != 1 {
+		t.Errorf("expected preview in output pane, got %q", m.OutputLines)
+	}
+
+	m.UpgradeSystem("")
+	m = runTransaction(t, m, m.doUpgrade(1, ""))
+	if m.Upgrading || m.UpgradeError != "" {
+		t.Fatalf("upgrade did not complete cleanly: %q", m.UpgradeError)
+	}
+	if len(m.OutputLines) != 1 || m.OutputLines[0] != "upgrading glibc (2.38-7 -> 2.39-1)..." {
+		t.Errorf("OutputLines = %q", m.OutputLines)
+	}
+
+	updated, _ = m.Update(m.loadPackages())
+	m = updated.(Model)
+	m.SetPreset(string(domain.PresetUpdatable))
+	if len(m.Viewport.VisibleRows) != 0 {
+		t.Errorf("expected no updatable packages after upgrade, got %d", len(m.Viewport.VisibleRows))
+	}
+
+	updated, cmd = m.Update(commandResultMsg{Result: command.Execute("upgrade")})
+	m = updated.(Model)
+	updated, _ = m.Update(cmd())
+	m = updated.(Model)
+	if m.PendingUpgrade || m.RemoteError != "System is up to date" {
+		t.Errorf("expected up-to-date message, got %q", m.RemoteError)
+	}
+}
+
+func TestModel_History(t *testing.T) {
+	m := newTestModel(t)
+	updated, _ := m.Update(m.loadHistory())
+	m = updated.(Model)
+
+	var bash *domain.Package
+	for _, pkg := range m.installedPackages() {
+		if pkg.Name == "bash" {
+			bash = pkg
+		}
+	}
+	summary := m.historySummary(bash)
+	if len(summary) != 2 || !strings.HasSuffix(summary[0], "upgraded 5.2.015-5 -> 5.2.026-2") || !strings.HasSuffix(summary[1], "installed 5.2.015-5") {
+		t.Errorf("historySummary(bash) = %q", summary)
+	}
+
+	updated, cmd := m.Update(commandResultMsg{Result: command.Execute("history bash")})
+	updated, _ = updated.Update(cmd())
+	m = updated.(Model)
+	if !m.ShowHistory || len(m.History.Lines) != 4 {
+		t.Fatalf("expected two bash transactions, got %+v", m.History)
+	}
+	if event := m.History.Event(m.HistoryCursor); event == nil || event.Action != domain.ActionUpgraded {
+		t.Errorf("expected the cursor on the latest upgrade, got %+v", event)
+	}
+
+	updated, _ = m.handleHistoryInput("enter")
+	m = updated.(Model)
+	if m.ShowHistory || m.Viewport.GetSelectedPackage().Name != "bash" {
+		t.Errorf("expected enter to jump to bash")
+	}
+}
+
+func TestModel_PacnewReplace(t *testing.T) {
+	m := newTestModel(t)
+	m.SetPreset(string(domain.PresetPacnew))
+	if len(m.Viewport.VisibleRows) != 2 {
+		t.Fatalf("expected bash and glibc in the Pacnew preset, got %d rows", len(m.Viewport.VisibleRows))
+	}
+
+	updated, cmd := m.Update(commandResultMsg{Result: command.Execute("pacnew")})
+	updated, _ = updated.Update(cmd())
+	m = updated.(Model)
+	if !m.ShowConfigs || len(m.ConfigFiles) != 3 || m.selectedConfig().New != "/etc/bash.bashrc.pacnew" {
+		t.Fatalf("expected three files to merge, got %+v", m.ConfigFiles)
+	}
+	if orphan := m.ConfigFiles[2]; orphan.New != "/etc/oldtool.conf.pacsave" || orphan.Owner() != "removed package" {
+		t.Errorf("expected the removed package's .pacsave last, got %+v", orphan)
+	}
+
+	updated, cmd = m.handleConfigsInput("enter")
+	updated, _ = updated.Update(cmd())
+	m = updated.(Model)
+	if m.ConfigDiff == nil || !reflect.DeepEqual(m.OutputLines[2:], []string{
+		"@@ -1,4 +1,4 @@",
+		" # /etc/bash.bashrc",
+		" [[ $- != *i* ]] && return",
+		` PS1='[\u@\h \W]\$ '`,
+		"-alias ls='ls --color=auto'",
+		"+[[ -r /usr/share/bash-completion/bash_completion ]] && . /usr/share/bash-completion/bash_completion",
+	}) {
+		t.Fatalf("unexpected diff %q", m.OutputLines)
+	}
+
+	updated, _ = m.handleNormalModeInput("r")
+	m = updated.(Model)
+	if !m.PendingConfig || !m.ConfigReplace || m.ConfigTarget.Path != "/etc/bash.bashrc" {
+		t.Fatalf("expected a pending replace, got %+v", m.ConfigTarget)
+	}
+
+	m.PendingConfig, m.ResolvingConfig = false, true
+	m = runTransaction(t, m, m.doResolveConfig(*m.ConfigTarget, true, ""))
+	if m.ConfigError != "" || len(m.ConfigFiles) != 2 || m.OutputTitle != "Replaced /etc/bash.bashrc with its pacnew" {
+		t.Fatalf("replace failed: %q, %q", m.ConfigError, m.OutputTitle)
+	}
+	if content, _ := m.Repo.ReadConfigFile("/etc/bash.bashrc"); !strings.Contains(content, "bash_completion") {
+		t.Errorf("expected the live file to be replaced, got %q", content)
+	}
+	if _, err := m.Repo.ReadConfigFile("/etc/bash.bashrc.pacnew"); err == nil {
+		t.Error("expected the .pacnew file to be gone")
+	}
+	if content, _ := m.Repo.ReadConfigFile("/etc/bash.bashrc.orig"); !strings.Contains(content, "alias ls") {
+		t.Errorf("expected the old live file to be kept as .orig, got %q", content)
+	}
+}
+
+func TestModel_Verify(t *testing.T) {
+	m := newTestModel(t)
+
+	updated, _ := m.Update(commandResultMsg{Result: command.Execute("verify all")})
+	m = updated.(Model)
+	if !m.Verifying || m.VerifyTotal != 6 || !m.ShowOutput {
+		t.Fatalf("expected 6 packages to be verifying, got %d", m.VerifyTotal)
+	}
+
+	stream := m.verifyStream
+	for m.Verifying {
+		updated, _ = m.Update(stream.next())
+		m = updated.(Model)
+	}
+	if !reflect.DeepEqual(m.OutputLines, []string{
+		"bash: /usr/bin/bash (SHA256 checksum mismatch)",
+		"bash: /usr/share/doc/bash/CHANGES (No such file or directory)",
+	}) {
+		t.Errorf("unexpected problems %q", m.OutputLines)
+	}
+	if want := "Verified 6 packages (10 files): 2 problems in 1 packages"; m.OutputTitle != want {
+		t.Errorf("OutputTitle = %q, want %q", m.OutputTitle, want)
+	}
+
+	updated, _ = m.handleNormalModeInput("enter")
+	m = updated.(Model)
+	if m.ShowOutput {
+		t.Fatal("expected Enter to dismiss the results")
+	}
+
+	updated, _ = m.Update(commandResultMsg{Result: command.Execute("verify all")})
+	m = updated.(Model)
+	stream = m.verifyStream
+	updated, _ = m.handleNormalModeInput("esc")
+	m = updated.(Model)
+	for m.Verifying {
+		updated, _ = m.Update(stream.next())
+		m = updated.(Model)
+	}
+	if !m.VerifyCancelled || !strings.HasPrefix(m.OutputTitle, "Verification cancelled") {
+		t.Errorf("expected the verification to be cancelled, got %q", m.OutputTitle)
+	}
+}
+
+func TestModel_DiskUsage(t *testing.T) {
+	m := newTestModel(t)
+
+	updated, cmd := m.Update(commandResultMsg{Result: command.Execute("du")})
+	updated, _ = updated.Update(cmd())
+	m = updated.(Model)
+	if !m.ShowDiskUsage || m.DiskUsage.Packages != 6 {
+		t.Fatalf("expected the disk usage of 6 packages, got %+v", m.DiskUsage)
+	}
+
+	fp := m.selectedFootprint()
+	if fp == nil || fp.Package.Name != "base" || len(fp.Deps) != 3 {
+		t.Fatalf("expected base and its 3 dependencies to be the largest footprint, got %+v", fp)
+	}
+
+	updated, _ = m.handleNormalModeInput("r")
+	m = updated.(Model)
+	if !m.PendingRemoval || len(m.RemovalPlan.Removed) != 4 || m.RemovalPlan.FreedSize != fp.Size {
+		t.Fatalf("expected a recursive removal freeing %d bytes, got %+v", fp.Size, m.RemovalPlan)
+	}
+	m.CancelRemoval()
+
+	for range 3 {
+		updated, _ = m.handleNormalModeInput("tab")
+		m = updated.(Model)
+	}
+	if m.UsageSection != domain.UsagePresets || m.selectedFootprint() != nil {
+		t.Fatalf("expected the presets section, got %v", m.UsageSection)
+	}
+
+	updated, _ = m.handleNormalModeInput("down")
+	updated, _ = updated.(Model).handleNormalModeInput("enter")
+	m = updated.(Model)
+	if m.ShowDiskUsage || m.Presets[m.CurrentPreset].Type != domain.PresetDependency {
+		t.Errorf("expected Enter to switch to the Dependencies preset, got %q", m.Presets[m.CurrentPreset].Type)
+	}
+}
+
+func TestModel_Import(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "laptop.txt")
+	if err := os.WriteFile(path, []byte("[repo]\nbash\nripgrep\n\n[foreign]\nyay-bin\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	m := newTestModel(t)
+	updated, cmd := m.Update(commandResultMsg{Result: command.Execute("import " + path)})
+	updated, _ = updated.Update(cmd())
+	m = updated.(Model)
+	if !m.ShowManifest || len(m.ManifestLines) != 6 {
+		t.Fatalf("expected ripgrep missing, base extra and bash installed as a dependency, got %+v", m.ManifestDiff)
+	}
+
+	updated, _ = m.handleNormalModeInput("enter")
+	m = updated.(Model)
+	if !m.PendingInstall || !reflect.DeepEqual(m.InstallingPkgs, []string{"ripgrep"}) || m.InstallViaAUR {
+		t.Fatalf("expected a pending install of ripgrep, got %v", m.InstallingPkgs)
+	}
+
+	m.PendingInstall, m.Installing = false, true
+	m = runTransaction(t, m, m.doInstall(m.InstallingPkgs, ""))
+	updated, _ = m.Update(m.loadPackages())
+	m = updated.(Model)
+	if len(m.ManifestDiff.Missing) != 0 || len(m.ManifestLines) != 4 {
+		t.Fatalf("expected the diff to drop ripgrep after installing it, got %+v", m.ManifestDiff)
+	}
+
+	updated, _ = m.handleNormalModeInput("a")
+	m = updated.(Model)
+	if !m.PendingRemoval || !reflect.DeepEqual(m.RemovingPkgs, []string{"base"}) {
+		t.Errorf("expected a pending removal of base, got %v", m.RemovingPkgs)
+	}
+}
+
+func TestModel_ExportTable(t *testing.T) {
+	m := newTestModel(t)
+	m.SetPreset(string(domain.PresetDependency))
+	path := filepath.Join(t.TempDir(), "deps.json")
+
+	updated, cmd := m.Update(commandResultMsg{Result: command.Execute("export " + path)})
+	updated, _ = updated.Update(cmd())
+	m = updated.(Model)
+	if m.RemoteError != "Exported 4 packages to "+path {
+		t.Fatalf("unexpected status %q", m.RemoteError)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var rows []map[string]any
+	if err := json.Unmarshal(data, &rows); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, data)
+	}
+	if len(rows) != 4 || rows[0]["name"] != m.Viewport.VisibleRows[0].Package.Name || rows[0]["size"] != float64(m.Viewport.VisibleRows[0].Package.InstalledSize) {
+		t.Errorf("unexpected export %s", data)
+	}
+
+	updated, _ = m.Update(commandResultMsg{Result: command.Execute("export " + filepath.Join(t.TempDir(), "deps.txt"))})
+	if got := updated.(Model).RemoteError; !strings.HasPrefix(got, "Cannot tell the format") {
+		t.Errorf("expected an unknown extension to be rejected, got %q", got)
+	}
+}
+
+func TestModel_FilterQuery(t *testing.T) {
+	m := newTestModel(t)
+
+	visible := func() []string {
+		var names []string
+		for _, row := range m.Viewport.VisibleRows {
+			names = append(names, row.Package.Name)
+		}
+		return names
+	}
+
+	for _, key := range []string{"/", "s", "i", "z", "e", ">", "9", "M"} {
+		updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)})
+		m = updated.(Model)
+	}
+	if m.FilterError != "" || !reflect.DeepEqual(visible(), []string{"glibc"}) {
+		t.Fatalf("expected size>9M to leave glibc, got %v (error %q)", visible(), m.FilterError)
+	}
+
+	for _, key := range []string{" ", "o", "r", " ", "r", "e", "p", "o", ":"} {
+		updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)})
+		m = updated.(Model)
+	}
+	if m.FilterError != `"repo:" needs a value` || !reflect.DeepEqual(visible(), []string{"glibc"}) {
+		t.Fatalf("expected an unfinished query to keep the rows, got %v (error %q)", visible(), m.FilterError)
+	}
+
+	for _, key := range []string{"f", "o", "r"} {
+		updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)})
+		m = updated.(Model)
+	}
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = updated.(Model)
+	if m.Mode != ModeNormal || m.FilterError != "" || !reflect.DeepEqual(visible(), []string{"glibc", "yay-bin"}) {
+		t.Errorf("expected glibc and yay-bin, got %v (error %q)", visible(), m.FilterError)
+	}
+	if f := m.Viewport.Filter; f.Query != "size>9M or repo:for" || !reflect.DeepEqual(f.Terms, []string{"9M", "for"}) || f.Column != "" {
+		t.Errorf("unexpected filter state %+v", f)
+	}
+}
+
+func TestModel_PacsaveDiffWithoutLiveFile(t *testing.T) {
+	m := newTestModel(t)
+	file := domain.ConfigFile{Path: "/etc/gone.conf", New: "/etc/nsswitch.conf.pacsave", Package: "glibc"}
+
+	updated, _ := m.Update(m.ShowConfigDiff(file)())
+	m = updated.(Model)
+	if m.RemoteError != "" || m.ConfigDiff == nil {
+		t.Fatalf("expected a diff against an empty file, got error %q", m.RemoteError)
+	}
+	if !strings.Contains(strings.Join(m.OutputLines, "\n"), "\n+hosts: files dns") {
+		t.Errorf("expected every line to be added, got %q", m.OutputLines)
+	}
+}
+
+func TestModel_UpgradeAUROnlyNeedsConfirmation(t *testing.T) {
+	m := newTestModel(t)
+	m.AURHelper = &aur.HelperConfig{Name: "yay", Path: "/usr/bin/yay"}
+	m.UpgradeAUR = true
+
+	updated, cmd := m.handleUpgradePreview(upgradePreviewMsg{})
+	m = updated.(Model)
+	if cmd != nil || m.Upgrading {
+		t.Fatal("expected the AUR helper to wait for confirmation")
+	}
+	if !m.PendingUpgrade || !strings.Contains(m.OutputTitle, "AUR packages to upgrade via yay") {
+		t.Errorf("expected an AUR upgrade preview, got %q", m.OutputTitle)
+	}
+
+	updated, _ = m.handlePendingUpgradeInput("esc")
+	m = updated.(Model)
+	if m.PendingUpgrade || m.UpgradeAUR || m.Upgrading {
+		t.Error("expected esc to cancel the AUR upgrade")
+	}
+}
+
+func TestModel_UpgradePreviewAfterSync(t *testing.T) {
+	m := newTestModel(t)
+	m.Synced = true
+
+	updated, _ := m.Update(m.doUpgradePreview()())
+	m = updated.(Model)
+	if !m.PendingUpgrade || m.OutputLines[len(m.OutputLines)-1] != resyncWarning {
+		t.Errorf("expected the preview to warn that pacman re-syncs, got %q", m.OutputLines)
+	}
+
+	m.CancelUpgrade()
+	m.AURHelper = nil
+	m.UpgradeAUR = true
+	updated, cmd := m.handleUpgradePreview(upgradePreviewMsg{})
+	m = updated.(Model)
+	if cmd != nil || m.UpgradeAUR || !strings.HasPrefix(m.RemoteError, "No AUR helper found") {
+		t.Errorf("expected a missing helper to be reported, got %q", m.RemoteError)
+	}
+}
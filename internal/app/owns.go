@@ -0,0 +1,63 @@
+package app
+
+import (
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/sjsanc/pacviz/v3/internal/domain"
+)
+
+type ownsResultMsg struct {
+	owners []domain.FileOwner
+	err    error
+}
+
+// FindOwners looks up the packages owning targets in the background.
+func (m Model) FindOwners(targets []string) tea.Cmd {
+	repo := m.Repo
+	return func() tea.Msg {
+		owners, err := repo.Owns(targets)
+		return ownsResultMsg{owners: owners, err: err}
+	}
+}
+
+// handleOwnsResult selects the first owning package. When several paths
+// were looked up the full pacman -Qo style report is shown in the output
+// pane; the user's marks are left alone.
+func (m Model) handleOwnsResult(msg ownsResultMsg) (tea.Model, tea.Cmd) {
+	if msg.err != nil {
+		m.RemoteError = fmt.Sprintf("Failed to look up owners: %v", msg.err)
+		return m, nil
+	}
+
+	var lines, packages []string
+	seen := make(map[string]bool)
+	for _, owner := range msg.owners {
+		if owner.Package == "" {
+			lines = append(lines, fmt.Sprintf("No package owns %s", owner.Query))
+			continue
+		}
+		lines = append(lines, fmt.Sprintf("%s is owned by %s %s", owner.Path, owner.Package, owner.Version))
+		if !seen[owner.Package] {
+			seen[owner.Package] = true
+			packages = append(packages, owner.Package)
+		}
+	}
+
+	var cmd tea.Cmd
+	if len(packages) > 0 {
+		cmd = m.JumpToPackage(packages[0])
+	}
+
+	if len(lines) == 1 {
+		m.RemoteError = lines[0]
+		return m, cmd
+	}
+
+	m.StartOutput(fmt.Sprintf("Owners of %d paths (%d packages)", len(lines), len(packages)))
+	m.OutputFollow = false
+	for _, line := range lines {
+		m.AppendOutput(line)
+	}
+	return m, cmd
+}
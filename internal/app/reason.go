@@ -0,0 +1,103 @@
+package app
+
+import (
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/sjsanc/pacviz/v3/internal/domain"
+)
+
+type installReasonCompleteMsg struct {
+	pkgNames []string
+	reason   domain.InstallReason
+	err      error
+}
+
+func describeReason(reason domain.InstallReason) string {
+	if reason == domain.ReasonDependency {
+		return "dependency"
+	}
+	return "explicit"
+}
+
+// InitiateReasonChange previews marking pkgs with a new install reason in
+// the output pane and waits for confirmation.
+func (m *Model) InitiateReasonChange(pkgs []*domain.Package, reason domain.InstallReason) {
+	m.PendingReason = true
+	m.ReasonPkgs = packageNames(pkgs)
+	m.NewReason = reason
+
+	m.StartOutput(fmt.Sprintf("Mark %s as %s", describeTargets(m.ReasonPkgs), describeReason(reason)))
+	m.OutputFollow = false
+	for _, pkg := range pkgs {
+		if pkg.InstallReason == reason {
+			m.AppendOutput(fmt.Sprintf("%s (already %s)", pkg.Name, describeReason(reason)))
+			continue
+		}
+		m.AppendOutput(fmt.Sprintf("%s: %s → %s", pkg.Name, describeReason(pkg.InstallReason), describeReason(reason)))
+	}
+}
+
+func (m *Model) CancelReasonChange() {
+	m.PendingReason = false
+	m.ReasonPkgs = nil
+	m.DismissOutput()
+}
+
+func (m Model) handlePendingReasonInput(key string) (tea.Model, tea.Cmd) {
+	switch key {
+	case "enter":
+		if !IsRunningAsRoot() {
+			m.EnterPasswordMode()
+			return m, nil
+		}
+		return m, m.SetInstallReason("")
+	case "esc", "ctrl+c":
+		m.CancelReasonChange()
+		return m, nil
+	default:
+		return m.handleOutputPaneInput(key)
+	}
+}
+
+// SetInstallReason runs pacman -D in the background. Its output is only
+// shown if it fails.
+func (m *Model) SetInstallReason(password string) tea.Cmd {
+	pkgNames := m.ReasonPkgs
+	reason := m.NewReason
+
+	m.PendingReason = false
+	m.SettingReason = true
+	m.StartOutput(fmt.Sprintf("Marking %s as %s", describeTargets(pkgNames), describeReason(reason)))
+	m.ShowOutput = false
+
+	return tea.Batch(
+		m.doSetInstallReason(pkgNames, reason, password),
+		tickSpinner(),
+	)
+}
+
+func (m Model) doSetInstallReason(pkgNames []string, reason domain.InstallReason, password string) tea.Cmd {
+	return startTransaction(func(out chan<- string) tea.Msg {
+		err := m.Repo.SetInstallReason(pkgNames, reason, password, out)
+		return installReasonCompleteMsg{pkgNames: pkgNames, reason: reason, err: err}
+	})
+}
+
+func (m Model) handleInstallReasonComplete(msg installReasonCompleteMsg) (tea.Model, tea.Cmd) {
+	m.SettingReason = false
+	m.ReasonPkgs = nil
+
+	if msg.err != nil {
+		m.OutputTitle = fmt.Sprintf("Failed to mark %s as %s", describeTargets(msg.pkgNames), describeReason(msg.reason))
+		m.ShowOutput = true
+		return m, nil
+	}
+
+	m.DismissOutput()
+	m.Viewport.ClearMarks()
+	m.RemoteError = fmt.Sprintf("Marked %s as %s", describeTargets(msg.pkgNames), describeReason(msg.reason))
+
+	// Reload so orphan status and presets reflect the new reasons
+	return m, m.refreshRepository(true)
+}
@@ -0,0 +1,150 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/sjsanc/pacviz/v3/internal/domain"
+)
+
+// installedPackages returns every installed package, whichever view is active.
+func (m Model) installedPackages() []*domain.Package {
+	rows := m.Viewport.AllRows
+	if m.ViewMode == ViewRemote {
+		rows = m.LocalRows
+	}
+
+	pkgs := make([]*domain.Package, 0, len(rows))
+	for _, row := range rows {
+		if row.Package != nil {
+			pkgs = append(pkgs, row.Package)
+		}
+	}
+	return pkgs
+}
+
+// InitiateRemoval plans the removal of pkgs and shows the preview for confirmation.
+func (m *Model) InitiateRemoval(pkgs []*domain.Package, opts domain.RemoveOptions) {
+	plan, err := domain.PlanRemoval(m.installedPackages(), packageNames(pkgs), opts)
+	if err != nil {
+		m.RemoteError = fmt.Sprintf("Cannot remove: %v", err)
+		return
+	}
+
+	m.PendingRemoval = true
+	m.RemovingPkgs = plan.Targets
+	m.RemovalPlan = plan
+	m.RemoveError = ""
+
+	title := fmt.Sprintf("Remove %d packages (%s freed)", len(plan.Removed), domain.FormatSize(plan.FreedSize))
+	if flags := opts.Flags(); len(flags) > 0 {
+		title += " " + strings.Join(flags, " ")
+	}
+	m.StartOutput(title)
+	m.OutputFollow = false
+
+	for _, line := range formatRemovalPlan(plan) {
+		m.AppendOutput(line)
+	}
+}
+
+// formatRemovalPlan renders the packages a removal takes with it and the
+// dependencies it would leave broken.
+func formatRemovalPlan(plan *domain.RemovalPlan) []string {
+	targets := make(map[string]bool, len(plan.Targets))
+	for _, name := range plan.Targets {
+		targets[name] = true
+	}
+
+	nameWidth, versionWidth := 0, 0
+	for _, pkg := range plan.Removed {
+		nameWidth = max(nameWidth, len(pkg.Name))
+		versionWidth = max(versionWidth, len(pkg.Version))
+	}
+
+	lines := []string{fmt.Sprintf("Packages (%d):", len(plan.Removed))}
+	for _, pkg := range plan.Removed {
+		var notes []string
+		if !targets[pkg.Name] {
+			if pkg.InstallReason == domain.ReasonDependency {
+				notes = append(notes, "unneeded dependency")
+			} else {
+				notes = append(notes, "depends on a target")
+			}
+		}
+		if len(pkg.OptionalFor) > 0 {
+			notes = append(notes, "optional for "+strings.Join(pkg.OptionalFor, ", "))
+		}
+		note := ""
+		if len(notes) > 0 {
+			note = "  (" + strings.Join(notes, "; ") + ")"
+		}
+		lines = append(lines, fmt.Sprintf("  %s%s  %s%s  %9s%s",
+			pkg.Name, strings.Repeat(" ", nameWidth-len(pkg.Name)),
+			pkg.Version, strings.Repeat(" ", versionWidth-len(pkg.Version)),
+			domain.FormatSize(pkg.InstalledSize), note))
+	}
+
+	if len(plan.Broken) > 0 {
+		lines = append(lines, "", fmt.Sprintf("Breaks dependencies (%d):", len(plan.Broken)))
+		for _, broken := range plan.Broken {
+			lines = append(lines, fmt.Sprintf("  %s is required by %s", broken.Name, strings.Join(broken.RequiredBy, ", ")))
+		}
+		lines = append(lines, "pacman will refuse this removal; use :remove -c to remove the dependent packages too")
+	}
+
+	return lines
+}
+
+func (m *Model) CancelRemoval() {
+	m.PendingRemoval = false
+	m.RemovingPkgs = nil
+	m.RemovalPlan = nil
+	m.DismissOutput()
+}
+
+func (m *Model) RemovePackages(pkgNames []string, password string) tea.Cmd {
+	var opts domain.RemoveOptions
+	if m.RemovalPlan != nil {
+		opts = m.RemovalPlan.Options
+	}
+
+	m.Removing = true
+	m.PendingRemoval = false
+	m.RemovingPkgs = pkgNames
+	m.RemovalPlan = nil
+	m.RemoveError = ""
+	m.StartOutput("Removing " + describeTargets(pkgNames))
+
+	return tea.Batch(
+		m.doRemove(pkgNames, opts, password),
+		tickSpinner(),
+	)
+}
+
+func (m Model) doRemove(pkgNames []string, opts domain.RemoveOptions, password string) tea.Cmd {
+	return startTransaction(func(out chan<- string) tea.Msg {
+		err := m.Repo.Remove(pkgNames, opts, password, out)
+		return removeCompleteMsg{
+			pkgNames: pkgNames,
+			err:      err,
+		}
+	})
+}
+
+func (m Model) handlePendingRemovalInput(key string) (tea.Model, tea.Cmd) {
+	switch key {
+	case "enter":
+		if !IsRunningAsRoot() {
+			m.EnterPasswordMode()
+			return m, nil
+		}
+		return m, m.RemovePackages(m.RemovingPkgs, "")
+	case "esc", "ctrl+c":
+		m.CancelRemoval()
+		return m, nil
+	default:
+		return m.handleOutputPaneInput(key)
+	}
+}
@@ -0,0 +1,72 @@
+package app
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+type syncCompleteMsg struct {
+	files bool
+	err   error
+}
+
+// SyncDatabases refreshes the sync databases into the private dbpath,
+// streaming progress into the output pane.
+func (m *Model) SyncDatabases() tea.Cmd {
+	m.Syncing = true
+	m.SyncError = ""
+	m.StartOutput("Synchronizing package databases")
+
+	return tea.Batch(
+		m.doSync(),
+		tickSpinner(),
+	)
+}
+
+func (m Model) doSync() tea.Cmd {
+	return startTransaction(func(out chan<- string) tea.Msg {
+		return syncCompleteMsg{err: m.Repo.SyncDatabases(out)}
+	})
+}
+
+// SyncFileDatabases downloads the .files databases used by file searches.
+func (m *Model) SyncFileDatabases() tea.Cmd {
+	m.Syncing = true
+	m.SyncError = ""
+	m.StartOutput("Synchronizing package file databases")
+
+	return tea.Batch(
+		m.doSyncFiles(),
+		tickSpinner(),
+	)
+}
+
+func (m Model) doSyncFiles() tea.Cmd {
+	return startTransaction(func(out chan<- string) tea.Msg {
+		return syncCompleteMsg{files: true, err: m.Repo.SyncFileDatabases(out)}
+	})
+}
+
+func (m Model) handleSyncComplete(msg syncCompleteMsg) (tea.Model, tea.Cmd) {
+	m.Syncing = false
+
+	if msg.files {
+		m.OutputTitle = "Synchronized package file databases"
+		if msg.err != nil {
+			m.SyncError = msg.err.Error()
+			m.OutputTitle = "Failed to synchronize package file databases"
+		}
+		return m, nil
+	}
+
+	m.OutputTitle = "Synchronized package databases"
+
+	if msg.err != nil {
+		m.SyncError = msg.err.Error()
+		m.OutputTitle = "Failed to synchronize package databases"
+	} else {
+		m.Synced = true
+	}
+
+	// The repository reopened itself against the fresh databases, so only reload.
+	return m, m.loadPackages
+}
@@ -0,0 +1,156 @@
+package app
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// transactionStream carries output from a running pacman transaction to the model.
+// The goroutine running the transaction sends lines and finally the completion message.
+type transactionStream struct {
+	lines chan string
+	done  chan tea.Msg
+}
+
+// transactionOutputMsg delivers one line of transaction output.
+type transactionOutputMsg struct {
+	line   string
+	stream *transactionStream
+}
+
+// startTransaction runs fn in the background and returns a command that
+// yields its output line by line, followed by the message fn returns.
+func startTransaction(fn func(out chan<- string) tea.Msg) tea.Cmd {
+	return func() tea.Msg {
+		stream := &transactionStream{
+			lines: make(chan string, 256),
+			done:  make(chan tea.Msg, 1),
+		}
+
+		go func() {
+			msg := fn(stream.lines)
+			close(stream.lines)
+			stream.done <- msg
+		}()
+
+		return stream.next()
+	}
+}
+
+func (s *transactionStream) next() tea.Msg {
+	if line, ok := <-s.lines; ok {
+		return transactionOutputMsg{line: line, stream: s}
+	}
+	return <-s.done
+}
+
+func (s *transactionStream) wait() tea.Cmd {
+	return s.next
+}
+
+// outputPaneHeight returns the number of rows used by the output pane, title included.
+func (m Model) outputPaneHeight() int {
+	height := m.Height / 2
+	if height < 5 {
+		height = 5
+	}
+	if height > 20 {
+		height = 20
+	}
+	return height
+}
+
+// StartOutput clears the output pane and shows it with a new title.
+func (m *Model) StartOutput(title string) {
+	m.OutputTitle = title
+	m.OutputLines = nil
+	m.OutputOffset = 0
+	m.OutputFollow = true
+	m.ShowOutput = true
+}
+
+// AppendOutput adds a line to the output pane, keeping the tail in view while following.
+func (m *Model) AppendOutput(line string) {
+	m.OutputLines = append(m.OutputLines, line)
+	if m.OutputFollow {
+		m.scrollOutputToBottom()
+	}
+}
+
+// DismissOutput hides the output pane and clears any transaction errors.
+func (m *Model) DismissOutput() {
+	m.ShowOutput = false
+	m.OutputLines = nil
+	m.OutputOffset = 0
+	m.OutputTitle = ""
+	m.InstallError = ""
+	m.RemoveError = ""
+	m.UpgradeError = ""
+	m.SyncError = ""
+	m.CleanError = ""
+	m.ConfigError = ""
+}
+
+// ScrollOutput moves the output pane by delta lines. Scrolling to the end
+// resumes following new output.
+func (m *Model) ScrollOutput(delta int) {
+	maxOffset := m.maxOutputOffset()
+	m.OutputOffset += delta
+	if m.OutputOffset > maxOffset {
+		m.OutputOffset = maxOffset
+	}
+	if m.OutputOffset < 0 {
+		m.OutputOffset = 0
+	}
+	m.OutputFollow = m.OutputOffset == maxOffset
+}
+
+func (m *Model) scrollOutputToBottom() {
+	m.OutputOffset = m.maxOutputOffset()
+	m.OutputFollow = true
+}
+
+func (m Model) maxOutputOffset() int {
+	bodyRows := m.outputPaneHeight() - 1
+	maxOffset := len(m.OutputLines) - bodyRows
+	if maxOffset < 0 {
+		return 0
+	}
+	return maxOffset
+}
+
+// transactionRunning reports whether a transaction is still producing output.
+func (m Model) transactionRunning() bool {
+	return m.Installing || m.Removing || m.Upgrading || m.Syncing || m.SettingReason || m.Cleaning || m.ResolvingConfig
+}
+
+func (m Model) handleTransactionOutput(msg transactionOutputMsg) (tea.Model, tea.Cmd) {
+	m.AppendOutput(msg.line)
+	return m, msg.stream.wait()
+}
+
+func (m Model) handleOutputPaneInput(key string) (tea.Model, tea.Cmd) {
+	half := max((m.outputPaneHeight()-1)/2, 1)
+
+	switch key {
+	case "ctrl+c", "q":
+		return m, tea.Quit
+	case "up", "k":
+		m.ScrollOutput(-1)
+	case "down", "j":
+		m.ScrollOutput(1)
+	case "ctrl+u", "pgup":
+		m.ScrollOutput(-half)
+	case "ctrl+d", "pgdown":
+		m.ScrollOutput(half)
+	case "home", "g":
+		m.ScrollOutput(-len(m.OutputLines))
+	case "G", "end":
+		m.scrollOutputToBottom()
+	case "enter", "esc":
+		if !m.transactionRunning() {
+			m.DismissOutput()
+		}
+	}
+
+	return m, nil
+}
@@ -0,0 +1,147 @@
+package app
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/sjsanc/pacviz/v3/internal/domain"
+)
+
+// listPaneHeight returns the number of rows used by the dependency tree and
+// file list panes, title included.
+func (m Model) listPaneHeight() int {
+	return max(m.Height*2/3, 5)
+}
+
+// OpenTree shows the dependency tree of pkg in the given direction.
+func (m *Model) OpenTree(pkg *domain.Package, dir domain.TreeDirection) {
+	if pkg == nil {
+		m.RemoteError = "No package selected"
+		return
+	}
+	if !pkg.Installed {
+		m.RemoteError = "Dependency tree is only available for installed packages"
+		return
+	}
+
+	m.Tree = domain.NewDependencyTree(m.installedPackages(), pkg.Name, dir)
+	m.ShowTree = true
+	m.ShowDetailPanel = false
+	m.TreeCursor = 0
+	m.TreeOffset = 0
+}
+
+func (m *Model) CloseTree() {
+	m.ShowTree = false
+	m.Tree = nil
+}
+
+// moveTreeCursor moves the cursor by delta lines, keeping it in view.
+func (m *Model) moveTreeCursor(delta int) {
+	m.setTreeCursor(m.TreeCursor + delta)
+}
+
+func (m *Model) setTreeCursor(index int) {
+	index = min(index, len(m.Tree.Nodes)-1)
+	m.TreeCursor = max(index, 0)
+
+	bodyRows := m.listPaneHeight() - 1
+	if m.TreeCursor < m.TreeOffset {
+		m.TreeOffset = m.TreeCursor
+	}
+	if m.TreeCursor >= m.TreeOffset+bodyRows {
+		m.TreeOffset = m.TreeCursor - bodyRows + 1
+	}
+}
+
+// JumpToPackage closes the tree, file list and history and selects the named
+// package in the table, falling back to the All preset when the current view
+// hides it.
+func (m *Model) JumpToPackage(name string) tea.Cmd {
+	m.CloseTree()
+	m.CloseFiles()
+	m.CloseHistory()
+	m.CloseDiskUsage()
+	m.CloseManifest()
+
+	if m.ViewMode == ViewRemote {
+		m.ExitRemoteMode()
+	}
+	if m.Viewport.SelectPackage(name) {
+		return nil
+	}
+
+	_, cmd := m.SetPreset(string(domain.PresetAll))
+	if !m.Viewport.SelectPackage(name) {
+		m.RemoteError = "Package not found: " + name
+	}
+	return cmd
+}
+
+func (m Model) handleTreeInput(key string) (tea.Model, tea.Cmd) {
+	half := max((m.listPaneHeight()-1)/2, 1)
+	tree := m.Tree
+	m.RemoteError = ""
+
+	switch key {
+	case "ctrl+c", "q":
+		return m, tea.Quit
+	case "esc", "t":
+		m.CloseTree()
+	case ":":
+		m.EnterCommandMode()
+	case "up", "k":
+		m.moveTreeCursor(-1)
+	case "down", "j":
+		m.moveTreeCursor(1)
+	case "ctrl+u", "pgup":
+		m.moveTreeCursor(-half)
+	case "ctrl+d", "pgdown":
+		m.moveTreeCursor(half)
+	case "home", "g":
+		m.setTreeCursor(0)
+	case "G", "end":
+		m.setTreeCursor(len(tree.Nodes) - 1)
+	case "right", "l":
+		if tree.Nodes[m.TreeCursor].Expanded {
+			m.moveTreeCursor(1)
+		} else {
+			tree.Expand(m.TreeCursor)
+		}
+	case "left", "h":
+		if !tree.Collapse(m.TreeCursor) {
+			if parent := tree.Parent(m.TreeCursor); parent >= 0 {
+				m.setTreeCursor(parent)
+			}
+		}
+	case " ", "space":
+		tree.Toggle(m.TreeCursor)
+	case "E":
+		tree.ExpandAll()
+	case "tab":
+		if tree.Direction == domain.TreeDepends {
+			tree.SetDirection(domain.TreeRequiredBy)
+		} else {
+			tree.SetDirection(domain.TreeDepends)
+		}
+		m.TreeOffset = 0
+		m.setTreeCursor(0)
+	case "+", "=":
+		if tree.MaxDepth > 0 {
+			tree.SetMaxDepth(tree.MaxDepth + 1)
+		}
+	case "-":
+		depth := tree.MaxDepth
+		if depth == 0 {
+			for _, node := range tree.Nodes {
+				depth = max(depth, node.Depth)
+			}
+		}
+		tree.SetMaxDepth(max(depth-1, 1))
+		m.setTreeCursor(m.TreeCursor)
+	case "0":
+		tree.SetMaxDepth(0)
+	case "enter":
+		return m, m.JumpToPackage(tree.Nodes[m.TreeCursor].Name)
+	}
+
+	return m, nil
+}
@@ -2,6 +2,7 @@ package app
 
 import (
 	"fmt"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/sjsanc/pacviz/v3/internal/domain"
@@ -11,6 +12,15 @@ import (
 
 // Update handles incoming messages and updates the model (Bubble Tea interface).
 func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	updated, cmd := m.update(msg)
+	if next, ok := updated.(Model); ok {
+		next.updateWhySummary()
+		updated = next
+	}
+	return updated, cmd
+}
+
+func (m Model) update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case packagesLoadedMsg:
 		return m.handlePackagesLoaded(msg)
@@ -26,10 +36,50 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m.handleRepositoryRefreshed(msg)
 	case spinnerTickMsg:
 		return m.handleSpinnerTick()
+	case transactionOutputMsg:
+		return m.handleTransactionOutput(msg)
 	case installCompleteMsg:
 		return m.handleInstallComplete(msg)
 	case removeCompleteMsg:
 		return m.handleRemoveComplete(msg)
+	case upgradePreviewMsg:
+		return m.handleUpgradePreview(msg)
+	case upgradeCompleteMsg:
+		return m.handleUpgradeComplete(msg)
+	case aurUpgradeCompleteMsg:
+		return m.handleAURUpgradeComplete(msg)
+	case syncCompleteMsg:
+		return m.handleSyncComplete(msg)
+	case installReasonCompleteMsg:
+		return m.handleInstallReasonComplete(msg)
+	case filesLoadedMsg:
+		return m.handleFilesLoaded(msg)
+	case configsLoadedMsg:
+		return m.handleConfigsLoaded(msg)
+	case configDiffMsg:
+		return m.handleConfigDiff(msg)
+	case configResolvedMsg:
+		return m.handleConfigResolved(msg)
+	case verifyProgressMsg:
+		return m.handleVerifyProgress(msg)
+	case verifyCompleteMsg:
+		return m.handleVerifyComplete(msg)
+	case diskUsageMsg:
+		return m.handleDiskUsage(msg)
+	case manifestLoadedMsg:
+		return m.handleManifestLoaded(msg)
+	case tableExportedMsg:
+		return m.handleTableExported(msg)
+	case historyLoadedMsg:
+		return m.handleHistoryLoaded(msg)
+	case cacheLoadedMsg:
+		return m.handleCacheLoaded(msg)
+	case cleanupPlannedMsg:
+		return m.handleCleanupPlanned(msg)
+	case cleanCompleteMsg:
+		return m.handleCleanComplete(msg)
+	case ownsResultMsg:
+		return m.handleOwnsResult(msg)
 	case commandResultMsg:
 		return m.handleCommandResult(msg)
 	case tea.KeyMsg:
@@ -58,7 +108,7 @@ func (m Model) handlePackagesLoaded(msg packagesLoadedMsg) (tea.Model, tea.Cmd)
 
 		var cmds []tea.Cmd
 		cmds = append(cmds, m.doRemoteSearch(m.RemoteQuery))
-		if m.AUREnabled {
+		if m.searchesAUR() {
 			cmds = append(cmds, m.doAURSearch(m.RemoteQuery))
 		}
 		m.syncSearchDone = false
@@ -84,19 +134,23 @@ func (m Model) handlePackagesLoaded(msg packagesLoadedMsg) (tea.Model, tea.Cmd)
 		m.Viewport.SelectedRow = 0
 	}
 
-	// Look up which foreign packages are AUR packages so Repo column shows "aur"
-	var cmds []tea.Cmd
+	cmds := []tea.Cmd{m.loadHistory, m.reloadDiskUsage()}
+	if m.ShowManifest {
+		m.diffManifest()
+	}
+	if m.ImportPath != "" {
+		cmds = append(cmds, m.OpenManifest(m.ImportPath))
+		m.ImportPath = ""
+	}
 	if presetCmd != nil {
 		cmds = append(cmds, presetCmd)
 	}
+	// Look up which foreign packages are AUR packages so Repo column shows "aur"
 	if m.AUREnabled && m.AURClient != nil {
 		cmds = append(cmds, m.doAURInfoLookup())
 	}
 
-	if len(cmds) > 0 {
-		return m, tea.Batch(cmds...)
-	}
-	return m, nil
+	return m, tea.Batch(cmds...)
 }
 
 func (m Model) handleRemoteSearchResult(msg remoteSearchResultMsg) (tea.Model, tea.Cmd) {
@@ -107,7 +161,7 @@ func (m Model) handleRemoteSearchResult(msg remoteSearchResultMsg) (tea.Model, t
 	}
 	m.syncSearchDone = true
 
-	if !m.AUREnabled || m.aurSearchDone {
+	if !m.searchesAUR() || m.aurSearchDone {
 		return m.finalizeSearchResults(msg.query, msg.err)
 	}
 
@@ -140,7 +194,7 @@ func (m Model) finalizeSearchResults(query string, syncErr error) (tea.Model, te
 
 	var merged []*domain.Package
 	if len(syncPkgs) > 0 && len(aurPkgs) > 0 {
-		merged = mergeSearchResults(syncPkgs, aurPkgs)
+		merged = MergeSearchResults(syncPkgs, aurPkgs)
 	} else if len(syncPkgs) > 0 {
 		merged = syncPkgs
 	} else if len(aurPkgs) > 0 {
@@ -150,6 +204,8 @@ func (m Model) finalizeSearchResults(query string, syncErr error) (tea.Model, te
 	if len(merged) == 0 {
 		if syncErr != nil {
 			m.RemoteError = syncErr.Error()
+		} else if m.RemoteFiles {
+			m.RemoteError = "No package contains a file matching: " + query
 		} else {
 			m.RemoteError = "No packages found for: " + query
 		}
@@ -184,21 +240,23 @@ func (m Model) handleAURInfoResult(msg aurInfoResultMsg) (tea.Model, tea.Cmd) {
 
 func (m Model) handleAURInstallComplete(msg aurInstallCompleteMsg) (tea.Model, tea.Cmd) {
 	m.Installing = false
-	m.InstallingPkg = ""
+	m.InstallingPkgs = nil
+	m.InstallViaAUR = false
 
 	m.InstallError = ""
+	m.StartOutput("AUR install")
 	if msg.err != nil {
 		m.InstallError = msg.err.Error()
-		m.InstallOutput = "AUR installation failed"
+		m.AppendOutput("AUR installation failed")
 	} else {
-		m.InstallOutput = "AUR package installed successfully"
+		m.AppendOutput("AUR package installed successfully")
 	}
 
 	cmds := []tea.Cmd{m.refreshRepository(true)}
 
 	if m.ViewMode == ViewRemote && m.RemoteQuery != "" {
 		cmds = append(cmds, m.doRemoteSearch(m.RemoteQuery))
-		if m.AUREnabled {
+		if m.searchesAUR() {
 			cmds = append(cmds, m.doAURSearch(m.RemoteQuery))
 		}
 		m.syncSearchDone = false
@@ -210,15 +268,17 @@ func (m Model) handleAURInstallComplete(msg aurInstallCompleteMsg) (tea.Model, t
 	return m, tea.Batch(cmds...)
 }
 
+// handleRepositoryRefreshed reloads what reads the repository only once
+// Refresh has reopened its handle, so nothing reads a released one.
 func (m Model) handleRepositoryRefreshed(msg repositoryRefreshedMsg) (tea.Model, tea.Cmd) {
 	if msg.shouldReload {
-		return m, m.loadPackages
+		return m, tea.Batch(m.loadPackages, m.reloadCache())
 	}
 	return m, nil
 }
 
 func (m Model) handleSpinnerTick() (tea.Model, tea.Cmd) {
-	if !m.RemoteLoading && !m.Installing && !m.Removing {
+	if !m.RemoteLoading && !m.transactionRunning() && !m.Verifying {
 		return m, nil
 	}
 
@@ -228,13 +288,15 @@ func (m Model) handleSpinnerTick() (tea.Model, tea.Cmd) {
 
 func (m Model) handleInstallComplete(msg installCompleteMsg) (tea.Model, tea.Cmd) {
 	m.Installing = false
-	m.InstallingPkg = ""
+	m.InstallingPkgs = nil
+	m.InstallArchives = nil
 
 	m.InstallError = ""
-	m.InstallOutput = msg.output
+	m.OutputTitle = "Installed " + describeTargets(msg.pkgNames)
 
 	if msg.err != nil {
 		m.InstallError = msg.err.Error()
+		m.OutputTitle = "Failed to install " + describeTargets(msg.pkgNames)
 	}
 
 	return m, m.refreshRepository(true)
@@ -242,13 +304,14 @@ func (m Model) handleInstallComplete(msg installCompleteMsg) (tea.Model, tea.Cmd
 
 func (m Model) handleRemoveComplete(msg removeCompleteMsg) (tea.Model, tea.Cmd) {
 	m.Removing = false
-	m.RemovingPkg = ""
+	m.RemovingPkgs = nil
 
 	m.RemoveError = ""
-	m.RemoveOutput = msg.output
+	m.OutputTitle = "Removed " + describeTargets(msg.pkgNames)
 
 	if msg.err != nil {
 		m.RemoveError = msg.err.Error()
+		m.OutputTitle = "Failed to remove " + describeTargets(msg.pkgNames)
 	}
 
 	return m, m.refreshRepository(true)
@@ -256,6 +319,7 @@ func (m Model) handleRemoveComplete(msg removeCompleteMsg) (tea.Model, tea.Cmd)
 
 func (m Model) handleKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	key := msg.String()
+	m.Clipboard = ""
 
 	switch m.Mode {
 	case ModeCommand:
@@ -275,19 +339,19 @@ func (m Model) handleNormalModeInput(key string) (tea.Model, tea.Cmd) {
 	if m.PendingInstall {
 		switch key {
 		case "enter":
-			pkgName := m.InstallingPkg
+			pkgNames := m.InstallingPkgs
 
-			if m.isSelectedPackageAUR() {
+			if m.InstallViaAUR {
 				m.PendingInstall = false
 				m.Installing = true
-				return m, m.doAURInstall(pkgName)
+				return m, m.doAURInstall(pkgNames)
 			}
 
 			if !IsRunningAsRoot() {
 				m.EnterPasswordMode()
 				return m, nil
 			}
-			return m, m.InstallPackage(pkgName, "")
+			return m, m.InstallPackages(pkgNames, "")
 		case "esc", "ctrl+c":
 			m.CancelInstall()
 			return m, nil
@@ -297,50 +361,79 @@ func (m Model) handleNormalModeInput(key string) (tea.Model, tea.Cmd) {
 	}
 
 	if m.PendingRemoval {
-		switch key {
-		case "enter":
-			if !IsRunningAsRoot() {
-				m.EnterPasswordMode()
-				return m, nil
-			}
-			pkgName := m.RemovingPkg
-			return m, m.RemovePackage(pkgName, "")
-		case "esc", "ctrl+c":
-			m.CancelRemoval()
-			return m, nil
-		default:
-			return m, nil
-		}
+		return m.handlePendingRemovalInput(key)
+	}
+
+	if m.PendingUpgrade {
+		return m.handlePendingUpgradeInput(key)
+	}
+
+	if m.PendingReason {
+		return m.handlePendingReasonInput(key)
+	}
+
+	if m.PendingClean {
+		return m.handlePendingCleanInput(key)
+	}
+
+	if m.PendingConfig {
+		return m.handlePendingConfigInput(key)
+	}
+
+	if m.ConfigDiff != nil && m.ShowOutput {
+		return m.handleConfigDiffInput(key)
+	}
+
+	if m.Verifying {
+		return m.handleVerifyInput(key)
+	}
+
+	if m.ShowOutput {
+		return m.handleOutputPaneInput(key)
+	}
+
+	if m.ShowTree {
+		return m.handleTreeInput(key)
+	}
+
+	if m.ShowFiles {
+		return m.handleFilesInput(key)
+	}
+
+	if m.ShowCache {
+		return m.handleCacheInput(key)
+	}
+
+	if m.ShowHistory {
+		return m.handleHistoryInput(key)
+	}
+
+	if m.ShowConfigs {
+		return m.handleConfigsInput(key)
+	}
+
+	if m.ShowDiskUsage {
+		return m.handleDiskUsageInput(key)
+	}
+
+	if m.ShowManifest {
+		return m.handleManifestInput(key)
+	}
+
+	if m.ViewMode == ViewLocal {
+		m.RemoteError = ""
 	}
 
 	switch key {
 	case "ctrl+c", "q":
 		return m, tea.Quit
 	case "enter":
-		if m.RemoveOutput != "" {
-			m.RemoveOutput = ""
-			m.RemoveError = ""
-			return m, nil
-		}
-		if m.InstallOutput != "" {
-			m.InstallOutput = ""
-			m.InstallError = ""
-			return m, nil
-		}
 		m.ShowDetailPanel = !m.ShowDetailPanel
 	case "esc":
 		if m.ShowDetailPanel {
 			m.ShowDetailPanel = false
 			return m, nil
 		}
-		if m.RemoveOutput != "" {
-			m.RemoveOutput = ""
-			return m, nil
-		}
-		if m.InstallOutput != "" {
-			m.InstallOutput = ""
-			return m, nil
-		}
 		if m.ViewMode == ViewRemote {
 			m.ExitRemoteMode()
 			return m, nil
@@ -380,9 +473,31 @@ func (m Model) handleNormalModeInput(key string) (tea.Model, tea.Cmd) {
 	case "i":
 		if m.ViewMode == ViewRemote && m.ShowDetailPanel && m.Viewport.SelectedRow >= 0 && m.Viewport.SelectedRow < len(m.Viewport.VisibleRows) {
 			selectedRow := m.Viewport.VisibleRows[m.Viewport.SelectedRow]
-			pkgName := selectedRow.Cells[column.ColName]
-			m.InitiateInstall(pkgName)
+			m.InitiateInstall([]*domain.Package{selectedRow.Package})
+		}
+	case "t":
+		m.OpenTree(m.Viewport.GetSelectedPackage(), domain.TreeDepends)
+	case "f":
+		return m, m.OpenFiles(m.Viewport.GetSelectedPackage())
+	case "c":
+		filter := ""
+		if pkg := m.Viewport.GetSelectedPackage(); pkg != nil {
+			filter = pkg.Name
 		}
+		return m, m.OpenCache(filter)
+	case "H":
+		return m, m.OpenHistory("")
+	case "D":
+		return m, m.OpenDiskUsage()
+	case "m":
+		m.Viewport.ToggleMark()
+		m.Viewport.SelectNext()
+	case "M":
+		m.Viewport.MarkAllVisible()
+	case "*":
+		m.Viewport.InvertMarks()
+	case "u":
+		m.Viewport.ClearMarks()
 	}
 
 	return m, nil
@@ -407,26 +522,37 @@ func (m Model) handleFilterModeInput(key string) (tea.Model, tea.Cmd) {
 	switch key {
 	case "esc":
 		m.ExitMode()
+		m.FilterError = ""
 		m.Viewport.ClearFilter()
 	case "enter":
+		// An unfinished query keeps the last filter that parsed.
 		m.ExitMode()
+		m.FilterError = ""
 	case "backspace":
 		m.WriteToBuffer(key)
-		filterTerm := m.GetBufferContent()
-		m.Viewport.ApplyFilter(filterTerm)
+		m.applyFilter()
 	case "left", "right":
 	case "up", "down", "ctrl+a", "ctrl+e", "ctrl+k", "ctrl+u", "ctrl+w":
 	default:
 		if isValidSearchChar(key) {
 			m.WriteToBuffer(key)
-			filterTerm := m.GetBufferContent()
-			m.Viewport.ApplyFilter(filterTerm)
+			m.applyFilter()
 		}
 	}
 
 	return m, nil
 }
 
+// applyFilter filters the table by the query being typed, or keeps the
+// rows and shows why it does not parse yet.
+func (m *Model) applyFilter() {
+	if err := m.Viewport.ApplyFilter(m.GetBufferContent()); err != nil {
+		m.FilterError = err.Error()
+		return
+	}
+	m.FilterError = ""
+}
+
 func (m Model) handlePasswordModeInput(key string) (tea.Model, tea.Cmd) {
 	switch key {
 	case "esc":
@@ -438,6 +564,18 @@ func (m Model) handlePasswordModeInput(key string) (tea.Model, tea.Cmd) {
 		if m.PendingRemoval {
 			m.CancelRemoval()
 		}
+		if m.PendingUpgrade {
+			m.CancelUpgrade()
+		}
+		if m.PendingReason {
+			m.CancelReasonChange()
+		}
+		if m.PendingClean {
+			m.CancelClean()
+		}
+		if m.PendingConfig {
+			m.CancelConfigResolve()
+		}
 		return m, nil
 	case "enter":
 		password := m.PasswordBuffer
@@ -445,12 +583,22 @@ func (m Model) handlePasswordModeInput(key string) (tea.Model, tea.Cmd) {
 		m.ClearPasswordBuffer()
 
 		if m.PendingInstall {
-			pkgName := m.InstallingPkg
-			return m, m.InstallPackage(pkgName, password)
+			return m, m.InstallPackages(m.InstallingPkgs, password)
 		}
 		if m.PendingRemoval {
-			pkgName := m.RemovingPkg
-			return m, m.RemovePackage(pkgName, password)
+			return m, m.RemovePackages(m.RemovingPkgs, password)
+		}
+		if m.PendingUpgrade {
+			return m, m.UpgradeSystem(password)
+		}
+		if m.PendingReason {
+			return m, m.SetInstallReason(password)
+		}
+		if m.PendingClean {
+			return m, m.CleanCache(password)
+		}
+		if m.PendingConfig {
+			return m, m.ResolveConfig(password)
 		}
 		return m, nil
 	default:
@@ -480,6 +628,10 @@ func (m Model) handleCommandResult(msg commandResultMsg) (tea.Model, tea.Cmd) {
 		return m, m.EnterRemoteMode(result.RemoteSearch)
 	}
 
+	if result.FileSearch != "" {
+		return m, m.EnterFileSearchMode(result.FileSearch)
+	}
+
 	if result.PresetChange != "" {
 		ok, cmd := m.SetPreset(result.PresetChange)
 		if !ok {
@@ -502,10 +654,8 @@ func (m Model) handleCommandResult(msg commandResultMsg) (tea.Model, tea.Cmd) {
 	}
 
 	if result.InstallPackage {
-		if m.ViewMode == ViewRemote && m.Viewport.SelectedRow >= 0 && m.Viewport.SelectedRow < len(m.Viewport.VisibleRows) {
-			selectedRow := m.Viewport.VisibleRows[m.Viewport.SelectedRow]
-			pkgName := selectedRow.Cells[column.ColName]
-			m.InitiateInstall(pkgName)
+		if targets := m.targetPackages(); m.ViewMode == ViewRemote && len(targets) > 0 {
+			m.InitiateInstall(targets)
 		} else if m.ViewMode != ViewRemote {
 			m.RemoteError = "Install command only works in search mode"
 		} else {
@@ -514,10 +664,12 @@ func (m Model) handleCommandResult(msg commandResultMsg) (tea.Model, tea.Cmd) {
 	}
 
 	if result.RemovePackage {
-		if m.ViewMode == ViewLocal && m.Viewport.SelectedRow >= 0 && m.Viewport.SelectedRow < len(m.Viewport.VisibleRows) {
-			selectedRow := m.Viewport.VisibleRows[m.Viewport.SelectedRow]
-			pkgName := selectedRow.Cells[column.ColName]
-			m.InitiateRemoval(pkgName)
+		if targets := m.targetPackages(); m.ViewMode == ViewLocal && len(targets) > 0 {
+			m.InitiateRemoval(targets, domain.RemoveOptions{
+				Recursive: result.RemoveRecursive,
+				Cascade:   result.RemoveCascade,
+				NoSave:    result.RemoveNoSave,
+			})
 		} else if m.ViewMode != ViewLocal {
 			m.RemoteError = "Remove command only works in local mode"
 		} else {
@@ -525,6 +677,166 @@ func (m Model) handleCommandResult(msg commandResultMsg) (tea.Model, tea.Cmd) {
 		}
 	}
 
+	if result.Upgrade {
+		if m.ViewMode != ViewLocal {
+			m.RemoteError = "Upgrade command only works in local mode"
+			return m, nil
+		}
+		if m.transactionRunning() {
+			m.RemoteError = "A transaction is already running"
+			return m, nil
+		}
+		return m, m.InitiateUpgrade(result.UpgradeAUR)
+	}
+
+	if result.AutoRemove {
+		if m.ViewMode != ViewLocal {
+			m.RemoteError = "Autoremove only works in local mode"
+			return m, nil
+		}
+
+		var unneeded []*domain.Package
+		for _, pkg := range m.installedPackages() {
+			if pkg.IsUnneeded {
+				unneeded = append(unneeded, pkg)
+			}
+		}
+		if len(unneeded) == 0 {
+			m.RemoteError = "No unneeded packages"
+			return m, nil
+		}
+		m.InitiateRemoval(unneeded, domain.RemoveOptions{})
+		return m, nil
+	}
+
+	if result.MarkAsDeps || result.MarkAsExplicit {
+		targets := m.targetPackages()
+		if m.ViewMode != ViewLocal {
+			m.RemoteError = "Install reason can only be changed in local mode"
+			return m, nil
+		}
+		if len(targets) == 0 {
+			m.RemoteError = "No package selected"
+			return m, nil
+		}
+		if m.transactionRunning() {
+			m.RemoteError = "A transaction is already running"
+			return m, nil
+		}
+
+		reason := domain.ReasonExplicit
+		if result.MarkAsDeps {
+			reason = domain.ReasonDependency
+		}
+		m.InitiateReasonChange(targets, reason)
+		return m, nil
+	}
+
+	if result.ShowTree {
+		dir := domain.TreeDepends
+		if result.TreeReverse {
+			dir = domain.TreeRequiredBy
+		}
+		m.OpenTree(m.Viewport.GetSelectedPackage(), dir)
+		return m, nil
+	}
+
+	if result.ShowFiles {
+		return m, m.OpenFiles(m.Viewport.GetSelectedPackage())
+	}
+
+	if result.ShowCache {
+		return m, m.OpenCache(result.CacheFilter)
+	}
+
+	if result.ShowConfigs {
+		return m, m.OpenConfigs()
+	}
+
+	if result.ShowHistory {
+		return m, m.OpenHistory(result.HistoryFilter)
+	}
+
+	if result.ShowDiskUsage {
+		return m, m.OpenDiskUsage()
+	}
+
+	if result.ExportPath != "" {
+		return m, m.ExportTable(result.ExportPath, result.ExportFormat)
+	}
+
+	if result.ImportPath != "" {
+		if m.ViewMode != ViewLocal {
+			m.RemoteError = "Import command only works in local mode"
+			return m, nil
+		}
+		return m, m.OpenManifest(result.ImportPath)
+	}
+
+	if result.Verify {
+		if m.ViewMode != ViewLocal {
+			m.RemoteError = "Verify command only works in local mode"
+			return m, nil
+		}
+		if m.Verifying {
+			m.RemoteError = "A verification is already running"
+			return m, nil
+		}
+		targets := m.verifyTargets(result.VerifyScope)
+		if len(targets) == 0 {
+			m.RemoteError = "No package selected"
+			return m, nil
+		}
+		return m, m.VerifyPackages(targets)
+	}
+
+	if result.CleanCache {
+		if m.transactionRunning() {
+			m.RemoteError = "A transaction is already running"
+			return m, nil
+		}
+		return m, m.PlanCleanCache(domain.CachePolicy{
+			Keep:        result.CleanKeep,
+			Uninstalled: result.CleanUninstalled,
+			MaxAge:      time.Duration(result.CleanOlderDays) * 24 * time.Hour,
+		})
+	}
+
+	if result.CopyPath {
+		m.CopySelectedPath()
+		return m, nil
+	}
+
+	if len(result.OwnsPaths) > 0 {
+		return m, m.FindOwners(result.OwnsPaths)
+	}
+
+	if result.Why {
+		name := result.WhyPackage
+		if name == "" && m.ShowTree {
+			name = m.Tree.Nodes[m.TreeCursor].Name
+		} else if pkg := m.Viewport.GetSelectedPackage(); name == "" && pkg != nil {
+			name = pkg.Name
+		}
+		if name == "" {
+			m.RemoteError = "No package selected"
+			return m, nil
+		}
+		m.ShowWhy(name)
+		return m, nil
+	}
+
+	if result.SyncDatabases {
+		if m.transactionRunning() {
+			m.RemoteError = "A transaction is already running"
+			return m, nil
+		}
+		if result.SyncFiles {
+			return m, m.SyncFileDatabases()
+		}
+		return m, m.SyncDatabases()
+	}
+
 	if result.ThemeName != "" {
 		theme, err := styles.LoadTheme(result.ThemeName)
 		if err != nil {
@@ -553,9 +865,17 @@ func (m Model) handleMouseEvent(msg tea.MouseMsg) (tea.Model, tea.Cmd) {
 
 	switch msg.Type {
 	case tea.MouseWheelUp:
+		if m.ShowOutput {
+			m.ScrollOutput(-1)
+			return m, nil
+		}
 		m.Viewport.SelectPrev()
 		return m, nil
 	case tea.MouseWheelDown:
+		if m.ShowOutput {
+			m.ScrollOutput(1)
+			return m, nil
+		}
 		m.Viewport.SelectNext()
 		return m, nil
 	}
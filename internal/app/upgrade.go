@@ -0,0 +1,212 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/sjsanc/pacviz/v3/internal/aur"
+	"github.com/sjsanc/pacviz/v3/internal/domain"
+)
+
+type upgradePreviewMsg struct {
+	upgrades []domain.Upgrade
+	err      error
+}
+
+type upgradeCompleteMsg struct {
+	count int
+	err   error
+}
+
+type aurUpgradeCompleteMsg struct {
+	err error
+}
+
+// resyncWarning is shown with an upgrade preview built from databases :sync
+// refreshed. pacman cannot read those private copies, so it refreshes its own
+// from the servers in pacman.conf and may find other versions.
+const resyncWarning = "⚠ pacman first refreshes its own databases from pacman.conf's servers, so the versions installed may differ from this preview"
+
+// InitiateUpgrade looks up pending upgrades so they can be previewed before confirming.
+func (m *Model) InitiateUpgrade(includeAUR bool) tea.Cmd {
+	m.UpgradeAUR = includeAUR
+	m.UpgradeError = ""
+	return m.doUpgradePreview()
+}
+
+func (m Model) doUpgradePreview() tea.Cmd {
+	return func() tea.Msg {
+		upgrades, err := m.Repo.PendingUpgrades()
+		return upgradePreviewMsg{upgrades: upgrades, err: err}
+	}
+}
+
+func (m Model) handleUpgradePreview(msg upgradePreviewMsg) (tea.Model, tea.Cmd) {
+	if msg.err != nil {
+		m.RemoteError = fmt.Sprintf("Failed to check for upgrades: %v", msg.err)
+		return m, nil
+	}
+
+	if len(msg.upgrades) == 0 {
+		if !m.UpgradeAUR {
+			m.RemoteError = "System is up to date"
+			return m, nil
+		}
+		if m.AURHelper == nil {
+			m.UpgradeAUR = false
+			m.RemoteError = "No AUR helper found (install yay, paru, pikaur, or trizen)"
+			return m, nil
+		}
+	}
+
+	m.PendingUpgrade = true
+	m.Upgrades = msg.upgrades
+
+	title := fmt.Sprintf("%d packages to upgrade (%s download)",
+		len(msg.upgrades), domain.FormatSize(domain.TotalDownloadSize(msg.upgrades)))
+	if m.UpgradeAUR && m.AURHelper != nil {
+		if len(msg.upgrades) == 0 {
+			title = "Repository packages are up to date, AUR packages to upgrade via " + m.AURHelper.Name
+		} else {
+			title += ", then AUR packages via " + m.AURHelper.Name
+		}
+	}
+	m.StartOutput(title)
+	m.OutputFollow = false
+
+	for _, line := range formatUpgrades(msg.upgrades) {
+		m.AppendOutput(line)
+	}
+	if m.Synced && len(msg.upgrades) > 0 {
+		m.AppendOutput("")
+		m.AppendOutput(resyncWarning)
+	}
+
+	return m, nil
+}
+
+// formatUpgrades renders one aligned "repo/name old → new size" line per upgrade.
+func formatUpgrades(upgrades []domain.Upgrade) []string {
+	nameWidth, versionWidth := 0, 0
+	for _, u := range upgrades {
+		nameWidth = max(nameWidth, len(u.Repository)+1+len(u.Name))
+		versionWidth = max(versionWidth, len(u.OldVersion))
+	}
+
+	lines := make([]string, 0, len(upgrades))
+	for _, u := range upgrades {
+		name := u.Repository + "/" + u.Name
+		lines = append(lines, fmt.Sprintf("%s%s  %s%s → %s  %s",
+			name, strings.Repeat(" ", nameWidth-len(name)),
+			u.OldVersion, strings.Repeat(" ", versionWidth-len(u.OldVersion)),
+			u.NewVersion, domain.FormatSize(u.DownloadSize)))
+	}
+	return lines
+}
+
+func (m *Model) CancelUpgrade() {
+	m.PendingUpgrade = false
+	m.UpgradeAUR = false
+	m.Upgrades = nil
+	m.DismissOutput()
+}
+
+// UpgradeSystem runs the confirmed system upgrade, streaming its output into the pane.
+func (m *Model) UpgradeSystem(password string) tea.Cmd {
+	count := len(m.Upgrades)
+	m.Upgrading = true
+	m.PendingUpgrade = false
+	m.Upgrades = nil
+	m.UpgradeError = ""
+	m.StartOutput(fmt.Sprintf("Upgrading %d packages", count))
+
+	return tea.Batch(
+		m.doUpgrade(count, password),
+		tickSpinner(),
+	)
+}
+
+func (m Model) doUpgrade(count int, password string) tea.Cmd {
+	return startTransaction(func(out chan<- string) tea.Msg {
+		err := m.Repo.Upgrade(password, out)
+		return upgradeCompleteMsg{count: count, err: err}
+	})
+}
+
+func (m Model) handleUpgradeComplete(msg upgradeCompleteMsg) (tea.Model, tea.Cmd) {
+	m.Upgrading = false
+
+	if msg.err != nil {
+		m.UpgradeError = msg.err.Error()
+		m.UpgradeAUR = false
+		m.OutputTitle = "System upgrade failed"
+		return m, m.refreshRepository(true)
+	}
+
+	m.OutputTitle = fmt.Sprintf("Upgraded %d packages", msg.count)
+
+	if m.UpgradeAUR {
+		if m.AURHelper == nil {
+			m.UpgradeAUR = false
+			m.AppendOutput("no AUR helper found (install yay, paru, pikaur, or trizen), skipping AUR upgrade")
+			return m, m.refreshRepository(true)
+		}
+		return m, tea.Batch(m.refreshRepository(false), m.UpgradeAURPackages())
+	}
+
+	return m, m.refreshRepository(true)
+}
+
+// UpgradeAURPackages suspends the TUI and runs the AUR helper's upgrade interactively.
+func (m *Model) UpgradeAURPackages() tea.Cmd {
+	if m.AURHelper == nil {
+		m.UpgradeAUR = false
+		m.RemoteError = "No AUR helper found (install yay, paru, pikaur, or trizen)"
+		return nil
+	}
+
+	m.Upgrading = true
+	cmd := aur.UpgradeCmd(m.AURHelper)
+	return tea.ExecProcess(cmd, func(err error) tea.Msg {
+		return aurUpgradeCompleteMsg{err: err}
+	})
+}
+
+func (m Model) handleAURUpgradeComplete(msg aurUpgradeCompleteMsg) (tea.Model, tea.Cmd) {
+	m.Upgrading = false
+	m.UpgradeAUR = false
+
+	if !m.ShowOutput {
+		m.StartOutput("AUR upgrade")
+	}
+	if msg.err != nil {
+		m.UpgradeError = msg.err.Error()
+		m.AppendOutput(fmt.Sprintf("AUR upgrade via %s failed", m.AURHelper.Name))
+	} else {
+		m.AppendOutput(fmt.Sprintf("AUR packages upgraded via %s", m.AURHelper.Name))
+	}
+
+	return m, m.refreshRepository(true)
+}
+
+func (m Model) handlePendingUpgradeInput(key string) (tea.Model, tea.Cmd) {
+	switch key {
+	case "enter":
+		if len(m.Upgrades) == 0 {
+			// Only AUR packages: the helper asks for sudo itself.
+			m.PendingUpgrade = false
+			return m, m.UpgradeAURPackages()
+		}
+		if !IsRunningAsRoot() {
+			m.EnterPasswordMode()
+			return m, nil
+		}
+		return m, m.UpgradeSystem("")
+	case "esc", "ctrl+c":
+		m.CancelUpgrade()
+		return m, nil
+	default:
+		return m.handleOutputPaneInput(key)
+	}
+}
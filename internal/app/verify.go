@@ -0,0 +1,181 @@
+package app
+
+import (
+	"context"
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/sjsanc/pacviz/v3/internal/domain"
+	"github.com/sjsanc/pacviz/v3/internal/repository"
+)
+
+// verifyStream carries the results of a running :verify to the model, one
+// package at a time, and stops the goroutine producing them when cancelled.
+type verifyStream struct {
+	results chan verifyProgressMsg
+	cancel  context.CancelFunc
+}
+
+// verifyProgressMsg delivers the result of verifying one package.
+type verifyProgressMsg struct {
+	result domain.VerifyResult
+	err    error
+	name   string
+	stream *verifyStream
+}
+
+// verifyCompleteMsg is sent once every package has been verified, or the
+// verification was cancelled.
+type verifyCompleteMsg struct {
+	stream *verifyStream
+}
+
+// verifyTargets returns the packages :verify checks for scope: every
+// installed package, the rows left by the filter, or the marked or
+// selected packages.
+func (m Model) verifyTargets(scope string) []*domain.Package {
+	switch scope {
+	case "all":
+		return m.installedPackages()
+	case "visible":
+		pkgs := make([]*domain.Package, 0, len(m.Viewport.VisibleRows))
+		for _, row := range m.Viewport.VisibleRows {
+			if row.Package != nil {
+				pkgs = append(pkgs, row.Package)
+			}
+		}
+		return pkgs
+	default:
+		return m.targetPackages()
+	}
+}
+
+// VerifyPackages checks the packages' files in the background, listing the
+// problems found in the output pane as they come in.
+func (m *Model) VerifyPackages(pkgs []*domain.Package) tea.Cmd {
+	ctx, cancel := context.WithCancel(context.Background())
+	stream := &verifyStream{
+		results: make(chan verifyProgressMsg, 16),
+		cancel:  cancel,
+	}
+
+	m.Verifying = true
+	m.VerifyCancelled = false
+	m.VerifyDone = 0
+	m.VerifyTotal = len(pkgs)
+	m.VerifyFiles = 0
+	m.VerifyIssues = 0
+	m.VerifyBroken = 0
+	m.verifyStream = stream
+	m.StartOutput(m.verifyTitle())
+
+	names := packageNames(pkgs)
+	repo := m.Repo
+	go verifyAll(ctx, repo, names, stream.results)
+
+	return tea.Batch(stream.next, tickSpinner())
+}
+
+func verifyAll(ctx context.Context, repo repository.Repository, names []string, results chan<- verifyProgressMsg) {
+	defer close(results)
+	for _, name := range names {
+		if ctx.Err() != nil {
+			return
+		}
+		result, err := repo.VerifyPackage(ctx, name)
+		if ctx.Err() != nil {
+			return
+		}
+		select {
+		case results <- verifyProgressMsg{result: result, err: err, name: name}:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (s *verifyStream) next() tea.Msg {
+	msg, ok := <-s.results
+	if !ok {
+		return verifyCompleteMsg{stream: s}
+	}
+	msg.stream = s
+	return msg
+}
+
+// CancelVerify stops a running verification; the packages already checked
+// stay listed.
+func (m *Model) CancelVerify() {
+	if m.verifyStream == nil {
+		return
+	}
+	m.VerifyCancelled = true
+	m.verifyStream.cancel()
+}
+
+// verifyTitle summarises the verification so far for the output pane.
+func (m Model) verifyTitle() string {
+	switch {
+	case m.Verifying:
+		return fmt.Sprintf("Verifying %d/%d packages, %d problems", m.VerifyDone, m.VerifyTotal, m.VerifyIssues)
+	case m.VerifyCancelled:
+		return fmt.Sprintf("Verification cancelled after %d of %d packages: %d problems in %d packages",
+			m.VerifyDone, m.VerifyTotal, m.VerifyIssues, m.VerifyBroken)
+	case m.VerifyIssues == 0:
+		return fmt.Sprintf("Verified %d packages (%d files): no problems found", m.VerifyDone, m.VerifyFiles)
+	default:
+		return fmt.Sprintf("Verified %d packages (%d files): %d problems in %d packages",
+			m.VerifyDone, m.VerifyFiles, m.VerifyIssues, m.VerifyBroken)
+	}
+}
+
+func (m Model) handleVerifyProgress(msg verifyProgressMsg) (tea.Model, tea.Cmd) {
+	if msg.stream != m.verifyStream {
+		return m, nil // left over from a cancelled run
+	}
+
+	m.VerifyDone++
+	if msg.err != nil {
+		m.VerifyIssues++
+		m.VerifyBroken++
+		m.AppendOutput(fmt.Sprintf("%s: %v", msg.name, msg.err))
+	} else {
+		m.VerifyFiles += msg.result.Files
+		if len(msg.result.Issues) > 0 {
+			m.VerifyIssues += len(msg.result.Issues)
+			m.VerifyBroken++
+		}
+		for _, line := range msg.result.Lines() {
+			m.AppendOutput(line)
+		}
+	}
+	m.OutputTitle = m.verifyTitle()
+
+	return m, msg.stream.next
+}
+
+func (m Model) handleVerifyComplete(msg verifyCompleteMsg) (tea.Model, tea.Cmd) {
+	if msg.stream != m.verifyStream {
+		return m, nil
+	}
+
+	msg.stream.cancel()
+	m.verifyStream = nil
+	m.Verifying = false
+	m.OutputTitle = m.verifyTitle()
+	return m, nil
+}
+
+// handleVerifyInput lets the output pane scroll while a verification runs;
+// Esc cancels it.
+func (m Model) handleVerifyInput(key string) (tea.Model, tea.Cmd) {
+	switch key {
+	case "esc":
+		m.CancelVerify()
+		return m, nil
+	case "enter":
+		return m, nil
+	default:
+		return m.handleOutputPaneInput(key)
+	}
+}
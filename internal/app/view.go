@@ -5,12 +5,19 @@ import (
 
 	"github.com/charmbracelet/lipgloss"
 	"github.com/sjsanc/pacviz/v3/internal/command"
+	"github.com/sjsanc/pacviz/v3/internal/domain"
 	"github.com/sjsanc/pacviz/v3/internal/ui/column"
 	"github.com/sjsanc/pacviz/v3/internal/ui/renderer"
 )
 
 // View renders the application (Bubble Tea interface).
+// View renders the model, prefixed with any pending clipboard sequence so
+// the renderer, which owns the terminal, writes it.
 func (m Model) View() string {
+	return m.Clipboard + m.view()
+}
+
+func (m Model) view() string {
 	if m.Error != "" {
 		return fmt.Sprintf("Error: %s\nPress Ctrl+C to quit", m.Error)
 	}
@@ -40,7 +47,7 @@ func (m Model) View() string {
 		commandPalette, paletteRows = command.RenderCommandPalette(m.GetBufferContent(), width, isRemoteMode)
 		statusBar = renderer.RenderStatusWithBuffer(m.Buffer, width)
 	case ModeFilter:
-		statusBar = renderer.RenderStatusWithBuffer(m.Buffer, width)
+		statusBar = renderer.RenderFilterStatus(m.Buffer, m.FilterError, width)
 	case ModePassword:
 		prompt := "[sudo] password: "
 		masked := ""
@@ -49,45 +56,158 @@ func (m Model) View() string {
 		}
 		statusBar = renderer.RenderWarningStatus(prompt+masked, width)
 	case ModeNormal:
-		if m.RemoveOutput != "" {
-			var rows int
-			outputPalette, rows = command.RenderOutputPalette(m.RemoveOutput, width)
-			paletteRows = rows
-		} else if m.InstallOutput != "" {
-			var rows int
-			outputPalette, rows = command.RenderOutputPalette(m.InstallOutput, width)
-			paletteRows = rows
+		if m.ShowOutput {
+			outputPalette, paletteRows = renderer.RenderOutputPane(
+				m.OutputTitle,
+				m.OutputLines,
+				m.OutputOffset,
+				m.outputPaneHeight(),
+				width,
+			)
+		} else if m.ShowTree {
+			outputPalette, paletteRows = renderer.RenderTreePane(
+				m.Tree,
+				m.TreeCursor,
+				m.TreeOffset,
+				m.listPaneHeight(),
+				width,
+			)
+		} else if m.ShowFiles {
+			outputPalette, paletteRows = renderer.RenderFilesPane(
+				m.FileList,
+				m.FilesCursor,
+				m.FilesOffset,
+				m.listPaneHeight(),
+				width,
+			)
+		} else if m.ShowCache {
+			outputPalette, paletteRows = renderer.RenderCachePane(
+				m.Cache,
+				m.CacheCursor,
+				m.CacheOffset,
+				m.listPaneHeight(),
+				width,
+			)
+		} else if m.ShowHistory {
+			outputPalette, paletteRows = renderer.RenderHistoryPane(
+				m.History,
+				m.HistoryCursor,
+				m.HistoryOffset,
+				m.listPaneHeight(),
+				width,
+			)
+		} else if m.ShowConfigs {
+			outputPalette, paletteRows = renderer.RenderConfigsPane(
+				m.ConfigFiles,
+				m.ConfigCursor,
+				m.ConfigOffset,
+				m.listPaneHeight(),
+				width,
+			)
+		} else if m.ShowManifest {
+			outputPalette, paletteRows = renderer.RenderManifestPane(
+				m.ManifestPath,
+				m.ManifestDiff,
+				m.ManifestLines,
+				m.ManifestCursor,
+				m.ManifestOffset,
+				m.listPaneHeight(),
+				width,
+			)
+		} else if m.ShowDiskUsage {
+			outputPalette, paletteRows = renderer.RenderDiskUsagePane(
+				m.DiskUsage,
+				m.UsageSection,
+				m.UsageCursor,
+				m.UsageOffset,
+				m.listPaneHeight(),
+				width,
+			)
 		}
 
 		filterText := ""
-		if m.Viewport.Filter.Active && len(m.Viewport.Filter.Terms) > 0 {
-			filterText = m.Viewport.Filter.Terms[0]
+		if m.Viewport.Filter.Active {
+			filterText = m.Viewport.Filter.Query
 		}
 
 		if m.PendingInstall {
-			installMsg := fmt.Sprintf("⚠ Press Enter to install %s or Esc to cancel", m.InstallingPkg)
-			if m.isSelectedPackageAUR() && m.AURHelper != nil {
-				installMsg = fmt.Sprintf("⚠ Press Enter to install %s via %s or Esc to cancel", m.InstallingPkg, m.AURHelper.Name)
+			targets := describeTargets(m.InstallingPkgs)
+			installMsg := fmt.Sprintf("⚠ Press Enter to install %s or Esc to cancel", targets)
+			if m.InstallViaAUR && m.AURHelper != nil {
+				installMsg = fmt.Sprintf("⚠ Press Enter to install %s via %s or Esc to cancel", targets, m.AURHelper.Name)
 			}
 			statusBar = renderer.RenderWarningStatus(installMsg, width)
 		} else if m.PendingRemoval {
+			removeMsg := fmt.Sprintf("⚠ Press Enter to remove %s or Esc to cancel", describeTargets(m.RemovingPkgs))
+			if plan := m.RemovalPlan; plan != nil {
+				removeMsg = fmt.Sprintf("⚠ Press Enter to remove %d packages (%s freed) or Esc to cancel",
+					len(plan.Removed), domain.FormatSize(plan.FreedSize))
+				if len(plan.Broken) > 0 {
+					removeMsg = fmt.Sprintf("⚠ Removal breaks %d dependencies. Press Enter to try anyway or Esc to cancel", len(plan.Broken))
+				}
+			}
+			statusBar = renderer.RenderWarningStatus(removeMsg, width)
+		} else if m.PendingUpgrade {
+			upgradeMsg := fmt.Sprintf("⚠ Press Enter to upgrade %d packages (%s download) or Esc to cancel",
+				len(m.Upgrades), domain.FormatSize(domain.TotalDownloadSize(m.Upgrades)))
+			if len(m.Upgrades) == 0 && m.AURHelper != nil {
+				upgradeMsg = fmt.Sprintf("⚠ Press Enter to upgrade AUR packages via %s or Esc to cancel", m.AURHelper.Name)
+			}
+			statusBar = renderer.RenderWarningStatus(upgradeMsg, width)
+		} else if m.PendingReason {
 			statusBar = renderer.RenderWarningStatus(
-				fmt.Sprintf("⚠ Press Enter to remove %s or Esc to cancel", m.RemovingPkg),
+				fmt.Sprintf("⚠ Press Enter to mark %s as %s or Esc to cancel", describeTargets(m.ReasonPkgs), describeReason(m.NewReason)),
 				width,
 			)
-		} else if m.Installing {
+		} else if m.PendingClean {
 			statusBar = renderer.RenderWarningStatus(
-				fmt.Sprintf("%s Installing %s...", m.GetSpinner(), m.InstallingPkg),
+				fmt.Sprintf("⚠ Press Enter to delete %d cached packages (%s) or Esc to cancel",
+					len(m.CleanPlan.Remove), domain.FormatSize(m.CleanPlan.Size)),
 				width,
 			)
-		} else if m.Removing {
+		} else if m.PendingConfig {
+			statusBar = renderer.RenderWarningStatus(
+				fmt.Sprintf("⚠ Press Enter to %s or Esc to cancel", describeConfigAction(m.ConfigTarget, m.ConfigReplace)),
+				width,
+			)
+		} else if m.Upgrading {
+			statusBar = renderer.RenderWarningStatus(
+				fmt.Sprintf("%s Upgrading system...", m.GetSpinner()),
+				width,
+			)
+		} else if m.SettingReason {
+			statusBar = renderer.RenderWarningStatus(
+				fmt.Sprintf("%s Marking %s as %s...", m.GetSpinner(), describeTargets(m.ReasonPkgs), describeReason(m.NewReason)),
+				width,
+			)
+		} else if m.Syncing {
+			statusBar = renderer.RenderWarningStatus(
+				fmt.Sprintf("%s Synchronizing package databases...", m.GetSpinner()),
+				width,
+			)
+		} else if m.Cleaning {
+			statusBar = renderer.RenderWarningStatus(
+				fmt.Sprintf("%s Cleaning package cache...", m.GetSpinner()),
+				width,
+			)
+		} else if m.ResolvingConfig {
+			statusBar = renderer.RenderWarningStatus(
+				fmt.Sprintf("%s Resolving %s...", m.GetSpinner(), m.ConfigTarget.New),
+				width,
+			)
+		} else if m.Verifying {
+			statusBar = renderer.RenderWarningStatus(
+				fmt.Sprintf("%s Verifying packages %d/%d... Press Esc to cancel", m.GetSpinner(), m.VerifyDone, m.VerifyTotal),
+				width,
+			)
+		} else if m.Installing {
 			statusBar = renderer.RenderWarningStatus(
-				fmt.Sprintf("%s Removing %s...", m.GetSpinner(), m.RemovingPkg),
+				fmt.Sprintf("%s Installing %s...", m.GetSpinner(), describeTargets(m.InstallingPkgs)),
 				width,
 			)
-		} else if m.InstallOutput != "" && m.InstallError == "" {
+		} else if m.Removing {
 			statusBar = renderer.RenderWarningStatus(
-				fmt.Sprintf("✓ Package installed successfully. Press Enter to dismiss."),
+				fmt.Sprintf("%s Removing %s...", m.GetSpinner(), describeTargets(m.RemovingPkgs)),
 				width,
 			)
 		} else if m.InstallError != "" {
@@ -100,19 +220,106 @@ func (m Model) View() string {
 				fmt.Sprintf("Error removing package: %s", m.RemoveError),
 				width,
 			)
+		} else if m.UpgradeError != "" {
+			statusBar = renderer.RenderWarningStatus(
+				fmt.Sprintf("Error upgrading system: %s", m.UpgradeError),
+				width,
+			)
+		} else if m.SyncError != "" {
+			statusBar = renderer.RenderWarningStatus(
+				fmt.Sprintf("Error synchronizing databases: %s", m.SyncError),
+				width,
+			)
+		} else if m.CleanError != "" {
+			statusBar = renderer.RenderWarningStatus(
+				fmt.Sprintf("Error cleaning package cache: %s", m.CleanError),
+				width,
+			)
+		} else if m.ConfigError != "" {
+			statusBar = renderer.RenderWarningStatus(
+				fmt.Sprintf("Error resolving configuration file: %s", m.ConfigError),
+				width,
+			)
+		} else if m.ConfigDiff != nil && m.ShowOutput {
+			diffMsg := fmt.Sprintf("r: replace %s with the %s · x: delete the %s · Esc: keep both", m.ConfigDiff.Path, m.ConfigDiff.Kind(), m.ConfigDiff.Kind())
+			if m.RemoteError != "" {
+				diffMsg = m.RemoteError
+			}
+			statusBar = renderer.RenderWarningStatus(diffMsg, width)
+		} else if m.ShowOutput {
+			statusBar = renderer.RenderWarningStatus(
+				fmt.Sprintf("✓ %s. Press Enter to dismiss, ↑/↓ to scroll the log.", m.OutputTitle),
+				width,
+			)
+		} else if m.ShowTree {
+			treeMsg := "Enter: jump to package · ←/→: collapse/expand · E: expand all · Tab: flip direction · +/-/0: depth · Esc: close"
+			if m.RemoteError != "" {
+				treeMsg = m.RemoteError
+			}
+			statusBar = renderer.RenderWarningStatus(treeMsg, width)
+		} else if m.ShowFiles {
+			filesMsg := "/: filter · ←/→: collapse/expand · y: copy path · Esc: close"
+			if m.FilesFiltering {
+				filesMsg = "Filter files: " + m.FileList.Filter
+			} else if m.RemoteError != "" {
+				filesMsg = m.RemoteError
+			}
+			statusBar = renderer.RenderWarningStatus(filesMsg, width)
+		} else if m.ShowCache {
+			cacheMsg := "Enter: install the selected version · /: filter · Esc: close"
+			if m.CacheFiltering {
+				cacheMsg = "Filter packages: " + m.Cache.Filter
+			} else if m.RemoteError != "" {
+				cacheMsg = m.RemoteError
+			}
+			statusBar = renderer.RenderWarningStatus(cacheMsg, width)
+		} else if m.ShowHistory {
+			historyMsg := "Enter: jump to package · /: filter by package or date (2024-01, 2024-01..2024-03) · Esc: close"
+			if m.HistoryFiltering {
+				historyMsg = "Filter history: " + m.History.Filter
+			} else if m.RemoteError != "" {
+				historyMsg = m.RemoteError
+			}
+			statusBar = renderer.RenderWarningStatus(historyMsg, width)
+		} else if m.ShowConfigs {
+			configsMsg := "Enter: show diff · r: replace with the new file · x: delete the new file · Esc: close"
+			if m.RemoteError != "" {
+				configsMsg = m.RemoteError
+			}
+			statusBar = renderer.RenderWarningStatus(configsMsg, width)
+		} else if m.ShowManifest {
+			manifestMsg := "Enter: install, remove or mark explicit · a: do so for the whole section · Esc: close"
+			if m.ManifestDiff.Empty() {
+				manifestMsg = "The installed packages match " + m.ManifestPath + " · Esc: close"
+			}
+			if m.RemoteError != "" {
+				manifestMsg = m.RemoteError
+			}
+			statusBar = renderer.RenderWarningStatus(manifestMsg, width)
+		} else if m.ShowDiskUsage {
+			usageMsg := "Tab: next section · Enter: jump to package or preset · r: preview removing with its dependencies · Esc: close"
+			if m.RemoteError != "" {
+				usageMsg = m.RemoteError
+			}
+			statusBar = renderer.RenderWarningStatus(usageMsg, width)
 		} else if isRemoteMode {
 			errorMsg := m.RemoteError
+			query := m.RemoteQuery
+			if m.RemoteFiles {
+				query += " (files)"
+			}
 			statusBar = renderer.RenderRemoteStatus(
-				m.RemoteQuery,
+				query,
 				len(m.Viewport.VisibleRows),
 				m.Viewport.Height,
 				m.Viewport.Offset,
 				filterText,
+				m.Viewport.MarkedCount(),
 				m.RemoteLoading,
 				m.GetSpinner(),
 				errorMsg,
 				m.Installing,
-				m.InstallingPkg,
+				describeTargets(m.InstallingPkgs),
 				width,
 			)
 		} else {
@@ -123,6 +330,8 @@ func (m Model) View() string {
 				m.Viewport.Height,
 				m.Viewport.Offset,
 				filterText,
+				m.Viewport.MarkedCount(),
+				m.RemoteError,
 				width,
 			)
 		}
@@ -130,6 +339,10 @@ func (m Model) View() string {
 
 	if m.ShowDetailPanel {
 		selectedPackage := m.Viewport.GetSelectedPackage()
+		whyLines := m.WhyLines
+		if selectedPackage != m.WhyPackage {
+			whyLines = m.whySummary(selectedPackage)
+		}
 		tableUI = renderer.RenderWithDetailPanel(
 			width,
 			m.Height,
@@ -143,6 +356,8 @@ func (m Model) View() string {
 			selectedPackage,
 			m.Viewport.Offset,
 			isRemoteMode,
+			whyLines,
+			m.historySummary(selectedPackage),
 		)
 		if statusBar != "" {
 			return lipgloss.JoinVertical(lipgloss.Left, tableUI, statusBar)
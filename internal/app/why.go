@@ -0,0 +1,78 @@
+package app
+
+import (
+	"fmt"
+
+	"github.com/sjsanc/pacviz/v3/internal/domain"
+)
+
+// maxWhyChains is how many chains the detail panel shows before deferring to :why.
+const maxWhyChains = 3
+
+// updateWhySummary recomputes WhyLines when the detail panel shows a
+// different package, so that View does not walk the graph on every frame.
+// Reloads replace every package, so they are caught too.
+func (m *Model) updateWhySummary() {
+	if !m.ShowDetailPanel {
+		return
+	}
+	pkg := m.Viewport.GetSelectedPackage()
+	if pkg == m.WhyPackage {
+		return
+	}
+	m.WhyPackage = pkg
+	m.WhyLines = m.whySummary(pkg)
+}
+
+// whySummary returns the detail panel's explanation of why an installed
+// dependency is present. Explicit and uninstalled packages get none.
+func (m Model) whySummary(pkg *domain.Package) []string {
+	if pkg == nil || !pkg.Installed || pkg.InstallReason != domain.ReasonDependency {
+		return nil
+	}
+
+	chains := domain.WhyInstalled(m.installedPackages(), pkg.Name)
+	if len(chains) == 0 {
+		return []string{"Not required by any explicitly installed package"}
+	}
+
+	lines := make([]string, 0, maxWhyChains+1)
+	for _, chain := range chains[:min(len(chains), maxWhyChains)] {
+		lines = append(lines, domain.FormatChain(chain))
+	}
+	if len(chains) > maxWhyChains {
+		lines = append(lines, fmt.Sprintf("… and %d more (:why)", len(chains)-maxWhyChains))
+	}
+	return lines
+}
+
+// ShowWhy lists every dependency chain that keeps the named package installed.
+func (m *Model) ShowWhy(name string) {
+	var pkg *domain.Package
+	for _, installed := range m.installedPackages() {
+		if installed.Name == name {
+			pkg = installed
+			break
+		}
+	}
+	if pkg == nil {
+		m.RemoteError = fmt.Sprintf("%s is not installed", name)
+		return
+	}
+
+	chains := domain.WhyInstalled(m.installedPackages(), name)
+
+	m.StartOutput(fmt.Sprintf("Why is %s installed?", name))
+	m.OutputFollow = false
+
+	if pkg.InstallReason == domain.ReasonExplicit {
+		m.AppendOutput(fmt.Sprintf("%s was explicitly installed", name))
+	}
+	if len(chains) == 0 {
+		m.AppendOutput("Not required by any explicitly installed package")
+		return
+	}
+	for _, chain := range chains {
+		m.AppendOutput(domain.FormatChain(chain))
+	}
+}
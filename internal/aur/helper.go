@@ -41,3 +41,9 @@ func InstallCmd(helper *HelperConfig, names []string) *exec.Cmd {
 	args := append([]string{"-S"}, names...)
 	return exec.Command(helper.Path, args...)
 }
+
+// UpgradeCmd builds an exec.Cmd that upgrades installed AUR packages via the helper.
+// Like InstallCmd, it runs interactively.
+func UpgradeCmd(helper *HelperConfig) *exec.Cmd {
+	return exec.Command(helper.Path, "-Sua")
+}
@@ -7,16 +7,49 @@ import (
 
 // ExecuteResult represents the result of executing a command.
 type ExecuteResult struct {
-	Quit           bool
-	GoToLine       int
-	ScrollTop      bool
-	ScrollEnd      bool
-	Error          string
-	PresetChange   string
-	RemoteSearch   string
-	InstallPackage bool
-	RemovePackage  bool
-	ThemeName      string
+	Quit             bool
+	GoToLine         int
+	ScrollTop        bool
+	ScrollEnd        bool
+	Error            string
+	PresetChange     string
+	RemoteSearch     string
+	InstallPackage   bool
+	RemovePackage    bool
+	RemoveRecursive  bool
+	RemoveCascade    bool
+	RemoveNoSave     bool
+	ThemeName        string
+	Upgrade          bool
+	UpgradeAUR       bool
+	SyncDatabases    bool
+	SyncFiles        bool
+	FileSearch       string
+	MarkAsDeps       bool
+	MarkAsExplicit   bool
+	AutoRemove       bool
+	ShowTree         bool
+	TreeReverse      bool
+	Why              bool
+	WhyPackage       string
+	ShowFiles        bool
+	CopyPath         bool
+	ShowCache        bool
+	CacheFilter      string
+	CleanCache       bool
+	CleanKeep        int
+	CleanUninstalled bool
+	CleanOlderDays   int
+	ShowHistory      bool
+	ShowConfigs      bool
+	ShowDiskUsage    bool
+	ImportPath       string
+	ExportPath       string
+	ExportFormat     string // "csv", "json" or "md"; empty to follow the file extension
+	HistoryFilter    string
+	Verify           bool
+	VerifyScope      string // "" for the marked or selected packages, "all" or "visible"
+	OwnsPaths        []string
 }
 
 // Execute parses and executes a command string.
@@ -57,7 +90,45 @@ func Execute(commandStr string) ExecuteResult {
 	case "i", "install":
 		return ExecuteResult{InstallPackage: true, GoToLine: -1}
 	case "r", "remove":
-		return ExecuteResult{RemovePackage: true, GoToLine: -1}
+		return executeRemove(args)
+	case "u", "upgrade":
+		return executeUpgrade(args)
+	case "autoremove":
+		return ExecuteResult{AutoRemove: true, GoToLine: -1}
+	case "asdeps":
+		return ExecuteResult{MarkAsDeps: true, GoToLine: -1}
+	case "asexplicit":
+		return ExecuteResult{MarkAsExplicit: true, GoToLine: -1}
+	case "F", "filesearch":
+		return executeFileSearch(args)
+	case "sync":
+		return executeSync(args)
+	case "tree":
+		return executeTree(args)
+	case "why":
+		return executeWhy(args)
+	case "files":
+		return ExecuteResult{ShowFiles: true, GoToLine: -1}
+	case "copy":
+		return ExecuteResult{CopyPath: true, GoToLine: -1}
+	case "cache":
+		return executeCache(args)
+	case "cleancache":
+		return executeCleanCache(args)
+	case "pacnew":
+		return ExecuteResult{ShowConfigs: true, GoToLine: -1}
+	case "export":
+		return executeExport(args)
+	case "import":
+		return executeImport(args)
+	case "du", "diskusage":
+		return ExecuteResult{ShowDiskUsage: true, GoToLine: -1}
+	case "history":
+		return ExecuteResult{ShowHistory: true, HistoryFilter: strings.Join(args, " "), GoToLine: -1}
+	case "verify":
+		return executeVerify(args)
+	case "owns":
+		return executeOwns(args)
 	case "theme", "th":
 		return executeTheme(args)
 	default:
@@ -94,7 +165,7 @@ func executePreset(args []string) ExecuteResult {
 	if len(args) == 0 {
 		return ExecuteResult{
 			GoToLine: -1,
-			Error:    "Usage: :p <preset> (explicit, dependency, orphans, foreign, aur, updatable, all)",
+			Error:    "Usage: :p <preset> (explicit, dependency, orphans, unneeded, broken, pacnew, foreign, aur, updatable, all)",
 		}
 	}
 
@@ -103,6 +174,9 @@ func executePreset(args []string) ExecuteResult {
 		"explicit":   true,
 		"dependency": true,
 		"orphans":    true,
+		"unneeded":   true,
+		"broken":     true,
+		"pacnew":     true,
 		"foreign":    true,
 		"aur":        true,
 		"updatable":  true,
@@ -112,7 +186,7 @@ func executePreset(args []string) ExecuteResult {
 	if !validPresets[preset] {
 		return ExecuteResult{
 			GoToLine: -1,
-			Error:    "Invalid preset: " + preset + " (valid: explicit, dependency, orphans, foreign, aur, updatable, all)",
+			Error:    "Invalid preset: " + preset + " (valid: explicit, dependency, orphans, unneeded, broken, pacnew, foreign, aur, updatable, all)",
 		}
 	}
 
@@ -153,3 +227,235 @@ func executeTheme(args []string) ExecuteResult {
 		ThemeName: themeName,
 	}
 }
+
+// executeRemove parses pacman-style -R modifiers: -s, -c and -n, which may
+// be combined ("-sc") or given as --recursive, --cascade and --nosave.
+func executeRemove(args []string) ExecuteResult {
+	result := ExecuteResult{RemovePackage: true, GoToLine: -1}
+
+	for _, arg := range args {
+		switch arg {
+		case "--recursive":
+			result.RemoveRecursive = true
+			continue
+		case "--cascade":
+			result.RemoveCascade = true
+			continue
+		case "--nosave":
+			result.RemoveNoSave = true
+			continue
+		}
+
+		if !strings.HasPrefix(arg, "-") || strings.HasPrefix(arg, "--") || len(arg) < 2 {
+			return ExecuteResult{GoToLine: -1, Error: "Usage: :remove [-s] [-c] [-n]"}
+		}
+		for _, flag := range arg[1:] {
+			switch flag {
+			case 's':
+				result.RemoveRecursive = true
+			case 'c':
+				result.RemoveCascade = true
+			case 'n':
+				result.RemoveNoSave = true
+			default:
+				return ExecuteResult{GoToLine: -1, Error: "Usage: :remove [-s] [-c] [-n]"}
+			}
+		}
+	}
+
+	return result
+}
+
+func executeTree(args []string) ExecuteResult {
+	if len(args) == 0 {
+		return ExecuteResult{GoToLine: -1, ShowTree: true}
+	}
+
+	if len(args) > 1 || (args[0] != "-r" && args[0] != "--reverse") {
+		return ExecuteResult{
+			GoToLine: -1,
+			Error:    "Usage: :tree [-r]",
+		}
+	}
+
+	return ExecuteResult{
+		GoToLine:    -1,
+		ShowTree:    true,
+		TreeReverse: true,
+	}
+}
+
+func executeFileSearch(args []string) ExecuteResult {
+	if len(args) != 1 {
+		return ExecuteResult{
+			GoToLine: -1,
+			Error:    "Usage: :filesearch <file|path|glob>",
+		}
+	}
+
+	return ExecuteResult{
+		GoToLine:   -1,
+		FileSearch: args[0],
+	}
+}
+
+func executeSync(args []string) ExecuteResult {
+	switch {
+	case len(args) == 0:
+		return ExecuteResult{SyncDatabases: true, GoToLine: -1}
+	case len(args) == 1 && args[0] == "files":
+		return ExecuteResult{SyncDatabases: true, SyncFiles: true, GoToLine: -1}
+	default:
+		return ExecuteResult{
+			GoToLine: -1,
+			Error:    "Usage: :sync [files]",
+		}
+	}
+}
+
+func executeCache(args []string) ExecuteResult {
+	if len(args) > 1 {
+		return ExecuteResult{
+			GoToLine: -1,
+			Error:    "Usage: :cache [package]",
+		}
+	}
+
+	result := ExecuteResult{ShowCache: true, GoToLine: -1}
+	if len(args) == 1 {
+		result.CacheFilter = args[0]
+	}
+	return result
+}
+
+// executeCleanCache parses "-k N", "-u" and "-d DAYS". Without any of them
+// the three newest versions of each package are kept, as with paccache;
+// otherwise versions are only limited when -k is given.
+func executeCleanCache(args []string) ExecuteResult {
+	usage := ExecuteResult{GoToLine: -1, Error: "Usage: :cleancache [-k <versions>] [-u] [-d <days>]"}
+	result := ExecuteResult{CleanCache: true, GoToLine: -1}
+	if len(args) == 0 {
+		result.CleanKeep = 3
+	}
+
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "-u", "--uninstalled":
+			result.CleanUninstalled = true
+		case "-k", "--keep", "-d", "--days":
+			if i+1 >= len(args) {
+				return usage
+			}
+			n, err := strconv.Atoi(args[i+1])
+			if err != nil || n < 0 {
+				return usage
+			}
+			if args[i] == "-k" || args[i] == "--keep" {
+				result.CleanKeep = n
+			} else {
+				result.CleanOlderDays = n
+			}
+			i++
+		default:
+			return usage
+		}
+	}
+
+	return result
+}
+
+func executeExport(args []string) ExecuteResult {
+	usage := ExecuteResult{GoToLine: -1, Error: "Usage: :export <file> [csv|json|md]"}
+	if len(args) == 0 || len(args) > 2 {
+		return usage
+	}
+
+	result := ExecuteResult{GoToLine: -1, ExportPath: args[0]}
+	if len(args) == 2 {
+		switch format := strings.ToLower(args[1]); format {
+		case "csv", "json", "md":
+			result.ExportFormat = format
+		case "markdown":
+			result.ExportFormat = "md"
+		default:
+			return usage
+		}
+	}
+	return result
+}
+
+func executeImport(args []string) ExecuteResult {
+	if len(args) != 1 {
+		return ExecuteResult{
+			GoToLine: -1,
+			Error:    "Usage: :import <manifest>",
+		}
+	}
+
+	return ExecuteResult{
+		GoToLine:   -1,
+		ImportPath: args[0],
+	}
+}
+
+func executeVerify(args []string) ExecuteResult {
+	switch {
+	case len(args) == 0:
+		return ExecuteResult{Verify: true, GoToLine: -1}
+	case len(args) == 1 && (args[0] == "all" || args[0] == "visible"):
+		return ExecuteResult{Verify: true, VerifyScope: args[0], GoToLine: -1}
+	default:
+		return ExecuteResult{
+			GoToLine: -1,
+			Error:    "Usage: :verify [all|visible]",
+		}
+	}
+}
+
+func executeOwns(args []string) ExecuteResult {
+	if len(args) == 0 {
+		return ExecuteResult{
+			GoToLine: -1,
+			Error:    "Usage: :owns <path|command|glob>...",
+		}
+	}
+
+	return ExecuteResult{
+		GoToLine:  -1,
+		OwnsPaths: args,
+	}
+}
+
+func executeWhy(args []string) ExecuteResult {
+	if len(args) > 1 {
+		return ExecuteResult{
+			GoToLine: -1,
+			Error:    "Usage: :why [package]",
+		}
+	}
+
+	result := ExecuteResult{GoToLine: -1, Why: true}
+	if len(args) == 1 {
+		result.WhyPackage = args[0]
+	}
+	return result
+}
+
+func executeUpgrade(args []string) ExecuteResult {
+	if len(args) == 0 {
+		return ExecuteResult{GoToLine: -1, Upgrade: true}
+	}
+
+	if len(args) > 1 || args[0] != "aur" {
+		return ExecuteResult{
+			GoToLine: -1,
+			Error:    "Usage: :upgrade [aur]",
+		}
+	}
+
+	return ExecuteResult{
+		GoToLine:   -1,
+		Upgrade:    true,
+		UpgradeAUR: true,
+	}
+}
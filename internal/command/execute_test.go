@@ -1,6 +1,9 @@
 package command
 
-import "testing"
+import (
+	"reflect"
+	"testing"
+)
 
 func TestExecute_GoTo(t *testing.T) {
 	tests := []struct {
@@ -109,13 +112,13 @@ func TestExecute_Preset(t *testing.T) {
 			name:           "preset without args",
 			commandStr:     "p",
 			expectedPreset: "",
-			expectedError:  "Usage: :p <preset> (explicit, dependency, orphans, foreign, aur, updatable, all)",
+			expectedError:  "Usage: :p <preset> (explicit, dependency, orphans, unneeded, broken, pacnew, foreign, aur, updatable, all)",
 		},
 		{
 			name:           "preset with invalid name",
 			commandStr:     "p invalid",
 			expectedPreset: "",
-			expectedError:  "Invalid preset: invalid (valid: explicit, dependency, orphans, foreign, aur, updatable, all)",
+			expectedError:  "Invalid preset: invalid (valid: explicit, dependency, orphans, unneeded, broken, pacnew, foreign, aur, updatable, all)",
 		},
 	}
 
@@ -133,3 +136,236 @@ func TestExecute_Preset(t *testing.T) {
 		})
 	}
 }
+
+func TestExecute_Upgrade(t *testing.T) {
+	tests := []struct {
+		name          string
+		commandStr    string
+		expectedAUR   bool
+		expectedError string
+	}{
+		{
+			name:       "upgrade with u alias",
+			commandStr: "u",
+		},
+		{
+			name:        "upgrade with aur",
+			commandStr:  "upgrade aur",
+			expectedAUR: true,
+		},
+		{
+			name:          "upgrade with invalid arg",
+			commandStr:    "upgrade all",
+			expectedError: "Usage: :upgrade [aur]",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := Execute(tt.commandStr)
+
+			if result.Upgrade != (tt.expectedError == "") {
+				t.Errorf("Upgrade = %v, want %v", result.Upgrade, tt.expectedError == "")
+			}
+
+			if result.UpgradeAUR != tt.expectedAUR {
+				t.Errorf("UpgradeAUR = %v, want %v", result.UpgradeAUR, tt.expectedAUR)
+			}
+
+			if result.Error != tt.expectedError {
+				t.Errorf("Error = %q, want %q", result.Error, tt.expectedError)
+			}
+		})
+	}
+}
+
+func TestExecute_Remove(t *testing.T) {
+	tests := []struct {
+		name       string
+		commandStr string
+		want       ExecuteResult
+	}{
+		{
+			name:       "plain remove",
+			commandStr: "r",
+			want:       ExecuteResult{RemovePackage: true, GoToLine: -1},
+		},
+		{
+			name:       "combined flags",
+			commandStr: "remove -sc",
+			want:       ExecuteResult{RemovePackage: true, RemoveRecursive: true, RemoveCascade: true, GoToLine: -1},
+		},
+		{
+			name:       "separate and long flags",
+			commandStr: "remove -n --cascade",
+			want:       ExecuteResult{RemovePackage: true, RemoveCascade: true, RemoveNoSave: true, GoToLine: -1},
+		},
+		{
+			name:       "unknown flag",
+			commandStr: "remove -x",
+			want:       ExecuteResult{GoToLine: -1, Error: "Usage: :remove [-s] [-c] [-n]"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Execute(tt.commandStr); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Execute(%q) = %+v, want %+v", tt.commandStr, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExecute_Owns(t *testing.T) {
+	result := Execute("owns /usr/bin/bash ls /usr/lib/libz*")
+	if want := []string{"/usr/bin/bash", "ls", "/usr/lib/libz*"}; !reflect.DeepEqual(result.OwnsPaths, want) {
+		t.Errorf("OwnsPaths = %q, want %q", result.OwnsPaths, want)
+	}
+
+	result = Execute("owns")
+	if result.Error != "Usage: :owns <path|command|glob>..." || result.OwnsPaths != nil {
+		t.Errorf("expected a usage error, got %+v", result)
+	}
+}
+
+func TestExecute_FileSearch(t *testing.T) {
+	tests := []struct {
+		commandStr string
+		want       ExecuteResult
+	}{
+		{"F rg", ExecuteResult{FileSearch: "rg", GoToLine: -1}},
+		{"filesearch /usr/lib/libfoo.so*", ExecuteResult{FileSearch: "/usr/lib/libfoo.so*", GoToLine: -1}},
+		{"F", ExecuteResult{GoToLine: -1, Error: "Usage: :filesearch <file|path|glob>"}},
+		{"sync", ExecuteResult{SyncDatabases: true, GoToLine: -1}},
+		{"sync files", ExecuteResult{SyncDatabases: true, SyncFiles: true, GoToLine: -1}},
+		{"sync all", ExecuteResult{GoToLine: -1, Error: "Usage: :sync [files]"}},
+	}
+
+	for _, tt := range tests {
+		if got := Execute(tt.commandStr); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Execute(%q) = %+v, want %+v", tt.commandStr, got, tt.want)
+		}
+	}
+}
+
+func TestExecute_Cache(t *testing.T) {
+	if got := Execute("cache"); !got.ShowCache || got.CacheFilter != "" {
+		t.Errorf("Execute(cache) = %+v", got)
+	}
+	if got := Execute("cache mesa"); !got.ShowCache || got.CacheFilter != "mesa" {
+		t.Errorf("Execute(cache mesa) = %+v", got)
+	}
+	if got := Execute("cache a b"); got.Error != "Usage: :cache [package]" {
+		t.Errorf("expected a usage error, got %+v", got)
+	}
+}
+
+func TestExecute_CleanCache(t *testing.T) {
+	usage := "Usage: :cleancache [-k <versions>] [-u] [-d <days>]"
+	tests := []struct {
+		commandStr string
+		want       ExecuteResult
+	}{
+		{"cleancache", ExecuteResult{CleanCache: true, CleanKeep: 3, GoToLine: -1}},
+		{"cleancache -k 1 -u -d 90", ExecuteResult{CleanCache: true, CleanKeep: 1, CleanUninstalled: true, CleanOlderDays: 90, GoToLine: -1}},
+		{"cleancache -k 0 -u", ExecuteResult{CleanCache: true, CleanUninstalled: true, GoToLine: -1}},
+		{"cleancache -u", ExecuteResult{CleanCache: true, CleanUninstalled: true, GoToLine: -1}},
+		{"cleancache -d 90", ExecuteResult{CleanCache: true, CleanOlderDays: 90, GoToLine: -1}},
+		{"cleancache -k", ExecuteResult{GoToLine: -1, Error: usage}},
+		{"cleancache -d soon", ExecuteResult{GoToLine: -1, Error: usage}},
+		{"cleancache -x", ExecuteResult{GoToLine: -1, Error: usage}},
+	}
+
+	for _, tt := range tests {
+		if got := Execute(tt.commandStr); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Execute(%q) = %+v, want %+v", tt.commandStr, got, tt.want)
+		}
+	}
+}
+
+func TestExecute_History(t *testing.T) {
+	if got := Execute("history"); !got.ShowHistory || got.HistoryFilter != "" {
+		t.Errorf("Execute(history) = %+v", got)
+	}
+	if got := Execute("history mesa 2024-01..2024-03"); !got.ShowHistory || got.HistoryFilter != "mesa 2024-01..2024-03" {
+		t.Errorf("Execute(history mesa 2024-01..2024-03) = %+v", got)
+	}
+}
+
+func TestExecute_Export(t *testing.T) {
+	tests := []struct {
+		input      string
+		wantPath   string
+		wantFormat string
+		wantErr    bool
+	}{
+		{input: "export audit.csv", wantPath: "audit.csv"},
+		{input: "export audit.txt json", wantPath: "audit.txt", wantFormat: "json"},
+		{input: "export audit Markdown", wantPath: "audit", wantFormat: "md"},
+		{input: "export", wantErr: true},
+		{input: "export audit.txt xml", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got := Execute(tt.input)
+		if tt.wantErr {
+			if got.ExportPath != "" || got.Error != "Usage: :export <file> [csv|json|md]" {
+				t.Errorf("Execute(%q) = %+v, want a usage error", tt.input, got)
+			}
+			continue
+		}
+		if got.ExportPath != tt.wantPath || got.ExportFormat != tt.wantFormat || got.Error != "" {
+			t.Errorf("Execute(%q) = %+v, want %q as %q", tt.input, got, tt.wantPath, tt.wantFormat)
+		}
+	}
+}
+
+func TestExecute_Import(t *testing.T) {
+	if got := Execute("import laptop.toml"); got.ImportPath != "laptop.toml" || got.Error != "" {
+		t.Errorf("Execute(import laptop.toml) = %+v", got)
+	}
+	if got := Execute("import"); got.ImportPath != "" || got.Error != "Usage: :import <manifest>" {
+		t.Errorf("Execute(import) = %+v, want a usage error", got)
+	}
+}
+
+func TestExecute_DiskUsage(t *testing.T) {
+	for _, input := range []string{"du", "diskusage"} {
+		if got := Execute(input); !got.ShowDiskUsage || got.Error != "" {
+			t.Errorf("Execute(%s) = %+v", input, got)
+		}
+	}
+}
+
+func TestExecute_Verify(t *testing.T) {
+	tests := []struct {
+		input string
+		scope string
+		err   bool
+	}{
+		{"verify", "", false},
+		{"verify all", "all", false},
+		{"verify visible", "visible", false},
+		{"verify bash", "", true},
+		{"verify all visible", "", true},
+	}
+
+	for _, tt := range tests {
+		got := Execute(tt.input)
+		if tt.err {
+			if got.Verify || got.Error != "Usage: :verify [all|visible]" {
+				t.Errorf("Execute(%q) = %+v, want a usage error", tt.input, got)
+			}
+			continue
+		}
+		if !got.Verify || got.VerifyScope != tt.scope || got.Error != "" {
+			t.Errorf("Execute(%q) = %+v, want scope %q", tt.input, got, tt.scope)
+		}
+	}
+}
+
+func TestExecute_Pacnew(t *testing.T) {
+	if got := Execute("pacnew"); !got.ShowConfigs || got.Error != "" {
+		t.Errorf("Execute(pacnew) = %+v", got)
+	}
+}
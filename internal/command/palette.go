@@ -79,14 +79,109 @@ func GetAllCommands(isRemoteMode bool) []CommandDef {
 			Name:        "i",
 			Aliases:     []string{"install"},
 			Args:        "",
-			Description: "Install selected package",
+			Description: "Install marked packages (or the selected one)",
 		})
 	} else {
 		baseCommands = append(baseCommands, CommandDef{
 			Name:        "r",
 			Aliases:     []string{"remove"},
+			Args:        "[-s] [-c] [-n]",
+			Description: "Remove marked packages (or the selected one)",
+		}, CommandDef{
+			Name:        "u",
+			Aliases:     []string{"upgrade"},
+			Args:        "[aur]",
+			Description: "Upgrade all packages (aur: also AUR packages)",
+		}, CommandDef{
+			Name:        "autoremove",
+			Aliases:     []string{},
+			Args:        "",
+			Description: "Remove all unneeded dependencies",
+		}, CommandDef{
+			Name:        "asdeps",
+			Aliases:     []string{},
+			Args:        "",
+			Description: "Mark marked (or selected) packages as dependencies",
+		}, CommandDef{
+			Name:        "asexplicit",
+			Aliases:     []string{},
+			Args:        "",
+			Description: "Mark marked (or selected) packages as explicitly installed",
+		}, CommandDef{
+			Name:        "tree",
+			Aliases:     []string{},
+			Args:        "[-r]",
+			Description: "Show dependency tree of selected package (-r: required by)",
+		}, CommandDef{
+			Name:        "why",
+			Aliases:     []string{},
+			Args:        "[package]",
+			Description: "Explain which explicit packages pull in a package",
+		}, CommandDef{
+			Name:        "files",
+			Aliases:     []string{},
+			Args:        "",
+			Description: "List files owned by the selected package",
+		}, CommandDef{
+			Name:        "copy",
+			Aliases:     []string{},
 			Args:        "",
-			Description: "Remove selected package",
+			Description: "Copy the selected file path to the clipboard",
+		}, CommandDef{
+			Name:        "owns",
+			Aliases:     []string{},
+			Args:        "<path|command|glob>...",
+			Description: "Select the package owning a file (like pacman -Qo)",
+		}, CommandDef{
+			Name:        "sync",
+			Aliases:     []string{},
+			Args:        "[files]",
+			Description: "Refresh sync databases for update checks (files: file lists too, no root)",
+		}, CommandDef{
+			Name:        "filesearch",
+			Aliases:     []string{"F"},
+			Args:        "<file|path|glob>",
+			Description: "Search the sync file databases for a file (like pacman -F)",
+		}, CommandDef{
+			Name:        "cache",
+			Aliases:     []string{},
+			Args:        "[package]",
+			Description: "Browse the package cache and install an older version",
+		}, CommandDef{
+			Name:        "cleancache",
+			Aliases:     []string{},
+			Args:        "[-k <n>] [-u] [-d <days>]",
+			Description: "Delete old cached packages (like paccache)",
+		}, CommandDef{
+			Name:        "history",
+			Aliases:     []string{},
+			Args:        "[filter]",
+			Description: "Browse package history from pacman.log",
+		}, CommandDef{
+			Name:        "pacnew",
+			Aliases:     []string{},
+			Args:        "",
+			Description: "List .pacnew and .pacsave files to diff, replace or delete",
+		}, CommandDef{
+			Name:        "verify",
+			Aliases:     []string{},
+			Args:        "[all|visible]",
+			Description: "Check marked (or selected) packages' files (like pacman -Qkk)",
+		}, CommandDef{
+			Name:        "du",
+			Aliases:     []string{"diskusage"},
+			Args:        "",
+			Description: "Show disk usage by package",
+		}, CommandDef{
+			Name:        "import",
+			Aliases:     []string{},
+			Args:        "<manifest>",
+			Description: "Compare installed packages with a manifest",
+		}, CommandDef{
+			Name:        "export",
+			Aliases:     []string{},
+			Args:        "<file> [csv|json|md]",
+			Description: "Write the visible table to a file",
 		})
 	}
 
@@ -166,38 +261,3 @@ func RenderCommandPalette(buffer string, width int, isRemoteMode bool) (string,
 
 	return lipgloss.JoinVertical(lipgloss.Left, lines...), len(lines)
 }
-
-// RenderOutputPalette renders the output palette showing command output.
-func RenderOutputPalette(output string, width int) (string, int) {
-	if output == "" {
-		return "", 0
-	}
-
-	outputLines := strings.Split(output, "\n")
-
-	// Limit to first 10 lines
-	maxDisplay := 10
-	if len(outputLines) > maxDisplay {
-		outputLines = outputLines[:maxDisplay]
-	}
-
-	rowStyle := lipgloss.NewStyle().
-		Foreground(styles.Current.Foreground).
-		Background(styles.Current.Selected).
-		Width(width)
-
-	var lines []string
-	for _, line := range outputLines {
-		if line == "" {
-			continue
-		}
-		content := "  " + line
-		lines = append(lines, rowStyle.Render(content))
-	}
-
-	if len(lines) == 0 {
-		return "", 0
-	}
-
-	return lipgloss.JoinVertical(lipgloss.Left, lines...), len(lines)
-}
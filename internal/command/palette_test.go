@@ -0,0 +1,17 @@
+package command
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFilterCommands_FileSearch(t *testing.T) {
+	filtered := FilterCommands("files", GetAllCommands(false))
+	var names []string
+	for _, def := range filtered {
+		names = append(names, def.Name)
+	}
+	if strings.Join(names, ",") != "files,filesearch" {
+		t.Errorf("FilterCommands(files) = %q", names)
+	}
+}
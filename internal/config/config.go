@@ -54,8 +54,16 @@ type KeybindingsConfig struct {
 }
 
 // PacmanConfig contains pacman-specific settings.
+// Empty RootDir and DBPath use the values from pacman.conf.
 type PacmanConfig struct {
-	DBPath string
+	ConfigPath string // pacman.conf to read (default /etc/pacman.conf)
+	RootDir    string
+	DBPath     string
+	Fixture    string // Load packages from a fixture instead of the system (demo mode)
+	Mirror     string // Mirror URL for :sync, may contain $repo and $arch (default: servers from pacman.conf)
+	SyncDBPath string // Private dbpath for :sync (default: $XDG_CACHE_HOME/pacviz/db)
+	CacheDir   string // Package cache for :cache (default: CacheDir from pacman.conf)
+	LogFile    string // pacman log for :history (default: LogFile from pacman.conf)
 }
 
 // DefaultConfig returns the default configuration.
@@ -106,7 +114,7 @@ func DefaultConfig() *Config {
 			Help:    []string{"?"},
 		},
 		Pacman: PacmanConfig{
-			DBPath: "/var/lib/pacman",
+			ConfigPath: "/etc/pacman.conf",
 		},
 		AUR: AURConfig{
 			Timeout:  5,
@@ -32,6 +32,16 @@ func Load(path string, optional bool) (*Config, error) {
 			Timeout  int    `toml:"timeout"`
 			CacheTTL int    `toml:"cache_ttl"`
 		} `toml:"aur"`
+		Pacman struct {
+			Config     string `toml:"config"`
+			Root       string `toml:"root"`
+			DBPath     string `toml:"dbpath"`
+			Fixture    string `toml:"fixture"`
+			Mirror     string `toml:"mirror"`
+			SyncDBPath string `toml:"sync_dbpath"`
+			CacheDir   string `toml:"cachedir"`
+			LogFile    string `toml:"logfile"`
+		} `toml:"pacman"`
 		Theme struct {
 			Overrides struct {
 				Accent1       string `toml:"accent1"`
@@ -82,6 +92,31 @@ func Load(path string, optional bool) (*Config, error) {
 		config.AUR.CacheTTL = tomlConfig.AUR.CacheTTL
 	}
 
+	if tomlConfig.Pacman.Config != "" {
+		config.Pacman.ConfigPath = tomlConfig.Pacman.Config
+	}
+	if tomlConfig.Pacman.Root != "" {
+		config.Pacman.RootDir = tomlConfig.Pacman.Root
+	}
+	if tomlConfig.Pacman.DBPath != "" {
+		config.Pacman.DBPath = tomlConfig.Pacman.DBPath
+	}
+	if tomlConfig.Pacman.Fixture != "" {
+		config.Pacman.Fixture = tomlConfig.Pacman.Fixture
+	}
+	if tomlConfig.Pacman.Mirror != "" {
+		config.Pacman.Mirror = tomlConfig.Pacman.Mirror
+	}
+	if tomlConfig.Pacman.SyncDBPath != "" {
+		config.Pacman.SyncDBPath = tomlConfig.Pacman.SyncDBPath
+	}
+	if tomlConfig.Pacman.CacheDir != "" {
+		config.Pacman.CacheDir = tomlConfig.Pacman.CacheDir
+	}
+	if tomlConfig.Pacman.LogFile != "" {
+		config.Pacman.LogFile = tomlConfig.Pacman.LogFile
+	}
+
 	themeName := tomlConfig.SelectedTheme
 	if themeName == "" {
 		themeName = "default"
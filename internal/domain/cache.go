@@ -0,0 +1,205 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// CachedPackage is a package archive in the pacman cache directory.
+type CachedPackage struct {
+	Name             string
+	Version          string
+	Architecture     string
+	Path             string
+	Size             int64
+	ModTime          time.Time
+	InstalledVersion string // version of the installed package, empty if not installed
+}
+
+// Installed reports whether this archive is the installed version.
+func (c CachedPackage) Installed() bool {
+	return c.InstalledVersion != "" && c.Version == c.InstalledVersion
+}
+
+// Label names the archive as "mesa 24.0.5-1".
+func (c CachedPackage) Label() string {
+	return c.Name + " " + c.Version
+}
+
+// CacheGroup is every cached archive of one package, newest first.
+type CacheGroup struct {
+	Name             string
+	InstalledVersion string
+	Archives         []CachedPackage
+	Size             int64
+}
+
+// CacheLine is one visible line of a CacheList: a package header when
+// Archive is -1, otherwise one of the group's archives.
+type CacheLine struct {
+	Group   int
+	Archive int
+}
+
+// CacheList is the package cache grouped by package, optionally filtered
+// by package name. Lines holds the visible lines in order.
+type CacheList struct {
+	Groups []CacheGroup
+	Filter string
+	Lines  []CacheLine
+}
+
+// NewCacheList groups archives by package. archives must be sorted by name,
+// and within a name newest first, as the repository returns them.
+func NewCacheList(archives []CachedPackage) *CacheList {
+	l := &CacheList{}
+	for _, archive := range archives {
+		n := len(l.Groups)
+		if n == 0 || l.Groups[n-1].Name != archive.Name {
+			l.Groups = append(l.Groups, CacheGroup{Name: archive.Name, InstalledVersion: archive.InstalledVersion})
+			n++
+		}
+		group := &l.Groups[n-1]
+		group.Archives = append(group.Archives, archive)
+		group.Size += archive.Size
+	}
+	l.rebuild()
+	return l
+}
+
+// ArchiveCount returns the number of cached archives.
+func (l *CacheList) ArchiveCount() int {
+	count := 0
+	for _, group := range l.Groups {
+		count += len(group.Archives)
+	}
+	return count
+}
+
+// TotalSize returns the combined size of every cached archive.
+func (l *CacheList) TotalSize() int64 {
+	var total int64
+	for _, group := range l.Groups {
+		total += group.Size
+	}
+	return total
+}
+
+func (l *CacheList) rebuild() {
+	l.Lines = l.Lines[:0]
+	filter := strings.ToLower(l.Filter)
+	for g, group := range l.Groups {
+		if filter != "" && !strings.Contains(strings.ToLower(group.Name), filter) {
+			continue
+		}
+		l.Lines = append(l.Lines, CacheLine{Group: g, Archive: -1})
+		for a := range group.Archives {
+			l.Lines = append(l.Lines, CacheLine{Group: g, Archive: a})
+		}
+	}
+}
+
+// SetFilter shows only packages whose name contains filter (case-insensitive).
+func (l *CacheList) SetFilter(filter string) {
+	l.Filter = filter
+	l.rebuild()
+}
+
+// Archive returns the archive on line i, or nil for a header line.
+func (l *CacheList) Archive(i int) *CachedPackage {
+	if i < 0 || i >= len(l.Lines) || l.Lines[i].Archive < 0 {
+		return nil
+	}
+	line := l.Lines[i]
+	return &l.Groups[line.Group].Archives[line.Archive]
+}
+
+// Find returns the line of the archive at path, or -1.
+func (l *CacheList) Find(path string) int {
+	for i := range l.Lines {
+		if archive := l.Archive(i); archive != nil && archive.Path == path {
+			return i
+		}
+	}
+	return -1
+}
+
+// CachePolicy selects the archives a cache cleanup deletes, like paccache.
+// An archive goes if any rule selects it, except the installed version,
+// which is always kept.
+type CachePolicy struct {
+	Keep        int           // versions to keep per package, newest first; 0 keeps all
+	Uninstalled bool          // delete every archive of packages that are not installed
+	MaxAge      time.Duration // delete archives older than this; 0 disables
+}
+
+// String describes the policy as "keep 3, uninstalled, older than 90 days".
+func (p CachePolicy) String() string {
+	var rules []string
+	if p.Keep > 0 {
+		rules = append(rules, fmt.Sprintf("keep %d", p.Keep))
+	}
+	if p.Uninstalled {
+		rules = append(rules, "uninstalled")
+	}
+	if p.MaxAge > 0 {
+		rules = append(rules, fmt.Sprintf("older than %d days", int(p.MaxAge.Hours()/24)))
+	}
+	return strings.Join(rules, ", ")
+}
+
+// CacheCleanup is the set of archives a CachePolicy deletes.
+type CacheCleanup struct {
+	Policy  CachePolicy
+	Remove  []CachedPackage
+	Reasons []string // why each archive in Remove is deleted
+	Size    int64    // space reclaimed
+}
+
+// PlanCacheCleanup applies policy to archives, which must be sorted by name
+// and newest version first, as of now.
+func PlanCacheCleanup(archives []CachedPackage, policy CachePolicy, now time.Time) *CacheCleanup {
+	plan := &CacheCleanup{Policy: policy}
+
+	rank := 0
+	for i, archive := range archives {
+		if i == 0 || archives[i-1].Name != archive.Name {
+			rank = 0
+		}
+		rank++
+
+		if archive.Installed() {
+			continue
+		}
+
+		var reasons []string
+		if policy.Keep > 0 && rank > policy.Keep {
+			reasons = append(reasons, fmt.Sprintf("beyond the %d newest", policy.Keep))
+		}
+		if policy.Uninstalled && archive.InstalledVersion == "" {
+			reasons = append(reasons, "not installed")
+		}
+		if policy.MaxAge > 0 && now.Sub(archive.ModTime) > policy.MaxAge {
+			reasons = append(reasons, fmt.Sprintf("%d days old", int(now.Sub(archive.ModTime).Hours()/24)))
+		}
+		if len(reasons) == 0 {
+			continue
+		}
+
+		plan.Remove = append(plan.Remove, archive)
+		plan.Reasons = append(plan.Reasons, strings.Join(reasons, "; "))
+		plan.Size += archive.Size
+	}
+
+	return plan
+}
+
+// Paths returns the files of the archives to delete.
+func (c *CacheCleanup) Paths() []string {
+	paths := make([]string, len(c.Remove))
+	for i, archive := range c.Remove {
+		paths[i] = archive.Path
+	}
+	return paths
+}
@@ -0,0 +1,104 @@
+package domain
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestCacheList(t *testing.T) {
+	list := NewCacheList([]CachedPackage{
+		{Name: "mesa", Version: "24.1.2-1", Path: "/cache/mesa-24.1.2-1", Size: 300, InstalledVersion: "24.1.2-1"},
+		{Name: "mesa", Version: "24.0.5-1", Path: "/cache/mesa-24.0.5-1", Size: 200, InstalledVersion: "24.1.2-1"},
+		{Name: "zstd", Version: "1.5.6-1", Path: "/cache/zstd-1.5.6-1", Size: 10},
+	})
+
+	if len(list.Groups) != 2 || list.ArchiveCount() != 3 || list.TotalSize() != 510 {
+		t.Fatalf("unexpected groups %+v", list.Groups)
+	}
+	if len(list.Lines) != 5 || list.Archive(0) != nil {
+		t.Fatalf("expected a header and archives per package, got %+v", list.Lines)
+	}
+	if archive := list.Archive(1); archive == nil || !archive.Installed() || archive.Label() != "mesa 24.1.2-1" {
+		t.Errorf("line 1 = %+v, want the installed mesa", archive)
+	}
+	if archive := list.Archive(2); archive == nil || archive.Installed() {
+		t.Errorf("line 2 = %+v, want the older mesa", archive)
+	}
+
+	list.SetFilter("ZST")
+	if len(list.Lines) != 2 || list.Archive(1).Name != "zstd" {
+		t.Errorf("filter: unexpected lines %+v", list.Lines)
+	}
+	if list.Find("/cache/mesa-24.0.5-1") != -1 || list.Find("/cache/zstd-1.5.6-1") != 1 {
+		t.Error("Find should only see visible lines")
+	}
+}
+
+func TestPlanCacheCleanup(t *testing.T) {
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	archive := func(name, version, installed string, age int) CachedPackage {
+		return CachedPackage{
+			Name:             name,
+			Version:          version,
+			Path:             "/cache/" + name + "-" + version,
+			Size:             100,
+			ModTime:          now.AddDate(0, 0, -age),
+			InstalledVersion: installed,
+		}
+	}
+	archives := []CachedPackage{
+		archive("mesa", "24.1.2-1", "24.0.5-1", 1),
+		archive("mesa", "24.1.0-1", "24.0.5-1", 10),
+		archive("mesa", "24.0.5-1", "24.0.5-1", 40),
+		archive("mesa", "23.3.1-1", "24.0.5-1", 200),
+		archive("zstd", "1.5.6-1", "", 5),
+	}
+
+	tests := []struct {
+		name    string
+		policy  CachePolicy
+		removed []string
+		reasons []string
+	}{
+		{
+			name:    "keep two keeps the installed version too",
+			policy:  CachePolicy{Keep: 2},
+			removed: []string{"/cache/mesa-23.3.1-1"},
+			reasons: []string{"beyond the 2 newest"},
+		},
+		{
+			name:    "uninstalled",
+			policy:  CachePolicy{Uninstalled: true},
+			removed: []string{"/cache/zstd-1.5.6-1"},
+			reasons: []string{"not installed"},
+		},
+		{
+			name:    "age and keep combine",
+			policy:  CachePolicy{Keep: 3, MaxAge: 7 * 24 * time.Hour},
+			removed: []string{"/cache/mesa-24.1.0-1", "/cache/mesa-23.3.1-1"},
+			reasons: []string{"10 days old", "beyond the 3 newest; 200 days old"},
+		},
+		{
+			name:   "empty policy",
+			policy: CachePolicy{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan := PlanCacheCleanup(archives, tt.policy, now)
+			if !reflect.DeepEqual(plan.Paths(), append([]string{}, tt.removed...)) ||
+				!reflect.DeepEqual(plan.Reasons, tt.reasons) {
+				t.Errorf("removed %q (%q), want %q (%q)", plan.Paths(), plan.Reasons, tt.removed, tt.reasons)
+			}
+			if plan.Size != int64(100*len(tt.removed)) {
+				t.Errorf("Size = %d", plan.Size)
+			}
+		})
+	}
+
+	if got := (CachePolicy{Keep: 3, Uninstalled: true, MaxAge: 90 * 24 * time.Hour}).String(); got != "keep 3, uninstalled, older than 90 days" {
+		t.Errorf("String() = %q", got)
+	}
+}
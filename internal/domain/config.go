@@ -0,0 +1,51 @@
+package domain
+
+import (
+	"sort"
+	"strings"
+)
+
+// ConfigFile is a .pacnew or .pacsave file pacman left next to one of a
+// package's backup files for the user to merge.
+type ConfigFile struct {
+	Package string // empty for a .pacsave left by a removed package
+	Path    string // the live configuration file
+	New     string // the .pacnew or .pacsave file next to it
+}
+
+// NewConfigFile describes the .pacnew or .pacsave file at path.
+func NewConfigFile(pkg, path string) ConfigFile {
+	live := strings.TrimSuffix(strings.TrimSuffix(path, ".pacnew"), ".pacsave")
+	return ConfigFile{Package: pkg, Path: live, New: path}
+}
+
+// Owner returns the package the file belongs to, or "removed package".
+func (c ConfigFile) Owner() string {
+	if c.Package == "" {
+		return "removed package"
+	}
+	return c.Package
+}
+
+// Kind returns "pacnew" or "pacsave".
+func (c ConfigFile) Kind() string {
+	if strings.HasSuffix(c.New, ".pacsave") {
+		return "pacsave"
+	}
+	return "pacnew"
+}
+
+// PendingConfigFiles lists the .pacnew and .pacsave files of packages,
+// sorted by path.
+func PendingConfigFiles(packages []*Package) []ConfigFile {
+	var files []ConfigFile
+	for _, pkg := range packages {
+		for _, path := range pkg.PendingConfigs {
+			files = append(files, NewConfigFile(pkg.Name, path))
+		}
+	}
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].New < files[j].New
+	})
+	return files
+}
@@ -0,0 +1,197 @@
+package domain
+
+import (
+	"sort"
+	"strings"
+)
+
+// TreeDirection selects which edges a DependencyTree follows.
+type TreeDirection int
+
+const (
+	TreeDepends    TreeDirection = iota // what the root pulls in
+	TreeRequiredBy                      // what pulls the root in
+)
+
+func (d TreeDirection) String() string {
+	if d == TreeRequiredBy {
+		return "Required By"
+	}
+	return "Depends"
+}
+
+// TreeNode is one visible line of a DependencyTree.
+type TreeNode struct {
+	Name        string
+	Depth       int
+	Prefix      string // box-drawing connectors leading up to the name
+	Expanded    bool
+	HasChildren bool
+	Cycle       bool // Name already appears between the root and this node
+	Repeat      bool // Name is expanded earlier in the tree, so it is not again
+	Truncated   bool // HasChildren, but the depth limit hides them
+
+	path string
+}
+
+// DependencyTree is a pactree-style view of the installed packages around
+// a root package. Nodes expand and collapse individually; Nodes holds the
+// currently visible lines in display order. Like pactree, a package's
+// dependencies are listed only under its first expanded occurrence, which
+// keeps the tree linear in the size of the graph.
+type DependencyTree struct {
+	Root      string
+	Direction TreeDirection
+	MaxDepth  int // 0 means unlimited
+	Nodes     []TreeNode
+
+	expanded map[string]bool
+	depends  map[string][]string
+	required map[string][]string
+}
+
+// NewDependencyTree builds a tree over installed, which must have Required
+// populated. Dependencies are derived from Required so that they resolve
+// through provides exactly as reverse dependencies do. The root starts
+// expanded.
+func NewDependencyTree(installed []*Package, root string, dir TreeDirection) *DependencyTree {
+	t := &DependencyTree{
+		Root:      root,
+		Direction: dir,
+		expanded:  map[string]bool{root: true},
+		depends:   make(map[string][]string),
+		required:  make(map[string][]string, len(installed)),
+	}
+
+	for _, pkg := range installed {
+		required := append([]string(nil), pkg.Required...)
+		sort.Strings(required)
+		t.required[pkg.Name] = required
+		for _, dependent := range pkg.Required {
+			t.depends[dependent] = append(t.depends[dependent], pkg.Name)
+		}
+	}
+	for _, deps := range t.depends {
+		sort.Strings(deps)
+	}
+
+	t.rebuild(false)
+	return t
+}
+
+func (t *DependencyTree) children(name string) []string {
+	if t.Direction == TreeRequiredBy {
+		return t.required[name]
+	}
+	return t.depends[name]
+}
+
+// rebuild flattens the expanded part of the tree into Nodes. With
+// expandAll, every node that can expand is marked expanded on the way.
+func (t *DependencyTree) rebuild(expandAll bool) {
+	t.Nodes = t.Nodes[:0]
+	shown := make(map[string]bool)
+
+	var walk func(name, path, prefix, childPrefix string, depth int, ancestors map[string]bool)
+	walk = func(name, path, prefix, childPrefix string, depth int, ancestors map[string]bool) {
+		children := t.children(name)
+		node := TreeNode{
+			Name:        name,
+			Depth:       depth,
+			Prefix:      prefix,
+			HasChildren: len(children) > 0,
+			Cycle:       ancestors[name],
+			path:        path,
+		}
+		node.Repeat = node.HasChildren && !node.Cycle && shown[name]
+		node.Truncated = node.HasChildren && !node.Cycle && !node.Repeat && t.MaxDepth > 0 && depth >= t.MaxDepth
+		canExpand := node.HasChildren && !node.Cycle && !node.Repeat && !node.Truncated
+		if canExpand && expandAll {
+			t.expanded[path] = true
+		}
+		node.Expanded = canExpand && t.expanded[path]
+		t.Nodes = append(t.Nodes, node)
+
+		if !node.Expanded {
+			return
+		}
+
+		shown[name] = true
+		ancestors[name] = true
+		for i, child := range children {
+			connector, next := "├── ", "│   "
+			if i == len(children)-1 {
+				connector, next = "└── ", "    "
+			}
+			walk(child, path+"/"+child, childPrefix+connector, childPrefix+next, depth+1, ancestors)
+		}
+		delete(ancestors, name)
+	}
+
+	walk(t.Root, t.Root, "", "", 0, make(map[string]bool))
+}
+
+// Expand opens the node at index i. It reports whether anything changed.
+func (t *DependencyTree) Expand(i int) bool {
+	if i < 0 || i >= len(t.Nodes) {
+		return false
+	}
+	node := t.Nodes[i]
+	if node.Expanded || !node.HasChildren || node.Cycle || node.Repeat || node.Truncated {
+		return false
+	}
+	t.expanded[node.path] = true
+	t.rebuild(false)
+	return true
+}
+
+// Collapse closes the node at index i. It reports whether anything changed.
+func (t *DependencyTree) Collapse(i int) bool {
+	if i < 0 || i >= len(t.Nodes) || !t.Nodes[i].Expanded {
+		return false
+	}
+	delete(t.expanded, t.Nodes[i].path)
+	t.rebuild(false)
+	return true
+}
+
+// Toggle expands or collapses the node at index i.
+func (t *DependencyTree) Toggle(i int) {
+	if !t.Collapse(i) {
+		t.Expand(i)
+	}
+}
+
+// ExpandAll opens every node down to the depth limit in a single pass.
+// Cycles and repeats are never followed, so this terminates on any graph.
+func (t *DependencyTree) ExpandAll() {
+	t.rebuild(true)
+}
+
+// Parent returns the index of the node's parent, or -1 for the root.
+func (t *DependencyTree) Parent(i int) int {
+	if i <= 0 || i >= len(t.Nodes) {
+		return -1
+	}
+	parentPath := t.Nodes[i].path[:strings.LastIndex(t.Nodes[i].path, "/")]
+	for j := i - 1; j >= 0; j-- {
+		if t.Nodes[j].path == parentPath {
+			return j
+		}
+	}
+	return -1
+}
+
+// SetDirection switches between the depends and required-by trees,
+// collapsing everything below the root.
+func (t *DependencyTree) SetDirection(dir TreeDirection) {
+	t.Direction = dir
+	t.expanded = map[string]bool{t.Root: true}
+	t.rebuild(false)
+}
+
+// SetMaxDepth changes the depth limit; 0 removes it.
+func (t *DependencyTree) SetMaxDepth(depth int) {
+	t.MaxDepth = max(depth, 0)
+	t.rebuild(false)
+}
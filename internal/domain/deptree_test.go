@@ -0,0 +1,121 @@
+package domain
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+// treeFixture: app -> libapp -> {libcommon, libcycle}, libcycle <-> libloop
+func treeFixture() []*Package {
+	return []*Package{
+		{Name: "app"},
+		{Name: "libapp", Required: []string{"app"}},
+		{Name: "libcommon", Required: []string{"libapp", "tool"}},
+		{Name: "libcycle", Required: []string{"libapp", "libloop"}},
+		{Name: "libloop", Required: []string{"libcycle"}},
+		{Name: "tool"},
+	}
+}
+
+func treeLines(tree *DependencyTree) []string {
+	lines := make([]string, len(tree.Nodes))
+	for i, node := range tree.Nodes {
+		lines[i] = node.Prefix + node.Name
+		if node.Cycle {
+			lines[i] += " (cycle)"
+		}
+		if node.Repeat {
+			lines[i] += " (repeat)"
+		}
+	}
+	return lines
+}
+
+func TestDependencyTree_Expand(t *testing.T) {
+	tree := NewDependencyTree(treeFixture(), "app", TreeDepends)
+	if got, want := treeLines(tree), []string{"app", "└── libapp"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("initial tree = %q, want %q", got, want)
+	}
+
+	tree.ExpandAll()
+	want := []string{
+		"app",
+		"└── libapp",
+		"    ├── libcommon",
+		"    └── libcycle",
+		"        └── libloop",
+		"            └── libcycle (cycle)",
+	}
+	if got := treeLines(tree); !reflect.DeepEqual(got, want) {
+		t.Errorf("expanded tree = %q, want %q", got, want)
+	}
+	if parent := tree.Parent(4); parent != 3 {
+		t.Errorf("Parent(4) = %d, want 3", parent)
+	}
+
+	if !tree.Collapse(1) || len(tree.Nodes) != 2 {
+		t.Errorf("collapsing libapp left %q", treeLines(tree))
+	}
+	tree.Expand(1)
+	if len(tree.Nodes) != 6 {
+		t.Errorf("re-expanding libapp should restore its expanded children, got %q", treeLines(tree))
+	}
+}
+
+func TestDependencyTree_DepthAndDirection(t *testing.T) {
+	tree := NewDependencyTree(treeFixture(), "app", TreeDepends)
+	tree.SetMaxDepth(2)
+	tree.ExpandAll()
+
+	if len(tree.Nodes) != 4 || !tree.Nodes[3].Truncated || tree.Nodes[3].Expanded {
+		t.Errorf("depth-limited tree = %q", treeLines(tree))
+	}
+
+	tree = NewDependencyTree(treeFixture(), "libcommon", TreeRequiredBy)
+	tree.ExpandAll()
+	want := []string{"libcommon", "├── libapp", "│   └── app", "└── tool"}
+	if got := treeLines(tree); !reflect.DeepEqual(got, want) {
+		t.Errorf("required-by tree = %q, want %q", got, want)
+	}
+}
+
+func TestDependencyTree_Repeats(t *testing.T) {
+	tree := NewDependencyTree(treeFixture(), "libcommon", TreeRequiredBy)
+	tree.ExpandAll()
+	if tree.Nodes[1].Repeat {
+		t.Fatalf("first occurrence marked as a repeat: %q", treeLines(tree))
+	}
+
+	// A ladder of diamonds: every level has two packages that both depend on
+	// both packages of the next level, so the unshared tree doubles per level.
+	const levels = 30
+	var packages []*Package
+	for level := 0; level < levels; level++ {
+		for _, side := range []string{"a", "b"} {
+			pkg := &Package{Name: fmt.Sprintf("%s%d", side, level)}
+			if level > 0 {
+				pkg.Required = []string{fmt.Sprintf("a%d", level-1), fmt.Sprintf("b%d", level-1)}
+			}
+			packages = append(packages, pkg)
+		}
+	}
+	packages = append(packages, &Package{Name: "root"})
+	packages[0].Required = []string{"root"}
+	packages[1].Required = []string{"root"}
+
+	tree = NewDependencyTree(packages, "root", TreeDepends)
+	tree.ExpandAll()
+	// The root and every package above the last level expand once, each
+	// listing two children.
+	if want := 1 + 2*(2*levels-1); len(tree.Nodes) != want {
+		t.Fatalf("expanded ladder has %d nodes, want %d", len(tree.Nodes), want)
+	}
+	want := []string{"root", "├── a0", "│   ├── a1", "│   │   ├── a2"}
+	if got := treeLines(tree)[:4]; !reflect.DeepEqual(got, want) {
+		t.Errorf("ladder starts %q, want %q", got, want)
+	}
+	if last := tree.Nodes[len(tree.Nodes)-1]; last.Name != "b1" || !last.Repeat || last.Expanded {
+		t.Errorf("expected b1 under b0 to be a repeat, got %+v", last)
+	}
+}
@@ -0,0 +1,184 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+)
+
+type diffOp int
+
+const (
+	diffEqual diffOp = iota
+	diffDelete
+	diffInsert
+)
+
+// diffEdit is one line of an edit script. a and b are the line's index in
+// the old and new text, or how many lines of that text precede it when the
+// line is absent from it.
+type diffEdit struct {
+	op   diffOp
+	a, b int
+	text string
+}
+
+// maxDiffTrace caps how many ints diffLines keeps for its backtrace, which
+// grows with both the file sizes and the number of differences.
+const maxDiffTrace = 1 << 22
+
+// diffLines returns the shortest edit script turning a into b (Myers' O(ND)
+// algorithm). Texts too large or too different to diff within maxDiffTrace
+// are shown as a whole-file replacement instead.
+func diffLines(a, b []string) []diffEdit {
+	n, m := len(a), len(b)
+	offset := n + m + 1
+	v := make([]int, 2*offset+1)
+	var trace [][]int
+
+search:
+	for d := 0; d <= n+m; d++ {
+		if (d+1)*len(v) > maxDiffTrace {
+			return replaceLines(a, b)
+		}
+		trace = append(trace, append([]int(nil), v...))
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				break search
+			}
+		}
+	}
+
+	// Walk the trace back from the end, collecting edits in reverse.
+	var edits []diffEdit
+	x, y := n, m
+	for d := len(trace) - 1; d >= 0; d-- {
+		v := trace[d]
+		k := x - y
+		prevK := k - 1
+		if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+			prevK = k + 1
+		}
+		prevX := v[offset+prevK]
+		prevY := prevX - prevK
+
+		for x > prevX && y > prevY {
+			x--
+			y--
+			edits = append(edits, diffEdit{op: diffEqual, a: x, b: y, text: a[x]})
+		}
+		if d > 0 {
+			if x == prevX {
+				y--
+				edits = append(edits, diffEdit{op: diffInsert, a: x, b: y, text: b[y]})
+			} else {
+				x--
+				edits = append(edits, diffEdit{op: diffDelete, a: x, b: y, text: a[x]})
+			}
+		}
+	}
+
+	for i, j := 0, len(edits)-1; i < j; i, j = i+1, j-1 {
+		edits[i], edits[j] = edits[j], edits[i]
+	}
+	return edits
+}
+
+// replaceLines returns an edit script deleting every line of a and then
+// inserting every line of b.
+func replaceLines(a, b []string) []diffEdit {
+	edits := make([]diffEdit, 0, len(a)+len(b))
+	for i, line := range a {
+		edits = append(edits, diffEdit{op: diffDelete, a: i, b: 0, text: line})
+	}
+	for i, line := range b {
+		edits = append(edits, diffEdit{op: diffInsert, a: len(a), b: i, text: line})
+	}
+	return edits
+}
+
+// splitLines splits text into lines, ignoring a final newline.
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+}
+
+// UnifiedDiff compares two texts line by line and returns the differences
+// in unified format with context lines around each change, like
+// diff -u. Identical texts yield no lines.
+func UnifiedDiff(oldName, newName, oldText, newText string, context int) []string {
+	edits := diffLines(splitLines(oldText), splitLines(newText))
+
+	var changes []int
+	for i, edit := range edits {
+		if edit.op != diffEqual {
+			changes = append(changes, i)
+		}
+	}
+	if len(changes) == 0 {
+		return nil
+	}
+
+	lines := []string{"--- " + oldName, "+++ " + newName}
+	for i := 0; i < len(changes); {
+		// Extend the hunk while the next change is within reach of its context.
+		j := i
+		for j+1 < len(changes) && changes[j+1]-changes[j] <= 2*context {
+			j++
+		}
+		start := max(changes[i]-context, 0)
+		end := min(changes[j]+context+1, len(edits))
+		lines = append(lines, formatHunk(edits[start:end])...)
+		i = j + 1
+	}
+	return lines
+}
+
+func formatHunk(edits []diffEdit) []string {
+	oldCount, newCount := 0, 0
+	body := make([]string, 0, len(edits))
+	for _, edit := range edits {
+		switch edit.op {
+		case diffEqual:
+			oldCount++
+			newCount++
+			body = append(body, " "+edit.text)
+		case diffDelete:
+			oldCount++
+			body = append(body, "-"+edit.text)
+		case diffInsert:
+			newCount++
+			body = append(body, "+"+edit.text)
+		}
+	}
+
+	header := fmt.Sprintf("@@ -%s +%s @@",
+		formatHunkRange(edits[0].a, oldCount), formatHunkRange(edits[0].b, newCount))
+	return append([]string{header}, body...)
+}
+
+// formatHunkRange formats a hunk's 1-based start line and length, which
+// diff abbreviates for a single line and starts one line earlier when empty.
+func formatHunkRange(first, count int) string {
+	switch count {
+	case 0:
+		return fmt.Sprintf("%d,0", first)
+	case 1:
+		return fmt.Sprintf("%d", first+1)
+	default:
+		return fmt.Sprintf("%d,%d", first+1, count)
+	}
+}
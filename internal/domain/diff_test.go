@@ -0,0 +1,99 @@
+package domain
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestUnifiedDiff(t *testing.T) {
+	letters := "a\nb\nc\nd\ne\nf\ng\nh\ni\nj\nk\nl\nm\n"
+
+	tests := []struct {
+		name     string
+		old, new string
+		want     []string
+	}{
+		{
+			name: "identical",
+			old:  letters,
+			new:  letters,
+			want: nil,
+		},
+		{
+			name: "two hunks",
+			old:  letters,
+			new:  "a\nB\nc\nd\ne\nf\ng\nh\ni\nj\nk\nm\nn\n",
+			want: []string{
+				"--- old", "+++ new",
+				"@@ -1,5 +1,5 @@", " a", "-b", "+B", " c", " d", " e",
+				"@@ -9,5 +9,5 @@", " i", " j", " k", "-l", " m", "+n",
+			},
+		},
+		{
+			name: "adjacent changes share a hunk",
+			old:  "a\nb\nc\nd\ne\n",
+			new:  "a\nB\nc\nD\ne\n",
+			want: []string{
+				"--- old", "+++ new",
+				"@@ -1,5 +1,5 @@", " a", "-b", "+B", " c", "-d", "+D", " e",
+			},
+		},
+		{
+			name: "new file",
+			old:  "",
+			new:  "a\nb\n",
+			want: []string{"--- old", "+++ new", "@@ -0,0 +1,2 @@", "+a", "+b"},
+		},
+		{
+			name: "single line",
+			old:  "x\n",
+			new:  "y",
+			want: []string{"--- old", "+++ new", "@@ -1 +1 @@", "-x", "+y"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := UnifiedDiff("old", "new", tt.old, tt.new, 3); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("UnifiedDiff() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUnifiedDiff_Large(t *testing.T) {
+	var old, new strings.Builder
+	for i := range 3000 {
+		fmt.Fprintf(&old, "old %d\n", i)
+		fmt.Fprintf(&new, "new %d\n", i)
+	}
+
+	got := UnifiedDiff("old", "new", old.String(), new.String(), 3)
+	if len(got) != 2+1+6000 {
+		t.Fatalf("expected a single whole-file hunk, got %d lines", len(got))
+	}
+	if got[2] != "@@ -1,3000 +1,3000 @@" || got[3] != "-old 0" || got[3003] != "+new 0" {
+		t.Errorf("unexpected hunk start %q, %q, %q", got[2], got[3], got[3003])
+	}
+}
+
+func TestPendingConfigFiles(t *testing.T) {
+	files := PendingConfigFiles([]*Package{
+		{Name: "pacman", PendingConfigs: []string{"/etc/pacman.conf.pacnew"}},
+		{Name: "glibc", PendingConfigs: []string{"/etc/nsswitch.conf.pacsave", "/etc/locale.gen.pacnew"}},
+	})
+
+	want := []ConfigFile{
+		{Package: "glibc", Path: "/etc/locale.gen", New: "/etc/locale.gen.pacnew"},
+		{Package: "glibc", Path: "/etc/nsswitch.conf", New: "/etc/nsswitch.conf.pacsave"},
+		{Package: "pacman", Path: "/etc/pacman.conf", New: "/etc/pacman.conf.pacnew"},
+	}
+	if !reflect.DeepEqual(files, want) {
+		t.Errorf("PendingConfigFiles() = %+v", files)
+	}
+	if files[0].Kind() != "pacnew" || files[1].Kind() != "pacsave" {
+		t.Errorf("unexpected kinds %q, %q", files[0].Kind(), files[1].Kind())
+	}
+}
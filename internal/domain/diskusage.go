@@ -0,0 +1,188 @@
+package domain
+
+import "sort"
+
+// Footprint is what removing an explicit package with pacman -Rs would
+// free: the package itself plus the dependencies nothing else needs.
+type Footprint struct {
+	Package    *Package
+	Deps       []*Package // dependencies removed along with it, sorted by name
+	Size       int64      // the package's and its exclusive dependencies' installed size
+	RequiredBy []string   // packages that still depend on the package itself
+}
+
+// ExclusiveFootprint works out the footprint of the installed package name.
+// installed must have Required populated.
+func ExclusiveFootprint(installed []*Package, name string) (Footprint, error) {
+	plan, err := PlanRemoval(installed, []string{name}, RemoveOptions{Recursive: true})
+	if err != nil {
+		return Footprint{}, err
+	}
+
+	fp := Footprint{Size: plan.FreedSize}
+	for _, pkg := range plan.Removed {
+		if pkg.Name == name {
+			fp.Package = pkg
+			fp.RequiredBy = pkg.Required
+		} else {
+			fp.Deps = append(fp.Deps, pkg)
+		}
+	}
+	return fp, nil
+}
+
+// UsageTotal is the combined installed size of the packages in a
+// repository, group or preset.
+type UsageTotal struct {
+	Name     string
+	Packages int
+	Size     int64
+}
+
+// UsageSection is one of the disk usage view's lists.
+type UsageSection int
+
+const (
+	UsageLargest UsageSection = iota
+	UsageRepos
+	UsageGroups
+	UsagePresets
+	usageSections
+)
+
+func (s UsageSection) String() string {
+	switch s {
+	case UsageLargest:
+		return "Largest packages"
+	case UsageRepos:
+		return "Repositories"
+	case UsageGroups:
+		return "Groups"
+	case UsagePresets:
+		return "Presets"
+	default:
+		return "Unknown"
+	}
+}
+
+// Next returns the section after s, wrapping around.
+func (s UsageSection) Next() UsageSection {
+	return (s + 1) % usageSections
+}
+
+// DiskUsage breaks down the installed size of a system.
+type DiskUsage struct {
+	Packages   int
+	Size       int64
+	Footprints []Footprint  // explicit packages, largest footprint first
+	Repos      []UsageTotal // largest first
+	Groups     []UsageTotal // largest first; packages without a group are left out
+	Presets    []UsageTotal // in DefaultPresets order
+}
+
+// NewDiskUsage computes the footprint of every explicit package and the
+// totals per repository, group and preset. installed must have Required
+// populated.
+func NewDiskUsage(installed []*Package) *DiskUsage {
+	u := &DiskUsage{Packages: len(installed)}
+
+	repos := make(map[string]*UsageTotal)
+	groups := make(map[string]*UsageTotal)
+	add := func(totals map[string]*UsageTotal, name string, pkg *Package) {
+		total, ok := totals[name]
+		if !ok {
+			total = &UsageTotal{Name: name}
+			totals[name] = total
+		}
+		total.Packages++
+		total.Size += pkg.InstalledSize
+	}
+
+	for _, pkg := range installed {
+		u.Size += pkg.InstalledSize
+
+		repo := pkg.Repository
+		if repo == "" {
+			repo = "unknown"
+		}
+		add(repos, repo, pkg)
+		for _, group := range pkg.Groups {
+			add(groups, group, pkg)
+		}
+
+		if pkg.InstallReason == ReasonExplicit {
+			if fp, err := ExclusiveFootprint(installed, pkg.Name); err == nil {
+				u.Footprints = append(u.Footprints, fp)
+			}
+		}
+	}
+
+	sort.SliceStable(u.Footprints, func(i, j int) bool {
+		if u.Footprints[i].Size != u.Footprints[j].Size {
+			return u.Footprints[i].Size > u.Footprints[j].Size
+		}
+		return u.Footprints[i].Package.Name < u.Footprints[j].Package.Name
+	})
+	u.Repos = sortedTotals(repos)
+	u.Groups = sortedTotals(groups)
+
+	for _, preset := range DefaultPresets() {
+		if preset.Type == PresetAll {
+			continue
+		}
+		total := UsageTotal{Name: preset.Name}
+		for _, pkg := range installed {
+			if preset.Filter(pkg) {
+				total.Packages++
+				total.Size += pkg.InstalledSize
+			}
+		}
+		u.Presets = append(u.Presets, total)
+	}
+
+	return u
+}
+
+// Len returns the number of entries in a section.
+func (u *DiskUsage) Len(section UsageSection) int {
+	switch section {
+	case UsageLargest:
+		return len(u.Footprints)
+	case UsageRepos:
+		return len(u.Repos)
+	case UsageGroups:
+		return len(u.Groups)
+	case UsagePresets:
+		return len(u.Presets)
+	default:
+		return 0
+	}
+}
+
+// Totals returns the entries of a totals section, or nil for UsageLargest.
+func (u *DiskUsage) Totals(section UsageSection) []UsageTotal {
+	switch section {
+	case UsageRepos:
+		return u.Repos
+	case UsageGroups:
+		return u.Groups
+	case UsagePresets:
+		return u.Presets
+	default:
+		return nil
+	}
+}
+
+func sortedTotals(totals map[string]*UsageTotal) []UsageTotal {
+	sorted := make([]UsageTotal, 0, len(totals))
+	for _, total := range totals {
+		sorted = append(sorted, *total)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].Size != sorted[j].Size {
+			return sorted[i].Size > sorted[j].Size
+		}
+		return sorted[i].Name < sorted[j].Name
+	})
+	return sorted
+}
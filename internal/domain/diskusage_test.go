@@ -0,0 +1,67 @@
+package domain
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNewDiskUsage(t *testing.T) {
+	pkgs := removalFixture()
+	for _, pkg := range pkgs {
+		switch pkg.Name {
+		case "app":
+			pkg.Repository = "extra"
+			pkg.Groups = []string{"devel", "gui"}
+		case "tool":
+			pkg.Groups = []string{"devel"}
+		default:
+			pkg.Repository = "core"
+		}
+	}
+
+	usage := NewDiskUsage(pkgs)
+	if usage.Packages != 5 || usage.Size != 113 {
+		t.Errorf("totals = %d packages, %d bytes, want 5 packages, 113 bytes", usage.Packages, usage.Size)
+	}
+
+	var footprints []string
+	for _, fp := range usage.Footprints {
+		footprints = append(footprints, fp.Package.Name)
+	}
+	if !reflect.DeepEqual(footprints, []string{"app", "tool"}) {
+		t.Fatalf("footprints = %v, want app then tool", footprints)
+	}
+	app := usage.Footprints[0]
+	if app.Size != 112 || len(app.Deps) != 2 || app.Deps[0].Name != "libapp" || app.Deps[1].Name != "libextra" {
+		t.Errorf("app footprint = %d bytes with %d deps, want 112 bytes with libapp and libextra", app.Size, len(app.Deps))
+	}
+	if tool := usage.Footprints[1]; tool.Size != 0 || len(tool.Deps) != 0 {
+		t.Errorf("tool footprint = %d bytes with %d deps, want the shared libcommon kept", tool.Size, len(tool.Deps))
+	}
+
+	wantRepos := []UsageTotal{{"extra", 1, 100}, {"core", 3, 13}, {"unknown", 1, 0}}
+	if !reflect.DeepEqual(usage.Repos, wantRepos) {
+		t.Errorf("Repos = %v, want %v", usage.Repos, wantRepos)
+	}
+	wantGroups := []UsageTotal{{"devel", 2, 100}, {"gui", 1, 100}}
+	if !reflect.DeepEqual(usage.Groups, wantGroups) {
+		t.Errorf("Groups = %v, want %v", usage.Groups, wantGroups)
+	}
+	if explicit := usage.Presets[0]; explicit != (UsageTotal{"Explicit", 2, 100}) {
+		t.Errorf("Presets[0] = %v, want the explicit packages", explicit)
+	}
+}
+
+func TestExclusiveFootprint_RequiredPackage(t *testing.T) {
+	fp, err := ExclusiveFootprint(removalFixture(), "libapp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fp.Size != 12 || !reflect.DeepEqual(fp.RequiredBy, []string{"app"}) {
+		t.Errorf("libapp footprint = %d bytes, required by %v; want 12 bytes, required by app", fp.Size, fp.RequiredBy)
+	}
+
+	if _, err := ExclusiveFootprint(removalFixture(), "missing"); err == nil {
+		t.Error("expected an error for a package that is not installed")
+	}
+}
@@ -0,0 +1,252 @@
+package domain
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sjsanc/pacviz/v3/internal/ui/column"
+)
+
+// TableFormat is a file format the package table can be exported in.
+type TableFormat string
+
+const (
+	TableCSV      TableFormat = "csv"
+	TableJSON     TableFormat = "json"
+	TableMarkdown TableFormat = "md"
+)
+
+// ParseTableFormat accepts a format name as given to :export.
+func ParseTableFormat(s string) (TableFormat, bool) {
+	switch strings.ToLower(s) {
+	case "csv":
+		return TableCSV, true
+	case "json":
+		return TableJSON, true
+	case "md", "markdown":
+		return TableMarkdown, true
+	default:
+		return "", false
+	}
+}
+
+// TableFormatFor picks the export format from a file's extension.
+func TableFormatFor(path string) (TableFormat, bool) {
+	return ParseTableFormat(strings.TrimPrefix(filepath.Ext(path), "."))
+}
+
+// ExportTable writes rows with the given columns. CSV and JSON hold the raw
+// package fields, so sizes are bytes, dates RFC 3339 and lists arrays (or
+// comma-separated in CSV); Markdown shows the table's formatted cells. The
+// index column numbers the rows in order.
+func ExportTable(w io.Writer, rows []*Row, columns []*column.Column, format TableFormat) error {
+	bw := bufio.NewWriter(w)
+
+	var err error
+	switch format {
+	case TableCSV:
+		err = exportCSV(bw, rows, columns)
+	case TableJSON:
+		err = exportJSON(bw, rows, columns)
+	case TableMarkdown:
+		err = exportMarkdown(bw, rows, columns)
+	default:
+		err = fmt.Errorf("unknown format %q", format)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to export table: %w", err)
+	}
+
+	if err := bw.Flush(); err != nil {
+		return fmt.Errorf("failed to export table: %w", err)
+	}
+	return nil
+}
+
+func exportCSV(w io.Writer, rows []*Row, columns []*column.Column) error {
+	cw := csv.NewWriter(w)
+
+	header := make([]string, len(columns))
+	for i, col := range columns {
+		header[i] = col.Name
+	}
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+
+	record := make([]string, len(columns))
+	for i, row := range rows {
+		for j, col := range columns {
+			switch v := exportValue(row, col.Type, i+1).(type) {
+			case []string:
+				record[j] = strings.Join(v, ", ")
+			default:
+				record[j] = fmt.Sprint(v)
+			}
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// exportJSON writes an array of objects keyed by column identifier, keeping
+// the columns in table order.
+func exportJSON(w io.Writer, rows []*Row, columns []*column.Column) error {
+	if _, err := io.WriteString(w, "["); err != nil {
+		return err
+	}
+	for i, row := range rows {
+		var b strings.Builder
+		if i > 0 {
+			b.WriteString(",")
+		}
+		b.WriteString("\n  {")
+		for j, col := range columns {
+			key, err := json.Marshal(string(col.Type))
+			if err != nil {
+				return err
+			}
+			value, err := json.Marshal(exportValue(row, col.Type, i+1))
+			if err != nil {
+				return err
+			}
+			if j > 0 {
+				b.WriteString(", ")
+			}
+			b.Write(key)
+			b.WriteString(": ")
+			b.Write(value)
+		}
+		b.WriteString("}")
+		if _, err := io.WriteString(w, b.String()); err != nil {
+			return err
+		}
+	}
+	if len(rows) > 0 {
+		_, err := io.WriteString(w, "\n]\n")
+		return err
+	}
+	_, err := io.WriteString(w, "]\n")
+	return err
+}
+
+func exportMarkdown(w io.Writer, rows []*Row, columns []*column.Column) error {
+	cells := make([]string, len(columns))
+	writeLine := func() error {
+		_, err := io.WriteString(w, "| "+strings.Join(cells, " | ")+" |\n")
+		return err
+	}
+
+	for i, col := range columns {
+		cells[i] = escapeMarkdownCell(col.Name)
+	}
+	if err := writeLine(); err != nil {
+		return err
+	}
+	for i := range columns {
+		cells[i] = "---"
+	}
+	if err := writeLine(); err != nil {
+		return err
+	}
+
+	for i, row := range rows {
+		for j, col := range columns {
+			if col.Type == column.ColIndex {
+				cells[j] = strconv.Itoa(i + 1)
+			} else {
+				cells[j] = escapeMarkdownCell(row.Cells[col.Type])
+			}
+		}
+		if err := writeLine(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func escapeMarkdownCell(s string) string {
+	return strings.ReplaceAll(s, "|", `\|`)
+}
+
+// exportValue returns the raw value of a column for the row at position
+// (counting from 1): numbers, booleans, RFC 3339 dates and string lists
+// where the package has them, otherwise the cell text.
+func exportValue(row *Row, col column.Type, position int) any {
+	pkg := row.Package
+	if col == column.ColIndex {
+		return position
+	}
+	if pkg == nil {
+		return row.Cells[col]
+	}
+
+	switch col {
+	case column.ColSize:
+		return pkg.InstalledSize
+	case column.ColDeps, column.ColDependencyCount:
+		return pkg.DependencyCount
+	case column.ColInstallDate:
+		return formatExportTime(pkg.InstallDate)
+	case column.ColBuildDate:
+		return formatExportTime(pkg.BuildDate)
+	case column.ColInstalled:
+		return pkg.Installed
+	case column.ColIsOrphan:
+		return pkg.IsOrphan
+	case column.ColIsForeign:
+		return pkg.IsForeign
+	case column.ColHasUpdate:
+		return pkg.HasUpdate
+	case column.ColInstallReason:
+		return strings.ToLower(formatInstallReason(pkg.InstallReason))
+	case column.ColGroups:
+		return exportList(pkg.Groups)
+	case column.ColLicenses:
+		return exportList(pkg.Licenses)
+	case column.ColDependencies:
+		return exportList(pkg.Dependencies)
+	case column.ColConflicts:
+		return exportList(pkg.Conflicts)
+	case column.ColProvides:
+		return exportList(pkg.Provides)
+	case column.ColReplaces:
+		return exportList(pkg.Replaces)
+	case column.ColRequired:
+		return exportList(pkg.Required)
+	case column.ColUnsatisfied:
+		return exportList(pkg.UnsatisfiedDeps)
+	case column.ColMatchedFiles:
+		return exportList(pkg.MatchedFiles)
+	case column.ColPendingConfigs:
+		return exportList(pkg.PendingConfigs)
+	default:
+		return row.Cells[col]
+	}
+}
+
+// exportList keeps empty lists as [] rather than null in JSON.
+func exportList(list []string) []string {
+	if list == nil {
+		return []string{}
+	}
+	return list
+}
+
+func formatExportTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
@@ -0,0 +1,65 @@
+package domain
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/sjsanc/pacviz/v3/internal/ui/column"
+)
+
+func TestExportTable(t *testing.T) {
+	pkgs := []*Package{
+		{Name: "bash", Version: "5.2-1", InstalledSize: 9437184, InstallDate: time.Date(2024, 1, 10, 12, 0, 0, 0, time.UTC), Groups: []string{"base", "shells"}},
+		{Name: "pipe|name", Version: "1.0-1", Description: `says "hi", twice`},
+	}
+	rows := PackagesToRows(pkgs)
+	columns := []*column.Column{
+		{Type: column.ColIndex, Name: "#"},
+		{Type: column.ColName, Name: "Name"},
+		{Type: column.ColSize, Name: "Size"},
+		{Type: column.ColInstallDate, Name: "InstalledOn"},
+		{Type: column.ColGroups, Name: "Groups"},
+		{Type: column.ColDescription, Name: "Description"},
+	}
+
+	tests := []struct {
+		format TableFormat
+		want   string
+	}{
+		{TableCSV, `#,Name,Size,InstalledOn,Groups,Description
+1,bash,9437184,2024-01-10T12:00:00Z,"base, shells",
+2,pipe|name,0,,,"says ""hi"", twice"
+`},
+		{TableJSON, `[
+  {"index": 1, "name": "bash", "size": 9437184, "install_date": "2024-01-10T12:00:00Z", "groups": ["base","shells"], "description": ""},
+  {"index": 2, "name": "pipe|name", "size": 0, "install_date": "", "groups": [], "description": "says \"hi\", twice"}
+]
+`},
+		{TableMarkdown, `| # | Name | Size | InstalledOn | Groups | Description |
+| --- | --- | --- | --- | --- | --- |
+| 1 | bash | 9.0 MB | 2024-01-10 | base, shells |  |
+| 2 | pipe\|name | 0 B | 0001-01-01 |  | says "hi", twice |
+`},
+	}
+
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		if err := ExportTable(&buf, rows, columns, tt.format); err != nil {
+			t.Fatalf("%s: ExportTable() error = %v", tt.format, err)
+		}
+		if got := buf.String(); got != tt.want {
+			t.Errorf("%s: ExportTable() =\n%s\nwant\n%s", tt.format, got, tt.want)
+		}
+	}
+}
+
+func TestTableFormatFor(t *testing.T) {
+	tests := map[string]TableFormat{"audit.csv": TableCSV, "audit.JSON": TableJSON, "audit.md": TableMarkdown, "audit.markdown": TableMarkdown, "audit.txt": ""}
+	for path, want := range tests {
+		got, ok := TableFormatFor(path)
+		if got != want || ok != (want != "") {
+			t.Errorf("TableFormatFor(%q) = %q, %v; want %q", path, got, ok, want)
+		}
+	}
+}
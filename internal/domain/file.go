@@ -0,0 +1,201 @@
+package domain
+
+import (
+	"path"
+	"sort"
+	"strings"
+)
+
+// PackageFile is one entry of an installed package's file list.
+type PackageFile struct {
+	Path    string // absolute path; directories end in "/"
+	Size    int64  // size on disk; for directories, the total of the files below
+	IsDir   bool
+	Missing bool // listed in the local database but absent on disk
+}
+
+// FileOwner is a package owning a path matched by an :owns query.
+type FileOwner struct {
+	Query   string // the path or pattern as resolved for lookup
+	Path    string // the matching file list entry
+	Package string
+	Version string
+}
+
+// parentDir returns the directory containing p ("/usr/bin/bash" -> "/usr/bin/").
+func parentDir(p string) string {
+	dir := path.Dir(strings.TrimSuffix(p, "/"))
+	if dir == "/" {
+		return "/"
+	}
+	return dir + "/"
+}
+
+// FileNode is one visible line of a FileTree.
+type FileNode struct {
+	File        PackageFile
+	Name        string // last path element, with a trailing "/" for directories
+	Depth       int
+	Expanded    bool
+	HasChildren bool
+}
+
+// FileTree is a package's file list shown as collapsible directories.
+// Directories start expanded; Nodes holds the visible lines in order.
+type FileTree struct {
+	Package string
+	Filter  string
+	Nodes   []FileNode
+
+	files     []PackageFile
+	children  map[string][]int // directory path -> indices into files
+	collapsed map[string]bool
+}
+
+// NewFileTree builds the tree for pkg's files and sums directory sizes.
+func NewFileTree(pkg string, files []PackageFile) *FileTree {
+	sorted := append([]PackageFile(nil), files...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Path < sorted[j].Path })
+
+	t := &FileTree{
+		Package:   pkg,
+		files:     sorted,
+		children:  make(map[string][]int),
+		collapsed: make(map[string]bool),
+	}
+
+	index := make(map[string]int, len(sorted))
+	for i, file := range sorted {
+		index[file.Path] = i
+	}
+	for i, file := range sorted {
+		parent := parentDir(file.Path)
+		if _, ok := index[parent]; !ok {
+			parent = ""
+		}
+		t.children[parent] = append(t.children[parent], i)
+	}
+
+	for _, file := range sorted {
+		if file.IsDir {
+			continue
+		}
+		for dir := parentDir(file.Path); ; dir = parentDir(dir) {
+			if j, ok := index[dir]; ok {
+				t.files[j].Size += file.Size
+			}
+			if dir == "/" {
+				break
+			}
+		}
+	}
+
+	t.rebuild()
+	return t
+}
+
+// FileCount returns the number of non-directory entries.
+func (t *FileTree) FileCount() int {
+	count := 0
+	for _, file := range t.files {
+		if !file.IsDir {
+			count++
+		}
+	}
+	return count
+}
+
+func (t *FileTree) rebuild() {
+	t.Nodes = t.Nodes[:0]
+	filter := strings.ToLower(t.Filter)
+
+	var matches func(i int) bool
+	matches = func(i int) bool {
+		if strings.Contains(strings.ToLower(t.files[i].Path), filter) {
+			return true
+		}
+		for _, child := range t.children[t.files[i].Path] {
+			if matches(child) {
+				return true
+			}
+		}
+		return false
+	}
+
+	var walk func(parent string, depth int)
+	walk = func(parent string, depth int) {
+		for _, i := range t.children[parent] {
+			if filter != "" && !matches(i) {
+				continue
+			}
+
+			file := t.files[i]
+			hasChildren := len(t.children[file.Path]) > 0
+			expanded := hasChildren && (filter != "" || !t.collapsed[file.Path])
+
+			name := path.Base(strings.TrimSuffix(file.Path, "/"))
+			if file.IsDir {
+				name += "/"
+			}
+
+			t.Nodes = append(t.Nodes, FileNode{
+				File:        file,
+				Name:        name,
+				Depth:       depth,
+				Expanded:    expanded,
+				HasChildren: hasChildren,
+			})
+			if expanded {
+				walk(file.Path, depth+1)
+			}
+		}
+	}
+	walk("", 0)
+}
+
+// SetFilter shows only paths containing filter (case-insensitive) and the
+// directories leading to them. Collapsed directories open while filtering.
+func (t *FileTree) SetFilter(filter string) {
+	t.Filter = filter
+	t.rebuild()
+}
+
+// Expand opens the directory at index i. It reports whether anything changed.
+func (t *FileTree) Expand(i int) bool {
+	if i < 0 || i >= len(t.Nodes) || t.Nodes[i].Expanded || !t.Nodes[i].HasChildren {
+		return false
+	}
+	delete(t.collapsed, t.Nodes[i].File.Path)
+	t.rebuild()
+	return true
+}
+
+// Collapse closes the directory at index i. It reports whether anything changed.
+func (t *FileTree) Collapse(i int) bool {
+	if i < 0 || i >= len(t.Nodes) || !t.Nodes[i].Expanded || t.Filter != "" {
+		return false
+	}
+	t.collapsed[t.Nodes[i].File.Path] = true
+	t.rebuild()
+	return true
+}
+
+// Toggle expands or collapses the directory at index i.
+func (t *FileTree) Toggle(i int) {
+	if !t.Collapse(i) {
+		t.Expand(i)
+	}
+}
+
+// Parent returns the index of the node's directory, or -1 at the top level.
+func (t *FileTree) Parent(i int) int {
+	if i <= 0 || i >= len(t.Nodes) {
+		return -1
+	}
+	for j := i - 1; j >= 0; j-- {
+		if t.Nodes[j].Depth < t.Nodes[i].Depth {
+			return j
+		}
+	}
+	return -1
+}
@@ -0,0 +1,62 @@
+package domain
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func fileTreeFixture() []PackageFile {
+	return []PackageFile{
+		{Path: "/usr/bin/bash", Size: 1000},
+		{Path: "/usr/", IsDir: true},
+		{Path: "/usr/bin/", IsDir: true},
+		{Path: "/usr/bin/sh", Size: 4},
+		{Path: "/etc/", IsDir: true},
+		{Path: "/etc/bash.bashrc", Size: 200},
+	}
+}
+
+func fileLines(list *FileTree) []string {
+	lines := make([]string, len(list.Nodes))
+	for i, node := range list.Nodes {
+		lines[i] = strings.Repeat("  ", node.Depth) + node.Name
+	}
+	return lines
+}
+
+func TestFileTree(t *testing.T) {
+	list := NewFileTree("bash", fileTreeFixture())
+
+	want := []string{"etc/", "  bash.bashrc", "usr/", "  bin/", "    bash", "    sh"}
+	if got := fileLines(list); !reflect.DeepEqual(got, want) {
+		t.Fatalf("tree = %q, want %q", got, want)
+	}
+	if list.FileCount() != 3 {
+		t.Errorf("FileCount = %d, want 3", list.FileCount())
+	}
+	if size := list.Nodes[2].File.Size; size != 1004 {
+		t.Errorf("usr/ size = %d, want 1004", size)
+	}
+
+	if !list.Collapse(3) {
+		t.Fatal("expected bin/ to collapse")
+	}
+	if got := fileLines(list); !reflect.DeepEqual(got, []string{"etc/", "  bash.bashrc", "usr/", "  bin/"}) {
+		t.Errorf("collapsed tree = %q", got)
+	}
+	if parent := list.Parent(3); parent != 2 {
+		t.Errorf("Parent(3) = %d, want 2", parent)
+	}
+
+	list.SetFilter("SH")
+	want = []string{"etc/", "  bash.bashrc", "usr/", "  bin/", "    bash", "    sh"}
+	if got := fileLines(list); !reflect.DeepEqual(got, want) {
+		t.Errorf("filtered tree = %q, want %q", got, want)
+	}
+
+	list.SetFilter("bin/sh")
+	if got := fileLines(list); !reflect.DeepEqual(got, []string{"usr/", "  bin/", "    sh"}) {
+		t.Errorf("filtered tree = %q", got)
+	}
+}
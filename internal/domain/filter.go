@@ -6,8 +6,9 @@ import (
 
 type FilterState struct {
 	Active bool
-	Terms  []string
-	Column column.Type
+	Query  string      // the filter as typed
+	Terms  []string    // words, phrases and predicate values, see Query.Terms
+	Column column.Type // the column all predicates test, see Query.Column
 	Regex  bool
 }
 
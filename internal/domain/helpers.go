@@ -22,7 +22,7 @@ func PackageToRow(pkg *Package, index int) *Row {
 	row.Cells[column.ColRepo] = pkg.Repository
 	row.Cells[column.ColName] = pkg.Name
 	row.Cells[column.ColVersion] = pkg.Version
-	row.Cells[column.ColSize] = formatSize(pkg.InstalledSize)
+	row.Cells[column.ColSize] = FormatSize(pkg.InstalledSize)
 	row.Cells[column.ColInstallDate] = pkg.InstallDate.Format("2006-01-02")
 	if pkg.Installed {
 		row.Cells[column.ColInstalled] = "Yes"
@@ -39,6 +39,9 @@ func PackageToRow(pkg *Package, index int) *Row {
 	row.Cells[column.ColPackager] = pkg.Packager
 	row.Cells[column.ColBuildDate] = pkg.BuildDate.Format("2006-01-02")
 	row.Cells[column.ColDependencies] = strings.Join(pkg.Dependencies, ", ")
+	row.Cells[column.ColUnsatisfied] = strings.Join(pkg.UnsatisfiedDeps, ", ")
+	row.Cells[column.ColMatchedFiles] = strings.Join(pkg.MatchedFiles, ", ")
+	row.Cells[column.ColPendingConfigs] = strings.Join(pkg.PendingConfigs, ", ")
 	row.Cells[column.ColOptDepends] = formatOptDepends(pkg.OptDepends)
 	row.Cells[column.ColConflicts] = strings.Join(pkg.Conflicts, ", ")
 	row.Cells[column.ColProvides] = strings.Join(pkg.Provides, ", ")
@@ -46,6 +49,7 @@ func PackageToRow(pkg *Package, index int) *Row {
 	row.Cells[column.ColRequired] = strings.Join(pkg.Required, ", ")
 	row.Cells[column.ColInstallReason] = formatInstallReason(pkg.InstallReason)
 	row.Cells[column.ColIsOrphan] = formatBool(pkg.IsOrphan)
+	row.Cells[column.ColUnneeded] = formatUnneeded(pkg)
 	row.Cells[column.ColIsForeign] = formatBool(pkg.IsForeign)
 	row.Cells[column.ColHasUpdate] = formatBool(pkg.HasUpdate)
 	row.Cells[column.ColNewVersion] = pkg.NewVersion
@@ -53,7 +57,8 @@ func PackageToRow(pkg *Package, index int) *Row {
 	return row
 }
 
-func formatSize(bytes int64) string {
+// FormatSize formats a byte count with binary units (e.g. "1.5 MB").
+func FormatSize(bytes int64) string {
 	const unit = 1024
 	if bytes < unit {
 		return fmt.Sprintf("%d B", bytes)
@@ -92,6 +97,18 @@ func formatInstallReason(reason InstallReason) string {
 	}
 }
 
+// formatUnneeded distinguishes unneeded packages that are still an optional
+// dependency of something (kept by pacman -Qdt, listed by -Qdtt).
+func formatUnneeded(pkg *Package) string {
+	if !pkg.IsUnneeded {
+		return "No"
+	}
+	if len(pkg.OptionalFor) > 0 {
+		return "Optional"
+	}
+	return "Yes"
+}
+
 func formatBool(b bool) string {
 	if b {
 		return "Yes"
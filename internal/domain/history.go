@@ -0,0 +1,250 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// HistoryAction is what a transaction did to a package.
+type HistoryAction string
+
+const (
+	ActionInstalled   HistoryAction = "installed"
+	ActionUpgraded    HistoryAction = "upgraded"
+	ActionDowngraded  HistoryAction = "downgraded"
+	ActionRemoved     HistoryAction = "removed"
+	ActionReinstalled HistoryAction = "reinstalled"
+)
+
+// HistoryEvent is one package change recorded in pacman.log.
+type HistoryEvent struct {
+	Time       time.Time
+	Action     HistoryAction
+	Package    string
+	OldVersion string // version before the change, empty for installs
+	NewVersion string // version after the change, empty for removals
+}
+
+// Versions describes the change as "1.0-1 -> 1.1-1", or the single version
+// involved.
+func (e HistoryEvent) Versions() string {
+	switch {
+	case e.OldVersion == "":
+		return e.NewVersion
+	case e.NewVersion == "":
+		return e.OldVersion
+	default:
+		return e.OldVersion + " -> " + e.NewVersion
+	}
+}
+
+// String describes the event as "upgraded mesa (24.0.5-1 -> 24.1.2-1)".
+func (e HistoryEvent) String() string {
+	return fmt.Sprintf("%s %s (%s)", e.Action, e.Package, e.Versions())
+}
+
+// Transaction is a group of package changes made by one pacman run.
+type Transaction struct {
+	Start   time.Time
+	Command string // the command that ran it, e.g. "pacman -Syu", if logged
+	Events  []HistoryEvent
+}
+
+// Summary counts the transaction's events per action, as
+// "3 upgraded, 1 installed".
+func (t Transaction) Summary() string {
+	order := []HistoryAction{ActionInstalled, ActionUpgraded, ActionDowngraded, ActionReinstalled, ActionRemoved}
+	counts := make(map[HistoryAction]int)
+	for _, event := range t.Events {
+		counts[event.Action]++
+	}
+
+	var parts []string
+	for _, action := range order {
+		if counts[action] > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", counts[action], action))
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// HistoryByPackage groups the events by package name, newest first.
+// transactions must be oldest first, as the repository returns them.
+func HistoryByPackage(transactions []Transaction) map[string][]HistoryEvent {
+	events := make(map[string][]HistoryEvent)
+	for i := len(transactions) - 1; i >= 0; i-- {
+		tx := transactions[i]
+		for j := len(tx.Events) - 1; j >= 0; j-- {
+			event := tx.Events[j]
+			events[event.Package] = append(events[event.Package], event)
+		}
+	}
+	return events
+}
+
+// HistoryFilter narrows the history to some packages and a time range.
+// Zero Since and Until leave the range open.
+type HistoryFilter struct {
+	Packages []string // package name substrings, any of which may match
+	Since    time.Time
+	Until    time.Time // exclusive
+}
+
+// ParseHistoryFilter reads a filter of space-separated words. Dates
+// ("2024", "2024-01", "2024-01-10") select that period and ranges
+// ("2024-01..2024-03", "2024-05..", "..2023") span periods; any other word
+// matches package names. Dates are in loc.
+func ParseHistoryFilter(s string, loc *time.Location) HistoryFilter {
+	var f HistoryFilter
+	for _, word := range strings.Fields(strings.ToLower(s)) {
+		since, until, ok := parseDateRange(word, loc)
+		if !ok {
+			f.Packages = append(f.Packages, word)
+			continue
+		}
+		if !since.IsZero() && since.After(f.Since) {
+			f.Since = since
+		}
+		if !until.IsZero() && (f.Until.IsZero() || until.Before(f.Until)) {
+			f.Until = until
+		}
+	}
+	return f
+}
+
+// parseDateRange reads a date or an inclusive range of dates as a half-open
+// interval.
+func parseDateRange(word string, loc *time.Location) (since, until time.Time, ok bool) {
+	from, to, isRange := strings.Cut(word, "..")
+	if !isRange {
+		return parseDatePeriod(word, loc)
+	}
+	if from == "" && to == "" {
+		return time.Time{}, time.Time{}, false
+	}
+	if from != "" {
+		if since, _, ok = parseDatePeriod(from, loc); !ok {
+			return time.Time{}, time.Time{}, false
+		}
+	}
+	if to != "" {
+		if _, until, ok = parseDatePeriod(to, loc); !ok {
+			return time.Time{}, time.Time{}, false
+		}
+	}
+	return since, until, true
+}
+
+// parseDatePeriod reads a year, month or day as the interval it covers.
+func parseDatePeriod(s string, loc *time.Location) (start, end time.Time, ok bool) {
+	var layout string
+	var years, months, days int
+	switch len(s) {
+	case len("2006"):
+		layout, years = "2006", 1
+	case len("2006-01"):
+		layout, months = "2006-01", 1
+	case len("2006-01-02"):
+		layout, days = "2006-01-02", 1
+	default:
+		return time.Time{}, time.Time{}, false
+	}
+
+	t, err := time.ParseInLocation(layout, s, loc)
+	if err != nil {
+		return time.Time{}, time.Time{}, false
+	}
+	return t, t.AddDate(years, months, days), true
+}
+
+// Match reports whether the filter selects event.
+func (f HistoryFilter) Match(event HistoryEvent) bool {
+	if !f.Since.IsZero() && event.Time.Before(f.Since) {
+		return false
+	}
+	if !f.Until.IsZero() && !event.Time.Before(f.Until) {
+		return false
+	}
+	if len(f.Packages) == 0 {
+		return true
+	}
+	name := strings.ToLower(event.Package)
+	for _, pkg := range f.Packages {
+		if strings.Contains(name, pkg) {
+			return true
+		}
+	}
+	return false
+}
+
+// HistoryLine is one visible line of a HistoryList: a transaction header
+// when Event is -1, otherwise one of the transaction's events.
+type HistoryLine struct {
+	Transaction int
+	Event       int
+}
+
+// HistoryList is the package history, newest transaction first, optionally
+// filtered. Lines holds the visible lines in order; filtered transactions
+// show only their matching events.
+type HistoryList struct {
+	Transactions []Transaction
+	Filter       string
+	Lines        []HistoryLine
+}
+
+// NewHistoryList lists transactions newest first. transactions must be
+// oldest first, as the repository returns them.
+func NewHistoryList(transactions []Transaction) *HistoryList {
+	l := &HistoryList{Transactions: make([]Transaction, len(transactions))}
+	for i, tx := range transactions {
+		l.Transactions[len(transactions)-1-i] = tx
+	}
+	l.rebuild()
+	return l
+}
+
+// EventCount returns the number of events in the visible lines.
+func (l *HistoryList) EventCount() int {
+	count := 0
+	for _, line := range l.Lines {
+		if line.Event >= 0 {
+			count++
+		}
+	}
+	return count
+}
+
+func (l *HistoryList) rebuild() {
+	l.Lines = l.Lines[:0]
+	filter := ParseHistoryFilter(l.Filter, time.Local)
+	for t, tx := range l.Transactions {
+		header := len(l.Lines)
+		for e, event := range tx.Events {
+			if !filter.Match(event) {
+				continue
+			}
+			if len(l.Lines) == header {
+				l.Lines = append(l.Lines, HistoryLine{Transaction: t, Event: -1})
+			}
+			l.Lines = append(l.Lines, HistoryLine{Transaction: t, Event: e})
+		}
+	}
+}
+
+// SetFilter shows only the events matching filter, as read by
+// ParseHistoryFilter in the local time zone.
+func (l *HistoryList) SetFilter(filter string) {
+	l.Filter = filter
+	l.rebuild()
+}
+
+// Event returns the event on line i, or nil for a header line.
+func (l *HistoryList) Event(i int) *HistoryEvent {
+	if i < 0 || i >= len(l.Lines) || l.Lines[i].Event < 0 {
+		return nil
+	}
+	line := l.Lines[i]
+	return &l.Transactions[line.Transaction].Events[line.Event]
+}
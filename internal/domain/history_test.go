@@ -0,0 +1,71 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseHistoryFilter(t *testing.T) {
+	day := func(y int, m time.Month, d int) time.Time {
+		return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		filter   string
+		packages int
+		since    time.Time
+		until    time.Time
+	}{
+		{"mesa", 1, time.Time{}, time.Time{}},
+		{"2024", 0, day(2024, 1, 1), day(2025, 1, 1)},
+		{"Mesa 2024-02", 1, day(2024, 2, 1), day(2024, 3, 1)},
+		{"2024-01-10", 0, day(2024, 1, 10), day(2024, 1, 11)},
+		{"2024-01..2024-03", 0, day(2024, 1, 1), day(2024, 4, 1)},
+		{"2024-05..", 0, day(2024, 5, 1), time.Time{}},
+		{"..2023", 0, time.Time{}, day(2024, 1, 1)},
+		{"2024 2024-06", 0, day(2024, 6, 1), day(2024, 7, 1)},
+		{"lib32-mesa 2024-13 ..", 3, time.Time{}, time.Time{}},
+	}
+
+	for _, tt := range tests {
+		f := ParseHistoryFilter(tt.filter, time.UTC)
+		if len(f.Packages) != tt.packages || !f.Since.Equal(tt.since) || !f.Until.Equal(tt.until) {
+			t.Errorf("ParseHistoryFilter(%q) = %+v", tt.filter, f)
+		}
+	}
+}
+
+func TestHistoryList(t *testing.T) {
+	at := func(d int) time.Time { return time.Date(2024, 1, d, 12, 0, 0, 0, time.Local) }
+	transactions := []Transaction{
+		{Start: at(1), Command: "pacman -S mesa zstd", Events: []HistoryEvent{
+			{Time: at(1), Action: ActionInstalled, Package: "mesa", NewVersion: "24.0.5-1"},
+			{Time: at(1), Action: ActionInstalled, Package: "zstd", NewVersion: "1.5.6-1"},
+		}},
+		{Start: at(20), Command: "pacman -Syu", Events: []HistoryEvent{
+			{Time: at(20), Action: ActionUpgraded, Package: "mesa", OldVersion: "24.0.5-1", NewVersion: "24.1.2-1"},
+		}},
+	}
+
+	list := NewHistoryList(transactions)
+	if len(list.Lines) != 5 || list.Event(0) != nil || list.Event(1).Package != "mesa" || list.Event(1).Action != ActionUpgraded {
+		t.Fatalf("expected the newest transaction first, got %+v", list.Lines)
+	}
+	if got := list.Transactions[1].Summary(); got != "2 installed" {
+		t.Errorf("Summary() = %q", got)
+	}
+
+	list.SetFilter("zstd")
+	if len(list.Lines) != 2 || list.Event(1).Package != "zstd" || list.EventCount() != 1 {
+		t.Errorf("package filter: unexpected lines %+v", list.Lines)
+	}
+	list.SetFilter("mesa 2024-01-15..")
+	if len(list.Lines) != 2 || list.Event(1).Action != ActionUpgraded {
+		t.Errorf("date filter: unexpected lines %+v", list.Lines)
+	}
+
+	history := HistoryByPackage(transactions)["mesa"]
+	if len(history) != 2 || history[0].String() != "upgraded mesa (24.0.5-1 -> 24.1.2-1)" || history[1].String() != "installed mesa (24.0.5-1)" {
+		t.Errorf("HistoryByPackage()[mesa] = %+v", history)
+	}
+}
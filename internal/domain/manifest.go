@@ -0,0 +1,323 @@
+package domain
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// ManifestFormat is the file format of a package manifest.
+type ManifestFormat string
+
+const (
+	ManifestText ManifestFormat = "text"
+	ManifestTOML ManifestFormat = "toml"
+)
+
+// ManifestFormatFor picks the format of a manifest file from its extension:
+// TOML for ".toml", text otherwise.
+func ManifestFormatFor(path string) ManifestFormat {
+	if strings.EqualFold(filepath.Ext(path), ".toml") {
+		return ManifestTOML
+	}
+	return ManifestText
+}
+
+// ManifestEntry is an explicitly installed package, optionally pinned to the
+// version installed when the manifest was written.
+type ManifestEntry struct {
+	Name    string
+	Version string
+}
+
+// Manifest is the explicit package set of a machine, split into packages
+// from the sync repositories and foreign (AUR or locally built) packages.
+type Manifest struct {
+	Repo    []ManifestEntry
+	Foreign []ManifestEntry
+}
+
+// NewManifest lists the explicitly installed packages, sorted by name,
+// with their versions when withVersions is set.
+func NewManifest(installed []*Package, withVersions bool) Manifest {
+	var m Manifest
+	for _, pkg := range installed {
+		if pkg.InstallReason != ReasonExplicit {
+			continue
+		}
+		entry := ManifestEntry{Name: pkg.Name}
+		if withVersions {
+			entry.Version = pkg.Version
+		}
+		if pkg.IsForeign || pkg.IsAUR {
+			m.Foreign = append(m.Foreign, entry)
+		} else {
+			m.Repo = append(m.Repo, entry)
+		}
+	}
+	sortManifestEntries(m.Repo)
+	sortManifestEntries(m.Foreign)
+	return m
+}
+
+func sortManifestEntries(entries []ManifestEntry) {
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name < entries[j].Name })
+}
+
+// manifestTOML is the TOML manifest layout.
+type manifestTOML struct {
+	Repo     []string          `toml:"repo"`
+	Foreign  []string          `toml:"foreign"`
+	Versions map[string]string `toml:"versions,omitempty"`
+}
+
+// WriteManifest writes m in the given format. The text format has a
+// "[repo]" and a "[foreign]" section, each listing one package per line as
+// "name" or "name version", like pacman -Qe.
+func WriteManifest(w io.Writer, m Manifest, format ManifestFormat) error {
+	if format == ManifestTOML {
+		doc := manifestTOML{Repo: []string{}, Foreign: []string{}}
+		for _, section := range []struct {
+			entries []ManifestEntry
+			names   *[]string
+		}{{m.Repo, &doc.Repo}, {m.Foreign, &doc.Foreign}} {
+			for _, entry := range section.entries {
+				*section.names = append(*section.names, entry.Name)
+				if entry.Version != "" {
+					if doc.Versions == nil {
+						doc.Versions = make(map[string]string)
+					}
+					doc.Versions[entry.Name] = entry.Version
+				}
+			}
+		}
+		enc := toml.NewEncoder(w)
+		enc.Indent = ""
+		if err := enc.Encode(doc); err != nil {
+			return fmt.Errorf("failed to write manifest: %w", err)
+		}
+		return nil
+	}
+
+	bw := bufio.NewWriter(w)
+	fmt.Fprintln(bw, "# Explicitly installed packages, written by pacviz export")
+	for i, section := range []struct {
+		name    string
+		entries []ManifestEntry
+	}{{"repo", m.Repo}, {"foreign", m.Foreign}} {
+		if i > 0 {
+			fmt.Fprintln(bw)
+		}
+		fmt.Fprintf(bw, "[%s]\n", section.name)
+		for _, entry := range section.entries {
+			if entry.Version != "" {
+				fmt.Fprintf(bw, "%s %s\n", entry.Name, entry.Version)
+			} else {
+				fmt.Fprintln(bw, entry.Name)
+			}
+		}
+	}
+	if err := bw.Flush(); err != nil {
+		return fmt.Errorf("failed to write manifest: %w", err)
+	}
+	return nil
+}
+
+// ParseManifest reads a manifest written by WriteManifest. Text manifests
+// may contain comments; packages listed before any section header are
+// taken to be repository packages, so a plain pacman -Qqe list works too.
+func ParseManifest(r io.Reader, format ManifestFormat) (Manifest, error) {
+	var m Manifest
+
+	if format == ManifestTOML {
+		var doc manifestTOML
+		if _, err := toml.NewDecoder(r).Decode(&doc); err != nil {
+			return m, fmt.Errorf("failed to parse manifest: %w", err)
+		}
+		for _, name := range doc.Repo {
+			m.Repo = append(m.Repo, ManifestEntry{Name: name, Version: doc.Versions[name]})
+		}
+		for _, name := range doc.Foreign {
+			m.Foreign = append(m.Foreign, ManifestEntry{Name: name, Version: doc.Versions[name]})
+		}
+		sortManifestEntries(m.Repo)
+		sortManifestEntries(m.Foreign)
+		return m, nil
+	}
+
+	section := &m.Repo
+	scanner := bufio.NewScanner(r)
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := strings.TrimSpace(scanner.Text())
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = strings.TrimSpace(line[:i])
+		}
+		if line == "" {
+			continue
+		}
+
+		switch line {
+		case "[repo]":
+			section = &m.Repo
+			continue
+		case "[foreign]":
+			section = &m.Foreign
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) > 2 || strings.HasPrefix(line, "[") {
+			return m, fmt.Errorf("failed to parse manifest: line %d: unexpected %q", lineNo, line)
+		}
+		entry := ManifestEntry{Name: fields[0]}
+		if len(fields) == 2 {
+			entry.Version = fields[1]
+		}
+		*section = append(*section, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return m, fmt.Errorf("failed to read manifest: %w", err)
+	}
+
+	sortManifestEntries(m.Repo)
+	sortManifestEntries(m.Foreign)
+	return m, nil
+}
+
+// ManifestDiff compares a manifest with the installed packages.
+type ManifestDiff struct {
+	Missing  []ManifestMissing // in the manifest but not installed
+	Extra    []*Package        // explicitly installed but not in the manifest
+	Reason   []*Package        // in the manifest but installed as a dependency
+	Versions []ManifestVersion // pinned to another version than the installed one
+}
+
+// ManifestMissing is a manifest package that is not installed.
+type ManifestMissing struct {
+	ManifestEntry
+	Foreign bool
+}
+
+// ManifestVersion is an installed package whose version differs from the
+// one pinned in the manifest.
+type ManifestVersion struct {
+	Package *Package
+	Want    string
+}
+
+// DiffManifest works out what would make the installed explicit package
+// set match m. Every list is sorted by name.
+func DiffManifest(m Manifest, installed []*Package) ManifestDiff {
+	byName := make(map[string]*Package, len(installed))
+	for _, pkg := range installed {
+		byName[pkg.Name] = pkg
+	}
+
+	var diff ManifestDiff
+	listed := make(map[string]bool)
+	for _, section := range []struct {
+		entries []ManifestEntry
+		foreign bool
+	}{{m.Repo, false}, {m.Foreign, true}} {
+		for _, entry := range section.entries {
+			listed[entry.Name] = true
+			pkg, ok := byName[entry.Name]
+			if !ok {
+				diff.Missing = append(diff.Missing, ManifestMissing{ManifestEntry: entry, Foreign: section.foreign})
+				continue
+			}
+			if pkg.InstallReason != ReasonExplicit {
+				diff.Reason = append(diff.Reason, pkg)
+			}
+			if entry.Version != "" && entry.Version != pkg.Version {
+				diff.Versions = append(diff.Versions, ManifestVersion{Package: pkg, Want: entry.Version})
+			}
+		}
+	}
+
+	for _, pkg := range installed {
+		if pkg.InstallReason == ReasonExplicit && !listed[pkg.Name] {
+			diff.Extra = append(diff.Extra, pkg)
+		}
+	}
+
+	sort.Slice(diff.Missing, func(i, j int) bool { return diff.Missing[i].Name < diff.Missing[j].Name })
+	sort.Slice(diff.Extra, func(i, j int) bool { return diff.Extra[i].Name < diff.Extra[j].Name })
+	sort.Slice(diff.Reason, func(i, j int) bool { return diff.Reason[i].Name < diff.Reason[j].Name })
+	sort.Slice(diff.Versions, func(i, j int) bool { return diff.Versions[i].Package.Name < diff.Versions[j].Package.Name })
+	return diff
+}
+
+// Empty reports whether the system already matches the manifest.
+func (d ManifestDiff) Empty() bool {
+	return len(d.Missing) == 0 && len(d.Extra) == 0 && len(d.Reason) == 0 && len(d.Versions) == 0
+}
+
+// ManifestSection is one of a ManifestDiff's lists.
+type ManifestSection int
+
+const (
+	SectionMissing ManifestSection = iota
+	SectionExtra
+	SectionReason
+	SectionVersions
+)
+
+func (s ManifestSection) String() string {
+	switch s {
+	case SectionMissing:
+		return "Missing"
+	case SectionExtra:
+		return "Not in the manifest"
+	case SectionReason:
+		return "Installed as a dependency"
+	case SectionVersions:
+		return "Different version"
+	default:
+		return "Unknown"
+	}
+}
+
+// ManifestLine is one line of the import view: a section header when Index
+// is -1, otherwise one entry of the section.
+type ManifestLine struct {
+	Section ManifestSection
+	Index   int
+}
+
+// Len returns the number of entries in a section.
+func (d ManifestDiff) Len(section ManifestSection) int {
+	switch section {
+	case SectionMissing:
+		return len(d.Missing)
+	case SectionExtra:
+		return len(d.Extra)
+	case SectionReason:
+		return len(d.Reason)
+	case SectionVersions:
+		return len(d.Versions)
+	default:
+		return 0
+	}
+}
+
+// Lines lists every non-empty section as a header followed by its entries.
+func (d ManifestDiff) Lines() []ManifestLine {
+	var lines []ManifestLine
+	for section := SectionMissing; section <= SectionVersions; section++ {
+		n := d.Len(section)
+		if n == 0 {
+			continue
+		}
+		lines = append(lines, ManifestLine{Section: section, Index: -1})
+		for i := 0; i < n; i++ {
+			lines = append(lines, ManifestLine{Section: section, Index: i})
+		}
+	}
+	return lines
+}
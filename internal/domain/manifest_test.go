@@ -0,0 +1,124 @@
+package domain
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func manifestFixture() []*Package {
+	return []*Package{
+		{Name: "bash", Version: "5.2-1", InstallReason: ReasonExplicit},
+		{Name: "git", Version: "2.44-1", InstallReason: ReasonExplicit},
+		{Name: "glibc", Version: "2.39-1", InstallReason: ReasonDependency},
+		{Name: "yay-bin", Version: "12.3-1", InstallReason: ReasonExplicit, IsForeign: true},
+	}
+}
+
+func TestManifest_RoundTrip(t *testing.T) {
+	want := NewManifest(manifestFixture(), true)
+	if !reflect.DeepEqual(want, Manifest{
+		Repo:    []ManifestEntry{{"bash", "5.2-1"}, {"git", "2.44-1"}},
+		Foreign: []ManifestEntry{{"yay-bin", "12.3-1"}},
+	}) {
+		t.Fatalf("NewManifest() = %+v", want)
+	}
+
+	for _, format := range []ManifestFormat{ManifestText, ManifestTOML} {
+		var buf bytes.Buffer
+		if err := WriteManifest(&buf, want, format); err != nil {
+			t.Fatalf("%s: WriteManifest() error = %v", format, err)
+		}
+		got, err := ParseManifest(&buf, format)
+		if err != nil {
+			t.Fatalf("%s: ParseManifest() error = %v", format, err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: round trip = %+v, want %+v", format, got, want)
+		}
+	}
+}
+
+func TestParseManifest_Text(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    Manifest
+		wantErr bool
+	}{
+		{
+			name:  "plain package list",
+			input: "git\nbash\n",
+			want:  Manifest{Repo: []ManifestEntry{{Name: "bash"}, {Name: "git"}}},
+		},
+		{
+			name:  "sections and comments",
+			input: "# laptop\n[foreign]\nyay-bin # AUR helper\n\n[repo]\nbash 5.2-1\n",
+			want:  Manifest{Repo: []ManifestEntry{{"bash", "5.2-1"}}, Foreign: []ManifestEntry{{Name: "yay-bin"}}},
+		},
+		{
+			name:    "unknown section",
+			input:   "[extra]\nbash\n",
+			wantErr: true,
+		},
+		{
+			name:    "too many fields",
+			input:   "bash 5.2-1 x86_64\n",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseManifest(strings.NewReader(tt.input), ManifestText)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseManifest() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseManifest() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDiffManifest(t *testing.T) {
+	manifest := Manifest{
+		Repo:    []ManifestEntry{{"bash", "5.1-1"}, {Name: "glibc"}, {Name: "vim"}},
+		Foreign: []ManifestEntry{{Name: "paru"}},
+	}
+
+	diff := DiffManifest(manifest, manifestFixture())
+
+	var missing, extra, reason []string
+	for _, m := range diff.Missing {
+		missing = append(missing, m.Name)
+	}
+	for _, pkg := range diff.Extra {
+		extra = append(extra, pkg.Name)
+	}
+	for _, pkg := range diff.Reason {
+		reason = append(reason, pkg.Name)
+	}
+
+	if !reflect.DeepEqual(missing, []string{"paru", "vim"}) || !diff.Missing[0].Foreign || diff.Missing[1].Foreign {
+		t.Errorf("Missing = %+v, want the foreign paru and vim", diff.Missing)
+	}
+	if !reflect.DeepEqual(extra, []string{"git", "yay-bin"}) {
+		t.Errorf("Extra = %v, want git and yay-bin", extra)
+	}
+	if !reflect.DeepEqual(reason, []string{"glibc"}) {
+		t.Errorf("Reason = %v, want glibc", reason)
+	}
+	if len(diff.Versions) != 1 || diff.Versions[0].Package.Name != "bash" || diff.Versions[0].Want != "5.1-1" {
+		t.Errorf("Versions = %+v, want bash pinned to 5.1-1", diff.Versions)
+	}
+
+	lines := diff.Lines()
+	if len(lines) != 10 || lines[0] != (ManifestLine{SectionMissing, -1}) || lines[3] != (ManifestLine{SectionExtra, -1}) {
+		t.Errorf("Lines() = %+v", lines)
+	}
+	if !DiffManifest(NewManifest(manifestFixture(), false), manifestFixture()).Empty() {
+		t.Error("expected a system to match its own manifest")
+	}
+}
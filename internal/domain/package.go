@@ -30,6 +30,7 @@ type Package struct {
 	InstallDate   time.Time
 	InstallReason InstallReason
 	InstalledSize int64
+	DownloadSize  int64 // compressed archive size, sync packages only
 	Packager      string
 	BuildDate     time.Time
 
@@ -38,6 +39,11 @@ type Package struct {
 	DependencyCount int      // number of packages depending on this
 	Repository      string   // repository name (e.g., "core", "extra", "AUR", "foreign")
 	IsOrphan        bool
+	IsUnneeded      bool     // dependency no explicit package needs, even indirectly
+	OptionalFor     []string // packages listing this as an optional dependency
+	UnsatisfiedDeps []string // dependencies no installed package satisfies
+	MatchedFiles    []string // paths matching a file search, sync results only
+	PendingConfigs  []string // .pacnew and .pacsave files next to the package's backup files
 	IsForeign       bool
 	IsAUR           bool
 	HasUpdate       bool
@@ -7,6 +7,9 @@ const (
 	PresetExplicit   PresetType = "explicit"
 	PresetDependency PresetType = "dependency"
 	PresetOrphans    PresetType = "orphans"
+	PresetUnneeded   PresetType = "unneeded"
+	PresetBroken     PresetType = "broken"
+	PresetPacnew     PresetType = "pacnew"
 	PresetForeign    PresetType = "foreign"
 	PresetAUR        PresetType = "aur"
 	PresetUpdatable  PresetType = "updatable"
@@ -48,6 +51,30 @@ func DefaultPresets() []Preset {
 				return p.IsOrphan
 			},
 		},
+		{
+			Type:        PresetUnneeded,
+			Name:        "Unneeded",
+			Description: "Dependencies no explicit package needs, even indirectly",
+			Filter: func(p *Package) bool {
+				return p.IsUnneeded
+			},
+		},
+		{
+			Type:        PresetBroken,
+			Name:        "Broken",
+			Description: "Packages with missing or unsatisfied dependencies",
+			Filter: func(p *Package) bool {
+				return len(p.UnsatisfiedDeps) > 0
+			},
+		},
+		{
+			Type:        PresetPacnew,
+			Name:        "Pacnew",
+			Description: "Packages with .pacnew or .pacsave files to merge",
+			Filter: func(p *Package) bool {
+				return len(p.PendingConfigs) > 0
+			},
+		},
 		{
 			Type:        PresetForeign,
 			Name:        "Foreign",
@@ -0,0 +1,518 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sjsanc/pacviz/v3/internal/ui/column"
+)
+
+// Query is a parsed package filter. See ParseQuery for the syntax.
+type Query struct {
+	root queryNode
+
+	// Terms lists the words and phrases searched for and the values of the
+	// field predicates, in the order given.
+	Terms []string
+
+	// Column is the column every predicate tests, when they all test the
+	// same one. It is empty when words search the name and description.
+	Column column.Type
+}
+
+// Match reports whether a row's package satisfies the query. The empty
+// query matches everything.
+func (q Query) Match(row *Row) bool {
+	return q.root == nil || q.root.match(row)
+}
+
+// ParseQuery reads a filter as typed after "/". Words and "quoted phrases"
+// match the name or description. field:value matches a field containing
+// value, field=value and field!=value compare it whole, and numeric and
+// date fields take <, <=, > and >= too, so "size>50M", "deps=0" and
+// "installed<2024-01-01" work. Dates are a year, month or day, or a range
+// such as "installed:2024-01..2024-03". Terms are combined with and (also
+// implied between terms), or and not, and grouped with parentheses. Dates
+// are in loc.
+func ParseQuery(s string, loc *time.Location) (Query, error) {
+	tokens, err := lexQuery(s)
+	if err != nil {
+		return Query{}, err
+	}
+	if len(tokens) == 0 {
+		return Query{}, nil
+	}
+
+	p := &queryParser{tokens: tokens, loc: loc, columns: make(map[column.Type]bool)}
+	root, err := p.parseOr()
+	if err != nil {
+		return Query{}, err
+	}
+	if p.pos < len(p.tokens) {
+		return Query{}, fmt.Errorf("unexpected %s", p.tokens[p.pos])
+	}
+
+	q := Query{root: root, Terms: p.terms}
+	if !p.words && len(p.columns) == 1 {
+		for col := range p.columns {
+			q.Column = col
+		}
+	}
+	return q, nil
+}
+
+type queryTokenKind int
+
+const (
+	tokenWord queryTokenKind = iota
+	tokenPredicate
+	tokenAnd
+	tokenOr
+	tokenNot
+	tokenOpen
+	tokenClose
+)
+
+type queryToken struct {
+	kind  queryTokenKind
+	text  string // the word, phrase or predicate value
+	field string
+	op    string
+	raw   string // as typed, for errors
+}
+
+func (t queryToken) String() string {
+	return strconv.Quote(t.raw)
+}
+
+// queryOps are the predicate operators, longest first.
+var queryOps = []string{"!=", "<=", ">=", ":", "=", "<", ">"}
+
+// lexQuery splits a query into tokens. Quotes make a phrase, or the value
+// of a predicate when they follow its operator.
+func lexQuery(s string) ([]queryToken, error) {
+	var tokens []queryToken
+	for i := 0; i < len(s); {
+		switch c := s[i]; {
+		case c == ' ' || c == '\t':
+			i++
+		case c == '(':
+			tokens = append(tokens, queryToken{kind: tokenOpen, raw: "("})
+			i++
+		case c == ')':
+			tokens = append(tokens, queryToken{kind: tokenClose, raw: ")"})
+			i++
+		case c == '"':
+			phrase, n, err := lexPhrase(s[i:])
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, queryToken{kind: tokenWord, text: phrase, raw: s[i : i+n]})
+			i += n
+		default:
+			start := i
+			for i < len(s) && !strings.ContainsRune(" \t()\"", rune(s[i])) {
+				i++
+			}
+			word := s[start:i]
+
+			field, op, value, ok := splitPredicate(word)
+			if ok && value == "" && i < len(s) && s[i] == '"' {
+				phrase, n, err := lexPhrase(s[i:])
+				if err != nil {
+					return nil, err
+				}
+				value = phrase
+				i += n
+			}
+
+			token := queryToken{kind: tokenWord, text: word, raw: s[start:i]}
+			switch {
+			case ok:
+				token.kind, token.field, token.op, token.text = tokenPredicate, field, op, value
+			case strings.EqualFold(word, "and"):
+				token.kind = tokenAnd
+			case strings.EqualFold(word, "or"):
+				token.kind = tokenOr
+			case strings.EqualFold(word, "not"):
+				token.kind = tokenNot
+			}
+			tokens = append(tokens, token)
+		}
+	}
+	return tokens, nil
+}
+
+// lexPhrase reads the quoted phrase s starts with, returning it and the
+// number of bytes read.
+func lexPhrase(s string) (string, int, error) {
+	end := strings.IndexByte(s[1:], '"')
+	if end < 0 {
+		return "", 0, errors.New("missing closing quote")
+	}
+	return s[1 : end+1], end + 2, nil
+}
+
+// splitPredicate splits "field<op>value". The field must be letters, so
+// words such as "1:2.0" or "c++" stay plain words.
+func splitPredicate(word string) (field, op, value string, ok bool) {
+	i := strings.IndexAny(word, ":=<>!")
+	if i <= 0 {
+		return "", "", "", false
+	}
+	for _, r := range word[:i] {
+		if (r < 'a' || r > 'z') && (r < 'A' || r > 'Z') {
+			return "", "", "", false
+		}
+	}
+	for _, op := range queryOps {
+		if strings.HasPrefix(word[i:], op) {
+			return strings.ToLower(word[:i]), op, word[i+len(op):], true
+		}
+	}
+	return "", "", "", false
+}
+
+type queryParser struct {
+	tokens  []queryToken
+	pos     int
+	loc     *time.Location
+	terms   []string
+	columns map[column.Type]bool
+	words   bool // whether any plain word or phrase was given
+}
+
+func (p *queryParser) peek() (queryToken, bool) {
+	if p.pos >= len(p.tokens) {
+		return queryToken{}, false
+	}
+	return p.tokens[p.pos], true
+}
+
+// parseOr reads terms joined by or.
+func (p *queryParser) parseOr() (queryNode, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		token, ok := p.peek()
+		if !ok || token.kind != tokenOr {
+			return left, nil
+		}
+		p.pos++
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = orNode{left, right}
+	}
+}
+
+// parseAnd reads terms joined by and, or simply following each other.
+func (p *queryParser) parseAnd() (queryNode, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		token, ok := p.peek()
+		if !ok || token.kind == tokenOr || token.kind == tokenClose {
+			return left, nil
+		}
+		if token.kind == tokenAnd {
+			p.pos++
+		}
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = andNode{left, right}
+	}
+}
+
+func (p *queryParser) parseUnary() (queryNode, error) {
+	token, ok := p.peek()
+	if !ok {
+		return nil, fmt.Errorf("expected a term after %s", p.tokens[p.pos-1])
+	}
+	p.pos++
+
+	switch token.kind {
+	case tokenNot:
+		node, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return notNode{node}, nil
+	case tokenOpen:
+		node, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if next, ok := p.peek(); !ok || next.kind != tokenClose {
+			return nil, errors.New("missing )")
+		}
+		p.pos++
+		return node, nil
+	case tokenWord:
+		p.words = true
+		p.terms = append(p.terms, token.text)
+		return wordNode(strings.ToLower(token.text)), nil
+	case tokenPredicate:
+		return p.parsePredicate(token)
+	default:
+		return nil, fmt.Errorf("unexpected %s", token)
+	}
+}
+
+func (p *queryParser) parsePredicate(token queryToken) (queryNode, error) {
+	field, ok := queryFields[token.field]
+	if !ok {
+		return nil, fmt.Errorf("unknown field %q", token.field)
+	}
+	if token.text == "" {
+		return nil, fmt.Errorf("%s needs a value", token)
+	}
+	p.terms = append(p.terms, token.text)
+	p.columns[field.column] = true
+
+	op := token.op
+	if op == ":" && field.kind != fieldText && field.kind != fieldList {
+		op = "="
+	}
+
+	switch field.kind {
+	case fieldText, fieldList:
+		if op != ":" && op != "=" && op != "!=" {
+			return nil, fmt.Errorf("%s cannot be compared with %s", token.field, op)
+		}
+		return textPredicate{field: field, op: op, value: strings.ToLower(token.text)}, nil
+	case fieldSize, fieldCount:
+		parse := parseSize
+		if field.kind == fieldCount {
+			parse = parseCount
+		}
+		n, err := parse(token.text)
+		if err != nil {
+			return nil, err
+		}
+		return numberPredicate{field: field, op: op, value: n}, nil
+	case fieldDate:
+		var since, until time.Time
+		var ok bool
+		if op == "=" || op == "!=" {
+			since, until, ok = parseDateRange(token.text, p.loc)
+		} else {
+			since, until, ok = parseDatePeriod(token.text, p.loc)
+		}
+		if !ok {
+			return nil, fmt.Errorf("invalid date %q (use 2024, 2024-01 or 2024-01-10)", token.text)
+		}
+		return datePredicate{field: field, op: op, since: since, until: until}, nil
+	default:
+		return nil, fmt.Errorf("unknown field %q", token.field)
+	}
+}
+
+// parseSize reads a size in bytes with an optional binary unit, as shown in
+// the Size column: "512", "50M", "1.5GB" or "10KiB".
+func parseSize(s string) (int64, error) {
+	number := strings.TrimRight(strings.ToLower(s), "ib")
+	multiplier := 1.0
+	if n := len(number); n > 0 {
+		if i := strings.IndexByte("kmgt", number[n-1]); i >= 0 {
+			number = number[:n-1]
+			for ; i >= 0; i-- {
+				multiplier *= 1024
+			}
+		}
+	}
+
+	value, err := strconv.ParseFloat(number, 64)
+	if err != nil || value < 0 {
+		return 0, fmt.Errorf("invalid size %q (use e.g. 512K, 50M or 1.5G)", s)
+	}
+	return int64(value * multiplier), nil
+}
+
+func parseCount(s string) (int64, error) {
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid number %q", s)
+	}
+	return n, nil
+}
+
+type queryFieldKind int
+
+const (
+	fieldText queryFieldKind = iota
+	fieldList
+	fieldSize
+	fieldCount
+	fieldDate
+)
+
+// queryField is a package field a predicate can test. Only the accessor
+// matching kind is set.
+type queryField struct {
+	column column.Type
+	kind   queryFieldKind
+	text   func(*Package) string
+	list   func(*Package) []string
+	number func(*Package) int64
+	date   func(*Package) time.Time
+}
+
+var queryFields = func() map[string]queryField {
+	name := queryField{column: column.ColName, kind: fieldText, text: func(p *Package) string { return p.Name }}
+	desc := queryField{column: column.ColDescription, kind: fieldText, text: func(p *Package) string { return p.Description }}
+	groups := queryField{column: column.ColGroups, kind: fieldList, list: func(p *Package) []string { return p.Groups }}
+	licenses := queryField{column: column.ColLicenses, kind: fieldList, list: func(p *Package) []string { return p.Licenses }}
+
+	return map[string]queryField{
+		"name":        name,
+		"desc":        desc,
+		"description": desc,
+		"repo":        {column: column.ColRepo, kind: fieldText, text: func(p *Package) string { return p.Repository }},
+		"version":     {column: column.ColVersion, kind: fieldText, text: func(p *Package) string { return p.Version }},
+		"arch":        {column: column.ColArchitecture, kind: fieldText, text: func(p *Package) string { return p.Architecture }},
+		"url":         {column: column.ColURL, kind: fieldText, text: func(p *Package) string { return p.URL }},
+		"packager":    {column: column.ColPackager, kind: fieldText, text: func(p *Package) string { return p.Packager }},
+		"reason":      {column: column.ColInstallReason, kind: fieldText, text: func(p *Package) string { return formatInstallReason(p.InstallReason) }},
+		"group":       groups,
+		"groups":      groups,
+		"license":     licenses,
+		"licenses":    licenses,
+		"depends":     {column: column.ColDependencies, kind: fieldList, list: func(p *Package) []string { return p.Dependencies }},
+		"required":    {column: column.ColRequired, kind: fieldList, list: func(p *Package) []string { return p.Required }},
+		"provides":    {column: column.ColProvides, kind: fieldList, list: func(p *Package) []string { return p.Provides }},
+		"conflicts":   {column: column.ColConflicts, kind: fieldList, list: func(p *Package) []string { return p.Conflicts }},
+		"size":        {column: column.ColSize, kind: fieldSize, number: func(p *Package) int64 { return p.InstalledSize }},
+		"deps":        {column: column.ColDeps, kind: fieldCount, number: func(p *Package) int64 { return int64(p.DependencyCount) }},
+		"installed":   {column: column.ColInstallDate, kind: fieldDate, date: func(p *Package) time.Time { return p.InstallDate }},
+		"built":       {column: column.ColBuildDate, kind: fieldDate, date: func(p *Package) time.Time { return p.BuildDate }},
+	}
+}()
+
+type queryNode interface {
+	match(row *Row) bool
+}
+
+type andNode struct{ left, right queryNode }
+
+func (n andNode) match(row *Row) bool { return n.left.match(row) && n.right.match(row) }
+
+type orNode struct{ left, right queryNode }
+
+func (n orNode) match(row *Row) bool { return n.left.match(row) || n.right.match(row) }
+
+type notNode struct{ node queryNode }
+
+func (n notNode) match(row *Row) bool { return !n.node.match(row) }
+
+// wordNode matches the name or description, like the plain filter.
+type wordNode string
+
+func (n wordNode) match(row *Row) bool {
+	return strings.Contains(strings.ToLower(row.Cells[column.ColName]), string(n)) ||
+		strings.Contains(strings.ToLower(row.Cells[column.ColDescription]), string(n))
+}
+
+// textPredicate matches text fields, and lists when any element matches.
+type textPredicate struct {
+	field queryField
+	op    string
+	value string // lower case
+}
+
+func (n textPredicate) match(row *Row) bool {
+	if row.Package == nil {
+		return false
+	}
+	var values []string
+	if n.field.kind == fieldList {
+		values = n.field.list(row.Package)
+	} else {
+		values = []string{n.field.text(row.Package)}
+	}
+
+	found := false
+	for _, v := range values {
+		v = strings.ToLower(v)
+		if (n.op == ":" && strings.Contains(v, n.value)) || (n.op != ":" && v == n.value) {
+			found = true
+			break
+		}
+	}
+	return found != (n.op == "!=")
+}
+
+type numberPredicate struct {
+	field queryField
+	op    string
+	value int64
+}
+
+func (n numberPredicate) match(row *Row) bool {
+	if row.Package == nil {
+		return false
+	}
+	v := n.field.number(row.Package)
+	switch n.op {
+	case "=":
+		return v == n.value
+	case "!=":
+		return v != n.value
+	case "<":
+		return v < n.value
+	case "<=":
+		return v <= n.value
+	case ">":
+		return v > n.value
+	case ">=":
+		return v >= n.value
+	default:
+		return false
+	}
+}
+
+// datePredicate compares a date with the period [since, until). Either end
+// is open when zero.
+type datePredicate struct {
+	field        queryField
+	op           string
+	since, until time.Time
+}
+
+func (n datePredicate) match(row *Row) bool {
+	if row.Package == nil {
+		return false
+	}
+	t := n.field.date(row.Package)
+	if t.IsZero() {
+		return false
+	}
+
+	within := (n.since.IsZero() || !t.Before(n.since)) && (n.until.IsZero() || t.Before(n.until))
+	switch n.op {
+	case "=":
+		return within
+	case "!=":
+		return !within
+	case "<":
+		return t.Before(n.since)
+	case "<=":
+		return t.Before(n.until)
+	case ">":
+		return !t.Before(n.until)
+	case ">=":
+		return !t.Before(n.since)
+	default:
+		return false
+	}
+}
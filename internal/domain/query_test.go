@@ -0,0 +1,120 @@
+package domain
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sjsanc/pacviz/v3/internal/ui/column"
+)
+
+func TestParseQuery_Match(t *testing.T) {
+	day := func(y int, m time.Month, d int) time.Time {
+		return time.Date(y, m, d, 12, 0, 0, 0, time.UTC)
+	}
+	packages := PackagesToRows([]*Package{
+		{Name: "firefox", Description: "Standalone web browser", Repository: "extra", InstallReason: ReasonExplicit,
+			Licenses: []string{"MPL-2.0"}, InstalledSize: 250 << 20, InstallDate: day(2024, 3, 5)},
+		{Name: "xorg-server", Description: "Xorg X server", Repository: "extra", InstallReason: ReasonExplicit,
+			Groups: []string{"xorg"}, Licenses: []string{"custom"}, InstalledSize: 4 << 20, InstallDate: day(2023, 11, 20), DependencyCount: 1},
+		{Name: "glibc", Description: "GNU C Library", Repository: "core", InstallReason: ReasonDependency,
+			Licenses: []string{"GPL-2.0-or-later", "LGPL-2.1-or-later"}, InstalledSize: 46 << 20, InstallDate: day(2023, 6, 1), DependencyCount: 12},
+		{Name: "gnu-free-fonts", Description: "A free family of scalable outline fonts", Repository: "extra", InstallReason: ReasonDependency,
+			Licenses: []string{"GPL-3.0-or-later"}, InstalledSize: 6 << 20, InstallDate: day(2024, 1, 15)},
+	})
+
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"", []string{"firefox", "xorg-server", "glibc", "gnu-free-fonts"}},
+		{"X", []string{"firefox", "xorg-server"}},
+		{"repo:extra", []string{"firefox", "xorg-server", "gnu-free-fonts"}},
+		{"reason:dep", []string{"glibc", "gnu-free-fonts"}},
+		{"group:xorg", []string{"xorg-server"}},
+		{"license:GPL", []string{"glibc", "gnu-free-fonts"}},
+		{"license=custom", []string{"xorg-server"}},
+		{"size>50M", []string{"firefox"}},
+		{"size<=6MiB", []string{"xorg-server", "gnu-free-fonts"}},
+		{"installed<2024-01-01", []string{"xorg-server", "glibc"}},
+		{"installed>=2024", []string{"firefox", "gnu-free-fonts"}},
+		{"installed:2023-06..2023-12", []string{"xorg-server", "glibc"}},
+		{"installed!=2024-03-05", []string{"xorg-server", "glibc", "gnu-free-fonts"}},
+		{"deps=0", []string{"firefox", "gnu-free-fonts"}},
+		{"deps>1", []string{"glibc"}},
+		{"repo:extra reason:dep", []string{"gnu-free-fonts"}},
+		{"repo:core OR group:xorg", []string{"xorg-server", "glibc"}},
+		{"not repo:extra", []string{"glibc"}},
+		{"repo:extra and not (reason:explicit or size<5M)", []string{"gnu-free-fonts"}},
+		{`"web browser"`, []string{"firefox"}},
+		{`desc:"x server"`, []string{"xorg-server"}},
+		{`"gnu c" or fonts`, []string{"glibc", "gnu-free-fonts"}},
+	}
+
+	for _, tt := range tests {
+		q, err := ParseQuery(tt.query, time.UTC)
+		if err != nil {
+			t.Errorf("ParseQuery(%q) failed: %v", tt.query, err)
+			continue
+		}
+		var got []string
+		for _, row := range packages {
+			if q.Match(row) {
+				got = append(got, row.Package.Name)
+			}
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseQuery(%q) matched %v, want %v", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestParseQuery_Errors(t *testing.T) {
+	tests := []struct {
+		query string
+		err   string
+	}{
+		{"rpo:extra", `unknown field "rpo"`},
+		{"size>", `"size>" needs a value`},
+		{"size>50Q", `invalid size "50Q"`},
+		{"deps=many", `invalid number "many"`},
+		{"installed<2024-13", `invalid date "2024-13"`},
+		{"name>foo", "name cannot be compared with >"},
+		{`"web browser`, "missing closing quote"},
+		{"(repo:core", "missing )"},
+		{"repo:core)", `unexpected ")"`},
+		{"firefox and", `expected a term after "and"`},
+		{"or glibc", `unexpected "or"`},
+	}
+
+	for _, tt := range tests {
+		_, err := ParseQuery(tt.query, time.UTC)
+		if err == nil || !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("ParseQuery(%q) error = %v, want %q", tt.query, err, tt.err)
+		}
+	}
+}
+
+func TestParseQuery_TermsAndColumn(t *testing.T) {
+	tests := []struct {
+		query  string
+		terms  []string
+		column column.Type
+	}{
+		{"firefox", []string{"firefox"}, ""},
+		{"repo:core or repo:extra", []string{"core", "extra"}, column.ColRepo},
+		{`group:xorg "x server"`, []string{"xorg", "x server"}, ""},
+		{"size>50M deps=0", []string{"50M", "0"}, ""},
+	}
+
+	for _, tt := range tests {
+		q, err := ParseQuery(tt.query, time.UTC)
+		if err != nil {
+			t.Fatalf("ParseQuery(%q) failed: %v", tt.query, err)
+		}
+		if !reflect.DeepEqual(q.Terms, tt.terms) || q.Column != tt.column {
+			t.Errorf("ParseQuery(%q) = terms %q, column %q", tt.query, q.Terms, q.Column)
+		}
+	}
+}
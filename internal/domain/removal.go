@@ -0,0 +1,130 @@
+package domain
+
+import (
+	"fmt"
+	"sort"
+)
+
+// RemoveOptions mirrors the pacman -R modifiers.
+type RemoveOptions struct {
+	Recursive bool // -s: also remove dependencies no other package requires
+	Cascade   bool // -c: also remove packages that depend on the targets
+	NoSave    bool // -n: do not keep .pacsave backups of modified config files
+}
+
+// Flags returns the pacman command-line flags for the options.
+func (o RemoveOptions) Flags() []string {
+	var flags []string
+	if o.Recursive {
+		flags = append(flags, "--recursive")
+	}
+	if o.Cascade {
+		flags = append(flags, "--cascade")
+	}
+	if o.NoSave {
+		flags = append(flags, "--nosave")
+	}
+	return flags
+}
+
+// BrokenDependency is a removed package that remaining packages still require.
+type BrokenDependency struct {
+	Name       string
+	RequiredBy []string
+}
+
+// RemovalPlan describes everything a removal would do.
+type RemovalPlan struct {
+	Options   RemoveOptions
+	Targets   []string
+	Removed   []*Package // targets plus cascaded and recursive removals, sorted by name
+	Broken    []BrokenDependency
+	FreedSize int64
+}
+
+// PlanRemoval works out which installed packages removing targets takes
+// with it, following pacman's -c and -s rules, and which remaining
+// packages would be left with a missing dependency. installed must have
+// Required populated.
+func PlanRemoval(installed []*Package, targets []string, opts RemoveOptions) (*RemovalPlan, error) {
+	byName := make(map[string]*Package, len(installed))
+	for _, pkg := range installed {
+		byName[pkg.Name] = pkg
+	}
+
+	removing := make(map[string]bool, len(targets))
+	for _, name := range targets {
+		if _, ok := byName[name]; !ok {
+			return nil, fmt.Errorf("target not found: %s", name)
+		}
+		removing[name] = true
+	}
+
+	for changed := true; changed; {
+		changed = false
+
+		if opts.Cascade {
+			for name := range removing {
+				for _, dependent := range byName[name].Required {
+					if _, ok := byName[dependent]; ok && !removing[dependent] {
+						removing[dependent] = true
+						changed = true
+					}
+				}
+			}
+		}
+
+		if opts.Recursive {
+			// A package is a dependency of the removal set exactly when one
+			// of its Required entries is being removed, so walking Required
+			// follows provides the same way the dependency graph did.
+			for _, dep := range installed {
+				if removing[dep.Name] || dep.InstallReason == ReasonExplicit || len(dep.Required) == 0 {
+					continue
+				}
+				if requiredOnlyBy(dep, removing) {
+					removing[dep.Name] = true
+					changed = true
+				}
+			}
+		}
+	}
+
+	plan := &RemovalPlan{
+		Options: opts,
+		Targets: targets,
+		Removed: make([]*Package, 0, len(removing)),
+	}
+
+	for name := range removing {
+		pkg := byName[name]
+		plan.Removed = append(plan.Removed, pkg)
+		plan.FreedSize += pkg.InstalledSize
+
+		var requiredBy []string
+		for _, dependent := range pkg.Required {
+			if !removing[dependent] {
+				requiredBy = append(requiredBy, dependent)
+			}
+		}
+		if len(requiredBy) > 0 {
+			sort.Strings(requiredBy)
+			plan.Broken = append(plan.Broken, BrokenDependency{Name: name, RequiredBy: requiredBy})
+		}
+	}
+
+	sort.Slice(plan.Removed, func(i, j int) bool { return plan.Removed[i].Name < plan.Removed[j].Name })
+	sort.Slice(plan.Broken, func(i, j int) bool { return plan.Broken[i].Name < plan.Broken[j].Name })
+
+	return plan, nil
+}
+
+// requiredOnlyBy reports whether every package requiring pkg is in set.
+func requiredOnlyBy(pkg *Package, set map[string]bool) bool {
+	for _, dependent := range pkg.Required {
+		if !set[dependent] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,95 @@
+package domain
+
+import (
+	"reflect"
+	"testing"
+)
+
+// removalFixture: app -> libapp -> libcommon <- tool (explicit), libapp -> libextra
+func removalFixture() []*Package {
+	return []*Package{
+		{Name: "app", InstallReason: ReasonExplicit, Dependencies: []string{"libapp"}, InstalledSize: 100},
+		{Name: "libapp", InstallReason: ReasonDependency, Dependencies: []string{"libcommon", "libextra"}, Required: []string{"app"}, InstalledSize: 10},
+		{Name: "libcommon", InstallReason: ReasonDependency, Required: []string{"libapp", "tool"}, InstalledSize: 1},
+		{Name: "libextra", InstallReason: ReasonDependency, Required: []string{"libapp"}, InstalledSize: 2},
+		{Name: "tool", InstallReason: ReasonExplicit, Dependencies: []string{"libcommon"}},
+	}
+}
+
+func removedNames(plan *RemovalPlan) []string {
+	names := make([]string, len(plan.Removed))
+	for i, pkg := range plan.Removed {
+		names[i] = pkg.Name
+	}
+	return names
+}
+
+func TestPlanRemoval(t *testing.T) {
+	tests := []struct {
+		name        string
+		targets     []string
+		opts        RemoveOptions
+		wantRemoved []string
+		wantBroken  []BrokenDependency
+		wantFreed   int64
+	}{
+		{
+			name:        "plain removal",
+			targets:     []string{"app"},
+			wantRemoved: []string{"app"},
+			wantFreed:   100,
+		},
+		{
+			name:        "recursive keeps shared dependencies",
+			targets:     []string{"app"},
+			opts:        RemoveOptions{Recursive: true},
+			wantRemoved: []string{"app", "libapp", "libextra"},
+			wantFreed:   112,
+		},
+		{
+			name:        "required package breaks dependents",
+			targets:     []string{"libcommon"},
+			wantRemoved: []string{"libcommon"},
+			wantBroken:  []BrokenDependency{{Name: "libcommon", RequiredBy: []string{"libapp", "tool"}}},
+			wantFreed:   1,
+		},
+		{
+			name:        "cascade removes dependents",
+			targets:     []string{"libapp"},
+			opts:        RemoveOptions{Cascade: true},
+			wantRemoved: []string{"app", "libapp"},
+			wantFreed:   110,
+		},
+		{
+			name:        "cascade and recursive",
+			targets:     []string{"libcommon"},
+			opts:        RemoveOptions{Cascade: true, Recursive: true},
+			wantRemoved: []string{"app", "libapp", "libcommon", "libextra", "tool"},
+			wantFreed:   113,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan, err := PlanRemoval(removalFixture(), tt.targets, tt.opts)
+			if err != nil {
+				t.Fatalf("PlanRemoval: %v", err)
+			}
+			if got := removedNames(plan); !reflect.DeepEqual(got, tt.wantRemoved) {
+				t.Errorf("Removed = %v, want %v", got, tt.wantRemoved)
+			}
+			if !reflect.DeepEqual(plan.Broken, tt.wantBroken) {
+				t.Errorf("Broken = %v, want %v", plan.Broken, tt.wantBroken)
+			}
+			if plan.FreedSize != tt.wantFreed {
+				t.Errorf("FreedSize = %d, want %d", plan.FreedSize, tt.wantFreed)
+			}
+		})
+	}
+}
+
+func TestPlanRemoval_UnknownTarget(t *testing.T) {
+	if _, err := PlanRemoval(removalFixture(), []string{"missing"}, RemoveOptions{}); err == nil {
+		t.Error("expected an error for a target that is not installed")
+	}
+}
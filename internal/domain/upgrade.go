@@ -0,0 +1,19 @@
+package domain
+
+// Upgrade describes an installed package with a newer version in a sync database.
+type Upgrade struct {
+	Name         string
+	Repository   string
+	OldVersion   string
+	NewVersion   string
+	DownloadSize int64
+}
+
+// TotalDownloadSize sums the download sizes of a set of upgrades.
+func TotalDownloadSize(upgrades []Upgrade) int64 {
+	var total int64
+	for _, u := range upgrades {
+		total += u.DownloadSize
+	}
+	return total
+}
@@ -0,0 +1,26 @@
+package domain
+
+import "fmt"
+
+// VerifyIssue is a file that no longer matches what its package installed.
+type VerifyIssue struct {
+	Path    string
+	Problem string // e.g. "Missing file" or "SHA256 checksum mismatch"
+}
+
+// VerifyResult is the outcome of checking one package's files against its
+// mtree data, like pacman -Qkk.
+type VerifyResult struct {
+	Package string
+	Files   int // entries checked
+	Issues  []VerifyIssue
+}
+
+// Lines describes each issue as "bash: /etc/bash.bashrc (Size mismatch)".
+func (r VerifyResult) Lines() []string {
+	lines := make([]string, len(r.Issues))
+	for i, issue := range r.Issues {
+		lines[i] = fmt.Sprintf("%s: %s (%s)", r.Package, issue.Path, issue.Problem)
+	}
+	return lines
+}
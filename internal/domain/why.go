@@ -0,0 +1,63 @@
+package domain
+
+import (
+	"sort"
+	"strings"
+)
+
+// WhyInstalled explains why the named package is installed: for every
+// explicitly installed package that transitively requires it, the shortest
+// chain of dependencies from that package down to name. Chains are ordered
+// shortest first, then by name. installed must have Required populated.
+func WhyInstalled(installed []*Package, name string) [][]string {
+	byName := make(map[string]*Package, len(installed))
+	for _, pkg := range installed {
+		byName[pkg.Name] = pkg
+	}
+
+	target, ok := byName[name]
+	if !ok {
+		return nil
+	}
+
+	// next records, for each package reached, the hop back towards name.
+	// Breadth-first order guarantees the first path found is a shortest one.
+	next := map[string]string{name: ""}
+	queue := []*Package{target}
+	var chains [][]string
+
+	for len(queue) > 0 {
+		pkg := queue[0]
+		queue = queue[1:]
+
+		for _, dependent := range pkg.Required {
+			dep, ok := byName[dependent]
+			if _, seen := next[dependent]; seen || !ok {
+				continue
+			}
+			next[dependent] = pkg.Name
+			queue = append(queue, dep)
+
+			if dep.InstallReason == ReasonExplicit {
+				var chain []string
+				for hop := dependent; hop != ""; hop = next[hop] {
+					chain = append(chain, hop)
+				}
+				chains = append(chains, chain)
+			}
+		}
+	}
+
+	sort.SliceStable(chains, func(i, j int) bool {
+		if len(chains[i]) != len(chains[j]) {
+			return len(chains[i]) < len(chains[j])
+		}
+		return chains[i][0] < chains[j][0]
+	})
+	return chains
+}
+
+// FormatChain renders a dependency chain as "firefox → gtk3 → dbus".
+func FormatChain(chain []string) string {
+	return strings.Join(chain, " → ")
+}
@@ -0,0 +1,47 @@
+package domain
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestWhyInstalled(t *testing.T) {
+	// firefox -> gtk3 -> at-spi2-core -> dbus, systemd -> dbus, gtk3 also via app -> firefox
+	installed := []*Package{
+		{Name: "firefox", InstallReason: ReasonExplicit, Required: []string{"app"}},
+		{Name: "app", InstallReason: ReasonExplicit},
+		{Name: "gtk3", InstallReason: ReasonDependency, Required: []string{"firefox"}},
+		{Name: "at-spi2-core", InstallReason: ReasonDependency, Required: []string{"gtk3"}},
+		{Name: "systemd", InstallReason: ReasonExplicit},
+		{Name: "dbus", InstallReason: ReasonDependency, Required: []string{"at-spi2-core", "systemd"}},
+		{Name: "orphan", InstallReason: ReasonDependency},
+	}
+
+	tests := []struct {
+		name string
+		want [][]string
+	}{
+		{
+			name: "dbus",
+			want: [][]string{
+				{"systemd", "dbus"},
+				{"firefox", "gtk3", "at-spi2-core", "dbus"},
+				{"app", "firefox", "gtk3", "at-spi2-core", "dbus"},
+			},
+		},
+		{name: "orphan", want: nil},
+		{name: "missing", want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := WhyInstalled(installed, tt.name); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("WhyInstalled(%q) = %q, want %q", tt.name, got, tt.want)
+			}
+		})
+	}
+
+	if got := FormatChain([]string{"firefox", "gtk3", "dbus"}); got != "firefox → gtk3 → dbus" {
+		t.Errorf("FormatChain = %q", got)
+	}
+}
@@ -1,71 +1,169 @@
 package repository
 
 import (
-	"bytes"
+	"context"
+	"errors"
 	"fmt"
+	"net/http"
 	"os"
-	"os/exec"
+	"path/filepath"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/Jguer/go-alpm/v2"
 	"github.com/Morganamilo/go-pacmanconf"
 	"github.com/sjsanc/pacviz/v3/internal/domain"
 )
 
+// DefaultConfigPath is the pacman.conf used when Options.ConfigPath is empty.
+const DefaultConfigPath = "/etc/pacman.conf"
+
+// Options selects which pacman configuration and databases AlpmRepository opens.
+// Empty RootDir and DBPath fall back to the values in pacman.conf.
+type Options struct {
+	ConfigPath string
+	RootDir    string
+	DBPath     string
+	Mirror     string // Mirror URL used by SyncDatabases instead of the pacman.conf servers
+	SyncDBPath string // Private dbpath for SyncDatabases (default: user cache dir)
+	CacheDir   string // Package cache directory (overrides CacheDir in pacman.conf)
+	LogFile    string // pacman log read by History (overrides LogFile in pacman.conf)
+}
+
 type AlpmRepository struct {
+	opts    Options
 	handle  *alpm.Handle
 	localDB alpm.IDB
 	syncDBs alpm.IDBList
+
+	dbPath    string     // system dbpath holding the local database
+	repos     []syncRepo // sync repositories from pacman.conf
+	arch      string
+	cacheDirs []string
+	logFile   string
+	history   pacmanLogCache
+
+	// syncedDBPath is the private dbpath the sync databases are read from
+	// after SyncDatabases has run.
+	syncedDBPath string
+}
+
+func NewAlpmRepository(opts Options) (*AlpmRepository, error) {
+	r := &AlpmRepository{opts: opts}
+	if err := r.open(); err != nil {
+		return nil, err
+	}
+	return r, nil
 }
 
-func NewAlpmRepository() (*AlpmRepository, error) {
-	pacmanConf, _, err := pacmanconf.ParseFile("/etc/pacman.conf")
+// open parses pacman.conf and initializes the ALPM handle with its databases.
+func (r *AlpmRepository) open() error {
+	configPath := r.opts.ConfigPath
+	if configPath == "" {
+		configPath = DefaultConfigPath
+	}
+
+	pacmanConf, _, err := pacmanconf.ParseFile(configPath)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse pacman.conf: %w", err)
+		return fmt.Errorf("failed to parse pacman.conf: %w", err)
+	}
+
+	rootDir := pacmanConf.RootDir
+	if r.opts.RootDir != "" {
+		rootDir = r.opts.RootDir
+	}
+	dbPath := pacmanConf.DBPath
+	if r.opts.DBPath != "" {
+		dbPath = r.opts.DBPath
 	}
 
-	handle, err := alpm.Initialize(pacmanConf.RootDir, pacmanConf.DBPath)
+	r.dbPath = dbPath
+	r.repos = make([]syncRepo, 0, len(pacmanConf.Repos))
+	for _, repo := range pacmanConf.Repos {
+		r.repos = append(r.repos, syncRepo{Name: repo.Name, Servers: repo.Servers})
+	}
+	r.cacheDirs = pacmanConf.CacheDir
+	if r.opts.CacheDir != "" {
+		r.cacheDirs = []string{r.opts.CacheDir}
+	}
+	if len(r.cacheDirs) == 0 {
+		r.cacheDirs = []string{DefaultCacheDir}
+	}
+	r.logFile = pacmanConf.LogFile
+	if r.opts.LogFile != "" {
+		r.logFile = r.opts.LogFile
+	}
+	if r.logFile == "" {
+		r.logFile = DefaultLogFile
+	}
+
+	r.arch = hostArch()
+	if len(pacmanConf.Architecture) > 0 && pacmanConf.Architecture[0] != "auto" {
+		r.arch = pacmanConf.Architecture[0]
+	}
+
+	// After a sync the handle reads from the private dbpath, whose local
+	// directory links back to the system database.
+	if r.syncedDBPath != "" {
+		dbPath = r.syncedDBPath
+	}
+
+	handle, err := alpm.Initialize(rootDir, dbPath)
 	if err != nil {
-		return nil, fmt.Errorf("failed to initialize ALPM: %w", err)
+		return fmt.Errorf("failed to initialize ALPM: %w", err)
 	}
 
 	localDB, err := handle.LocalDB()
 	if err != nil {
 		handle.Release()
-		return nil, fmt.Errorf("failed to get local database: %w", err)
+		return fmt.Errorf("failed to get local database: %w", err)
 	}
 
 	for _, repo := range pacmanConf.Repos {
 		_, err := handle.RegisterSyncDB(repo.Name, 0)
 		if err != nil {
 			handle.Release()
-			return nil, fmt.Errorf("failed to register sync database %s: %w", repo.Name, err)
+			return fmt.Errorf("failed to register sync database %s: %w", repo.Name, err)
 		}
 	}
 
 	syncDBs, err := handle.SyncDBs()
 	if err != nil {
 		handle.Release()
-		return nil, fmt.Errorf("failed to get sync databases: %w", err)
+		return fmt.Errorf("failed to get sync databases: %w", err)
 	}
 
-	return &AlpmRepository{
-		handle:  handle,
-		localDB: localDB,
-		syncDBs: syncDBs,
-	}, nil
+	r.handle = handle
+	r.localDB = localDB
+	r.syncDBs = syncDBs
+
+	return nil
 }
 
 func (r *AlpmRepository) GetInstalled() ([]*domain.Package, error) {
+	root, err := r.handle.Root()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get root directory: %w", err)
+	}
+
 	pkgs := r.localDB.PkgCache()
 	result := make([]*domain.Package, 0)
 
 	pkgs.ForEach(func(pkg alpm.IPackage) error {
-		result = append(result, r.convertPackage(pkg))
+		p := r.convertPackage(pkg)
+		var backups []string
+		for _, backup := range pkg.Backup().Slice() {
+			backups = append(backups, backup.Name)
+		}
+		p.PendingConfigs = pendingConfigs(root, backups)
+		result = append(result, p)
 		return nil
 	})
 
-	r.computeOrphans(result)
+	computeOrphans(result)
+	computeUnneeded(result)
+	computeBroken(result)
 	r.computeForeign(result)
 
 	return result, nil
@@ -77,12 +175,6 @@ func (r *AlpmRepository) convertPackage(pkg alpm.IPackage) *domain.Package {
 		installReason = domain.ReasonExplicit
 	}
 
-	deps := make([]string, 0)
-	pkg.Depends().ForEach(func(dep *alpm.Depend) error {
-		deps = append(deps, dep.Name)
-		return nil
-	})
-
 	return &domain.Package{
 		Name:          pkg.Name(),
 		Version:       pkg.Version(),
@@ -91,7 +183,11 @@ func (r *AlpmRepository) convertPackage(pkg alpm.IPackage) *domain.Package {
 		URL:           pkg.URL(),
 		Licenses:      pkg.Licenses().Slice(),
 		Groups:        pkg.Groups().Slice(),
-		Dependencies:  deps,
+		Dependencies:  dependStrings(pkg.Depends()),
+		OptDepends:    optDepends(pkg.OptionalDepends()),
+		Conflicts:     dependStrings(pkg.Conflicts()),
+		Provides:      dependStrings(pkg.Provides()),
+		Replaces:      dependStrings(pkg.Replaces()),
 		Installed:     true,
 		InstallDate:   pkg.InstallDate(),
 		InstallReason: installReason,
@@ -101,27 +197,24 @@ func (r *AlpmRepository) convertPackage(pkg alpm.IPackage) *domain.Package {
 	}
 }
 
-// computeOrphans marks dependency packages that have no reverse dependents as orphans.
-func (r *AlpmRepository) computeOrphans(packages []*domain.Package) {
-	pkgMap := make(map[string]*domain.Package)
-	for _, pkg := range packages {
-		pkgMap[pkg.Name] = pkg
-	}
-
-	reverseDeps := make(map[string][]string)
-	for _, pkg := range packages {
-		for _, dep := range pkg.Dependencies {
-			reverseDeps[dep] = append(reverseDeps[dep], pkg.Name)
-		}
-	}
+// dependStrings formats a dependency list as strings such as "glibc>=2.38".
+func dependStrings(list alpm.IDependList) []string {
+	result := make([]string, 0)
+	list.ForEach(func(dep *alpm.Depend) error {
+		result = append(result, dep.String())
+		return nil
+	})
+	return result
+}
 
-	for _, pkg := range packages {
-		if pkg.InstallReason == domain.ReasonDependency {
-			pkg.IsOrphan = len(reverseDeps[pkg.Name]) == 0
-		}
-		pkg.Required = reverseDeps[pkg.Name]
-		pkg.DependencyCount = len(reverseDeps[pkg.Name])
-	}
+// optDepends maps each optional dependency's name to its description.
+func optDepends(list alpm.IDependList) map[string]string {
+	result := make(map[string]string)
+	list.ForEach(func(dep *alpm.Depend) error {
+		result[dep.Name] = dep.Description
+		return nil
+	})
+	return result
 }
 
 // computeForeign marks packages not in any sync database as foreign
@@ -139,6 +232,11 @@ func (r *AlpmRepository) computeForeign(packages []*domain.Package) {
 		return nil
 	})
 
+	markForeign(packages, pkgToRepo, pkgToVersion)
+}
+
+// markForeign applies sync database membership and versions to installed packages.
+func markForeign(packages []*domain.Package, pkgToRepo, pkgToVersion map[string]string) {
 	for _, pkg := range packages {
 		if repo, exists := pkgToRepo[pkg.Name]; exists {
 			pkg.Repository = repo
@@ -180,15 +278,6 @@ func (r *AlpmRepository) Search(query string) ([]*domain.Package, error) {
 }
 
 func (r *AlpmRepository) convertSyncPackage(pkg alpm.IPackage, repoName string) *domain.Package {
-	deps := make([]string, 0)
-	pkg.Depends().ForEach(func(dep *alpm.Depend) error {
-		deps = append(deps, dep.Name)
-		return nil
-	})
-
-	localPkg := r.localDB.Pkg(pkg.Name())
-	installed := localPkg != nil
-
 	p := &domain.Package{
 		Name:          pkg.Name(),
 		Version:       pkg.Version(),
@@ -197,135 +286,373 @@ func (r *AlpmRepository) convertSyncPackage(pkg alpm.IPackage, repoName string)
 		URL:           pkg.URL(),
 		Licenses:      pkg.Licenses().Slice(),
 		Groups:        pkg.Groups().Slice(),
-		Dependencies:  deps,
-		Installed:     installed,
+		Dependencies:  dependStrings(pkg.Depends()),
+		OptDepends:    optDepends(pkg.OptionalDepends()),
+		Conflicts:     dependStrings(pkg.Conflicts()),
+		Provides:      dependStrings(pkg.Provides()),
+		Replaces:      dependStrings(pkg.Replaces()),
 		InstalledSize: pkg.ISize(),
+		DownloadSize:  pkg.Size(),
 		Packager:      pkg.Packager(),
 		BuildDate:     pkg.BuildDate(),
 		Repository:    repoName,
 		IsForeign:     false,
 	}
+	r.markLocal(p)
 
-	if localPkg != nil {
-		p.InstallDate = localPkg.InstallDate()
-		if localPkg.Reason() == alpm.PkgReasonExplicit {
-			p.InstallReason = domain.ReasonExplicit
-		} else {
-			p.InstallReason = domain.ReasonDependency
-		}
+	return p
+}
+
+// markLocal copies the install state of the local package named like the
+// sync package p, if one is installed.
+func (r *AlpmRepository) markLocal(p *domain.Package) {
+	localPkg := r.localDB.Pkg(p.Name)
+	if localPkg == nil {
+		return
 	}
 
-	return p
+	p.Installed = true
+	p.InstallDate = localPkg.InstallDate()
+	if localPkg.Reason() == alpm.PkgReasonExplicit {
+		p.InstallReason = domain.ReasonExplicit
+	} else {
+		p.InstallReason = domain.ReasonDependency
+	}
 }
 
-func (r *AlpmRepository) Install(names []string, password string) (string, error) {
-	if len(names) == 0 {
-		return "", fmt.Errorf("no packages specified for installation")
+// SearchFiles searches the repos' .files databases, preferring the copies
+// SyncFileDatabases downloaded over the system's (pacman -Fy).
+func (r *AlpmRepository) SearchFiles(query string) ([]*domain.Package, error) {
+	dirs := []string{filepath.Join(r.dbPath, "sync")}
+	if private, err := r.privateDBPath(); err == nil {
+		dirs = append([]string{filepath.Join(private, "sync")}, dirs...)
 	}
 
-	args := []string{"-S", "--noconfirm"}
-	args = append(args, names...)
+	q := newFileQuery(query)
+	result := make([]*domain.Package, 0)
+	found := false
+	for _, repo := range r.repos {
+		path := filesDBPath(dirs, repo.Name)
+		if path == "" {
+			continue
+		}
+		found = true
 
-	if os.Geteuid() == 0 {
-		cmd := exec.Command("pacman", args...)
-		output, err := cmd.CombinedOutput()
+		pkgs, err := searchFilesDBFile(path, q)
 		if err != nil {
-			return string(output), fmt.Errorf("failed to install packages: %w\n%s", err, string(output))
+			return nil, err
 		}
-		return string(output), nil
+		for _, pkg := range pkgs {
+			pkg.Repository = repo.Name
+			r.markLocal(pkg)
+		}
+		result = append(result, pkgs...)
 	}
 
-	cmd := exec.Command("sudo", append([]string{"-S", "pacman"}, args...)...)
+	if !found {
+		return nil, errNoFileDBs
+	}
+	return result, nil
+}
 
-	var stdin bytes.Buffer
-	stdin.WriteString(password + "\n")
-	cmd.Stdin = &stdin
+func (r *AlpmRepository) Install(names []string, password string, out chan<- string) error {
+	if len(names) == 0 {
+		return fmt.Errorf("no packages specified for installation")
+	}
 
-	output, err := cmd.CombinedOutput()
-	if err != nil {
-		return string(output), fmt.Errorf("failed to install packages: %w\n%s", err, string(output))
+	args := []string{"-S", "--noconfirm"}
+	args = append(args, names...)
+
+	if err := runStreaming(pacmanCommand(r.opts, args, password), out); err != nil {
+		return fmt.Errorf("failed to install packages: %w", err)
 	}
 
-	return string(output), nil
+	return nil
 }
 
-func (r *AlpmRepository) Remove(names []string, cascade bool, password string) (string, error) {
+func (r *AlpmRepository) Remove(names []string, opts domain.RemoveOptions, password string, out chan<- string) error {
 	if len(names) == 0 {
-		return "", fmt.Errorf("no packages specified for removal")
+		return fmt.Errorf("no packages specified for removal")
 	}
 
 	args := []string{"-R", "--noconfirm"}
+	args = append(args, opts.Flags()...)
+	args = append(args, names...)
 
-	if cascade {
-		args = append(args, "--cascade")
+	if err := runStreaming(pacmanCommand(r.opts, args, password), out); err != nil {
+		return fmt.Errorf("failed to remove packages: %w", err)
 	}
 
+	return nil
+}
+
+func (r *AlpmRepository) SetInstallReason(names []string, reason domain.InstallReason, password string, out chan<- string) error {
+	if len(names) == 0 {
+		return fmt.Errorf("no packages specified")
+	}
+
+	args := []string{"-D", "--asexplicit"}
+	if reason == domain.ReasonDependency {
+		args = []string{"-D", "--asdeps"}
+	}
 	args = append(args, names...)
 
-	if os.Geteuid() == 0 {
-		cmd := exec.Command("pacman", args...)
-		output, err := cmd.CombinedOutput()
-		if err != nil {
-			return string(output), fmt.Errorf("failed to remove packages: %w", err)
+	if err := runStreaming(pacmanCommand(r.opts, args, password), out); err != nil {
+		return fmt.Errorf("failed to set install reason: %w", err)
+	}
+
+	return nil
+}
+
+func (r *AlpmRepository) PendingUpgrades() ([]domain.Upgrade, error) {
+	upgrades := make([]domain.Upgrade, 0)
+
+	r.localDB.PkgCache().ForEach(func(pkg alpm.IPackage) error {
+		newPkg := pkg.SyncNewVersion(r.syncDBs)
+		if newPkg == nil {
+			return nil
 		}
-		return string(output), nil
+		upgrades = append(upgrades, domain.Upgrade{
+			Name:         pkg.Name(),
+			Repository:   newPkg.DB().Name(),
+			OldVersion:   pkg.Version(),
+			NewVersion:   newPkg.Version(),
+			DownloadSize: newPkg.Size(),
+		})
+		return nil
+	})
+
+	sort.Slice(upgrades, func(i, j int) bool { return upgrades[i].Name < upgrades[j].Name })
+
+	return upgrades, nil
+}
+
+// Upgrade installs the upgrades PendingUpgrades previewed. That preview reads
+// the system's sync databases, so pacman must not refresh them first (-Su).
+// After SyncDatabases it read private copies pacman cannot use, so pacman
+// refreshes the system's with -Syu, from pacman.conf's servers rather than
+// Options.Mirror; the app warns that versions may then differ.
+func (r *AlpmRepository) Upgrade(password string, out chan<- string) error {
+	args := []string{"-Su", "--noconfirm"}
+	if r.syncedDBPath != "" {
+		args[0] = "-Syu"
 	}
 
-	cmd := exec.Command("sudo", append([]string{"-S", "pacman"}, args...)...)
+	if err := runStreaming(pacmanCommand(r.opts, args, password), out); err != nil {
+		return fmt.Errorf("failed to upgrade system: %w", err)
+	}
 
-	var stdin bytes.Buffer
-	stdin.WriteString(password + "\n")
-	cmd.Stdin = &stdin
+	return nil
+}
 
-	output, err := cmd.CombinedOutput()
+// SyncDatabases downloads fresh copies of the sync databases into a private
+// dbpath, without root, and reopens the handle against them.
+func (r *AlpmRepository) SyncDatabases(out chan<- string) error {
+	dbPath, err := r.downloadPrivate(".db", out)
 	if err != nil {
-		return string(output), fmt.Errorf("failed to remove packages: %w", err)
+		return fmt.Errorf("failed to sync databases: %w", err)
 	}
 
-	return string(output), nil
+	r.syncedDBPath = dbPath
+	return r.Refresh()
 }
 
-// Refresh reinitializes the ALPM handle to reflect database changes.
-func (r *AlpmRepository) Refresh() error {
-	if r.handle != nil {
-		r.handle.Release()
+// SyncFileDatabases downloads the repos' .files databases into the private
+// dbpath for SearchFiles. The package databases are left untouched.
+func (r *AlpmRepository) SyncFileDatabases(out chan<- string) error {
+	if _, err := r.downloadPrivate(".files", out); err != nil {
+		return fmt.Errorf("failed to sync file databases: %w", err)
 	}
+	return nil
+}
 
-	pacmanConf, _, err := pacmanconf.ParseFile("/etc/pacman.conf")
-	if err != nil {
-		return fmt.Errorf("failed to parse pacman.conf: %w", err)
+// privateDBPath returns the dbpath unprivileged syncs download into.
+func (r *AlpmRepository) privateDBPath() (string, error) {
+	if r.opts.SyncDBPath != "" {
+		return r.opts.SyncDBPath, nil
 	}
+	return defaultSyncDBPath()
+}
 
-	handle, err := alpm.Initialize(pacmanConf.RootDir, pacmanConf.DBPath)
+// downloadPrivate fetches every repo's <repo><ext> into the private dbpath,
+// from Options.Mirror when set, and returns that dbpath.
+func (r *AlpmRepository) downloadPrivate(ext string, out chan<- string) (string, error) {
+	dbPath, err := r.privateDBPath()
 	if err != nil {
-		return fmt.Errorf("failed to initialize ALPM: %w", err)
+		return "", err
 	}
 
-	localDB, err := handle.LocalDB()
+	if err := prepareSyncDBPath(dbPath, filepath.Join(r.dbPath, "local")); err != nil {
+		return "", err
+	}
+
+	repos := r.repos
+	if r.opts.Mirror != "" {
+		repos = make([]syncRepo, len(r.repos))
+		for i, repo := range r.repos {
+			repos[i] = syncRepo{
+				Name:    repo.Name,
+				Servers: []string{expandMirror(r.opts.Mirror, repo.Name, r.arch)},
+			}
+		}
+	}
+
+	timeout := 60 * time.Second
+	if ext == ".files" {
+		timeout = 10 * time.Minute // file databases run to tens of megabytes
+	}
+
+	client := &http.Client{Timeout: timeout}
+	if err := downloadSyncDBs(client, filepath.Join(dbPath, "sync"), repos, ext, out); err != nil {
+		return "", err
+	}
+	return dbPath, nil
+}
+
+func (r *AlpmRepository) Files(name string) ([]domain.PackageFile, error) {
+	pkg := r.localDB.Pkg(name)
+	if pkg == nil {
+		return nil, fmt.Errorf("package not found: %s", name)
+	}
+
+	root, err := r.handle.Root()
 	if err != nil {
-		handle.Release()
-		return fmt.Errorf("failed to get local database: %w", err)
+		return nil, fmt.Errorf("failed to get root directory: %w", err)
 	}
 
-	for _, repo := range pacmanConf.Repos {
-		_, err := handle.RegisterSyncDB(repo.Name, 0)
-		if err != nil {
-			handle.Release()
-			return fmt.Errorf("failed to register sync database %s: %w", repo.Name, err)
+	files := pkg.Files()
+	names := make([]string, len(files))
+	for i, file := range files {
+		names[i] = file.Name
+	}
+	return statFiles(root, names), nil
+}
+
+func (r *AlpmRepository) Owns(targets []string) ([]domain.FileOwner, error) {
+	q := newOwnsQuery(targets)
+	r.localDB.PkgCache().ForEach(func(pkg alpm.IPackage) error {
+		files := pkg.Files()
+		names := make([]string, len(files))
+		for i, file := range files {
+			names[i] = file.Name
 		}
+		q.add(pkg.Name(), pkg.Version(), names)
+		return nil
+	})
+	return q.result(), nil
+}
+
+func (r *AlpmRepository) CachedPackages() ([]domain.CachedPackage, error) {
+	installed := make(map[string]string)
+	r.localDB.PkgCache().ForEach(func(pkg alpm.IPackage) error {
+		installed[pkg.Name()] = pkg.Version()
+		return nil
+	})
+	return readCache(r.cacheDirs, installed)
+}
+
+func (r *AlpmRepository) InstallFiles(paths []string, password string, out chan<- string) error {
+	if len(paths) == 0 {
+		return fmt.Errorf("no package files specified for installation")
 	}
 
-	syncDBs, err := handle.SyncDBs()
+	args := []string{"-U", "--noconfirm"}
+	args = append(args, paths...)
+
+	if err := runStreaming(pacmanCommand(r.opts, args, password), out); err != nil {
+		return fmt.Errorf("failed to install package files: %w", err)
+	}
+
+	return nil
+}
+
+func (r *AlpmRepository) RemoveCachedPackages(paths []string, password string, out chan<- string) error {
+	if len(paths) == 0 {
+		return fmt.Errorf("no package files specified for removal")
+	}
+
+	if err := runStreaming(privilegedCommand("rm", cacheRemoveArgs(paths), password), out); err != nil {
+		return fmt.Errorf("failed to remove cached packages: %w", err)
+	}
+
+	return nil
+}
+
+func (r *AlpmRepository) OrphanedConfigs() ([]domain.ConfigFile, error) {
+	root, err := r.handle.Root()
 	if err != nil {
-		handle.Release()
-		return fmt.Errorf("failed to get sync databases: %w", err)
+		return nil, fmt.Errorf("failed to get root directory: %w", err)
 	}
 
-	r.handle = handle
-	r.localDB = localDB
-	r.syncDBs = syncDBs
+	backups := make(map[string]bool)
+	r.localDB.PkgCache().ForEach(func(pkg alpm.IPackage) error {
+		for _, backup := range pkg.Backup().Slice() {
+			backups[filepath.Join(root, backup.Name)] = true
+		}
+		return nil
+	})
+	return orphanedConfigs(root, backups), nil
+}
 
+func (r *AlpmRepository) ReadConfigFile(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrPermission) {
+		data, err = readConfigPrivileged(path)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return string(data), nil
+}
+
+func (r *AlpmRepository) ResolveConfigFile(file domain.ConfigFile, replace bool, password string, out chan<- string) error {
+	name, args := configResolveCommand(file, replace)
+	if err := runStreaming(privilegedCommand(name, args, password), out); err != nil {
+		return fmt.Errorf("failed to resolve %s: %w", file.New, err)
+	}
 	return nil
 }
 
+func (r *AlpmRepository) History() ([]domain.Transaction, error) {
+	return r.history.read(r.logFile)
+}
+
+func (r *AlpmRepository) VerifyPackage(ctx context.Context, name string) (domain.VerifyResult, error) {
+	pkg := r.localDB.Pkg(name)
+	if pkg == nil {
+		return domain.VerifyResult{}, fmt.Errorf("package not found: %s", name)
+	}
+
+	root, err := r.handle.Root()
+	if err != nil {
+		return domain.VerifyResult{}, fmt.Errorf("failed to get root directory: %w", err)
+	}
+
+	entries, err := readMtree(filepath.Join(r.dbPath, "local", pkg.Name()+"-"+pkg.Version()))
+	if err != nil {
+		return domain.VerifyResult{}, err
+	}
+
+	backups := make(map[string]bool)
+	for _, backup := range pkg.Backup().Slice() {
+		backups[backup.Name] = true
+	}
+
+	result, err := verifyMtree(ctx, root, entries, backups)
+	if err != nil {
+		return domain.VerifyResult{}, err
+	}
+	result.Package = pkg.Name()
+	return result, nil
+}
+
+// Refresh reinitializes the ALPM handle to reflect database changes.
+func (r *AlpmRepository) Refresh() error {
+	if r.handle != nil {
+		r.handle.Release()
+	}
+
+	return r.open()
+}
+
 var _ Repository = (*AlpmRepository)(nil)
@@ -0,0 +1,125 @@
+package repository
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/Jguer/go-alpm/v2"
+	"github.com/sjsanc/pacviz/v3/internal/domain"
+)
+
+// fakePackage implements the parts of alpm.IPackage that package conversion
+// reads; calling anything else panics on the nil embedded interface.
+type fakePackage struct {
+	alpm.IPackage
+	name, version string
+	reason        alpm.PkgReason
+	depends       dependList
+	optDepends    dependList
+	conflicts     dependList
+	provides      dependList
+	replaces      dependList
+}
+
+func (p *fakePackage) Name() string                      { return p.name }
+func (p *fakePackage) Version() string                   { return p.version }
+func (p *fakePackage) Description() string               { return "" }
+func (p *fakePackage) Architecture() string              { return "x86_64" }
+func (p *fakePackage) URL() string                       { return "" }
+func (p *fakePackage) Packager() string                  { return "" }
+func (p *fakePackage) Licenses() alpm.StringList         { return alpm.StringList{} }
+func (p *fakePackage) Groups() alpm.StringList           { return alpm.StringList{} }
+func (p *fakePackage) InstallDate() time.Time            { return time.Time{} }
+func (p *fakePackage) BuildDate() time.Time              { return time.Time{} }
+func (p *fakePackage) ISize() int64                      { return 0 }
+func (p *fakePackage) Reason() alpm.PkgReason            { return p.reason }
+func (p *fakePackage) Depends() alpm.IDependList         { return p.depends }
+func (p *fakePackage) OptionalDepends() alpm.IDependList { return p.optDepends }
+func (p *fakePackage) Conflicts() alpm.IDependList       { return p.conflicts }
+func (p *fakePackage) Provides() alpm.IDependList        { return p.provides }
+func (p *fakePackage) Replaces() alpm.IDependList        { return p.replaces }
+
+// dependList is an alpm.IDependList backed by a Go slice.
+type dependList []alpm.Depend
+
+func (l dependList) ForEach(f func(*alpm.Depend) error) error {
+	for i := range l {
+		if err := f(&l[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (l dependList) Slice() []alpm.Depend { return l }
+
+func TestConvertPackage_Relations(t *testing.T) {
+	r := &AlpmRepository{}
+	p := r.convertPackage(&fakePackage{
+		name:    "bash",
+		version: "5.2.026-2",
+		depends: dependList{
+			{Name: "readline"},
+			{Name: "libreadline.so", Mod: alpm.DepModEq, Version: "8-64"},
+			{Name: "glibc", Mod: alpm.DepModGE, Version: "2.38"},
+		},
+		optDepends: dependList{
+			{Name: "bash-completion", Description: "for tab completion"},
+		},
+		conflicts: dependList{{Name: "bash-git"}},
+		provides:  dependList{{Name: "sh"}},
+		replaces:  dependList{{Name: "bash-legacy", Mod: alpm.DepModLT, Version: "5"}},
+	})
+
+	if want := []string{"readline", "libreadline.so=8-64", "glibc>=2.38"}; !reflect.DeepEqual(p.Dependencies, want) {
+		t.Errorf("Dependencies = %q, want %q", p.Dependencies, want)
+	}
+	if want := map[string]string{"bash-completion": "for tab completion"}; !reflect.DeepEqual(p.OptDepends, want) {
+		t.Errorf("OptDepends = %v, want %v", p.OptDepends, want)
+	}
+	if want := []string{"bash-git"}; !reflect.DeepEqual(p.Conflicts, want) {
+		t.Errorf("Conflicts = %q, want %q", p.Conflicts, want)
+	}
+	if want := []string{"sh"}; !reflect.DeepEqual(p.Provides, want) {
+		t.Errorf("Provides = %q, want %q", p.Provides, want)
+	}
+	if want := []string{"bash-legacy<5"}; !reflect.DeepEqual(p.Replaces, want) {
+		t.Errorf("Replaces = %q, want %q", p.Replaces, want)
+	}
+}
+
+func TestComputeBroken_ConvertedProvides(t *testing.T) {
+	r := &AlpmRepository{}
+	packages := []*domain.Package{
+		r.convertPackage(&fakePackage{
+			name:    "bash",
+			version: "5.2.026-2",
+			depends: dependList{
+				{Name: "readline"},
+				{Name: "libreadline.so", Mod: alpm.DepModEq, Version: "8-64"},
+			},
+			provides: dependList{{Name: "sh"}},
+		}),
+		r.convertPackage(&fakePackage{
+			name:     "readline",
+			version:  "8.2.010-1",
+			provides: dependList{{Name: "libreadline.so", Mod: alpm.DepModEq, Version: "8-64"}},
+		}),
+		r.convertPackage(&fakePackage{
+			name:    "makepkg-script",
+			version: "1.0-1",
+			depends: dependList{{Name: "sh"}, {Name: "libgone.so", Mod: alpm.DepModEq, Version: "1-64"}},
+		}),
+	}
+
+	computeBroken(packages)
+
+	if got := findPackage(packages, "bash").UnsatisfiedDeps; got != nil {
+		t.Errorf("bash should be satisfied through provides, got %q", got)
+	}
+	want := []string{"libgone.so=1-64 (missing)"}
+	if got := findPackage(packages, "makepkg-script").UnsatisfiedDeps; !reflect.DeepEqual(got, want) {
+		t.Errorf("makepkg-script UnsatisfiedDeps = %q, want %q", got, want)
+	}
+}
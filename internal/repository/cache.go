@@ -0,0 +1,95 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/Jguer/go-alpm/v2"
+	"github.com/sjsanc/pacviz/v3/internal/domain"
+)
+
+// DefaultCacheDir is pacman's package cache when pacman.conf sets no CacheDir.
+const DefaultCacheDir = "/var/cache/pacman/pkg/"
+
+// parseArchiveName splits a package archive file name
+// ("mesa-1:24.0.5-1-x86_64.pkg.tar.zst") into its name, version and
+// architecture. Signatures and partial downloads are rejected.
+func parseArchiveName(file string) (name, version, arch string, ok bool) {
+	i := strings.Index(file, ".pkg.tar")
+	if i < 0 || strings.HasSuffix(file, ".sig") || strings.HasSuffix(file, ".part") {
+		return "", "", "", false
+	}
+
+	parts := strings.Split(file[:i], "-")
+	if len(parts) < 4 {
+		return "", "", "", false
+	}
+	n := len(parts)
+	return strings.Join(parts[:n-3], "-"), parts[n-3] + "-" + parts[n-2], parts[n-1], true
+}
+
+// readCache lists the package archives in dirs, sorted by name and newest
+// version first. installed maps package names to their installed versions.
+// Missing directories are skipped.
+func readCache(dirs []string, installed map[string]string) ([]domain.CachedPackage, error) {
+	archives := make([]domain.CachedPackage, 0)
+	seen := make(map[string]bool)
+
+	for _, dir := range dirs {
+		entries, err := os.ReadDir(dir)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read package cache: %w", err)
+		}
+
+		for _, entry := range entries {
+			name, version, arch, ok := parseArchiveName(entry.Name())
+			if !ok || entry.IsDir() || seen[entry.Name()] {
+				continue
+			}
+			seen[entry.Name()] = true
+
+			archive := domain.CachedPackage{
+				Name:             name,
+				Version:          version,
+				Architecture:     arch,
+				Path:             filepath.Join(dir, entry.Name()),
+				InstalledVersion: installed[name],
+			}
+			if info, err := entry.Info(); err == nil {
+				archive.Size = info.Size()
+				archive.ModTime = info.ModTime()
+			}
+			archives = append(archives, archive)
+		}
+	}
+
+	sortCache(archives)
+	return archives, nil
+}
+
+// cacheRemoveArgs returns the rm arguments deleting paths and any detached
+// signatures next to them.
+func cacheRemoveArgs(paths []string) []string {
+	args := []string{"-v", "-f", "--"}
+	for _, path := range paths {
+		args = append(args, path, path+".sig")
+	}
+	return args
+}
+
+// sortCache orders archives by name, newest version first.
+func sortCache(archives []domain.CachedPackage) {
+	sort.SliceStable(archives, func(i, j int) bool {
+		if archives[i].Name != archives[j].Name {
+			return archives[i].Name < archives[j].Name
+		}
+		return alpm.VerCmp(archives[i].Version, archives[j].Version) > 0
+	})
+}
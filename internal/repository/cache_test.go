@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/sjsanc/pacviz/v3/internal/domain"
+)
+
+func TestParseArchiveName(t *testing.T) {
+	tests := []struct {
+		file                string
+		name, version, arch string
+		ok                  bool
+	}{
+		{"mesa-1:24.0.5-1-x86_64.pkg.tar.zst", "mesa", "1:24.0.5-1", "x86_64", true},
+		{"lib32-mesa-24.0.5-1-x86_64.pkg.tar.zst", "lib32-mesa", "24.0.5-1", "x86_64", true},
+		{"ca-certificates-20240618-1-any.pkg.tar.xz", "ca-certificates", "20240618-1", "any", true},
+		{"mesa-24.0.5-1-x86_64.pkg.tar.zst.sig", "", "", "", false},
+		{"mesa-24.0.5-1-x86_64.pkg.tar.zst.part", "", "", "", false},
+		{"mesa-24.0.5-x86_64.pkg.tar.zst", "", "", "", false},
+		{"README", "", "", "", false},
+	}
+
+	for _, tt := range tests {
+		name, version, arch, ok := parseArchiveName(tt.file)
+		if name != tt.name || version != tt.version || arch != tt.arch || ok != tt.ok {
+			t.Errorf("parseArchiveName(%q) = %q, %q, %q, %v", tt.file, name, version, arch, ok)
+		}
+	}
+}
+
+func TestReadCache(t *testing.T) {
+	dir := t.TempDir()
+	for _, file := range []string{
+		"mesa-24.0.5-1-x86_64.pkg.tar.zst",
+		"mesa-24.0.5-1-x86_64.pkg.tar.zst.sig",
+		"mesa-1:23.0-1-x86_64.pkg.tar.zst",
+		"mesa-24.1.2-1-x86_64.pkg.tar.zst",
+		"bash-5.2.026-2-x86_64.pkg.tar.zst",
+	} {
+		if err := os.WriteFile(filepath.Join(dir, file), []byte("archive"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	archives, err := readCache([]string{filepath.Join(dir, "missing"), dir}, map[string]string{"mesa": "24.1.2-1"})
+	if err != nil {
+		t.Fatalf("readCache: %v", err)
+	}
+
+	var got []string
+	for _, archive := range archives {
+		got = append(got, archive.Label())
+	}
+	want := []string{"bash 5.2.026-2", "mesa 1:23.0-1", "mesa 24.1.2-1", "mesa 24.0.5-1"}
+	if len(got) != len(want) {
+		t.Fatalf("archives = %q, want %q", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("archives = %q, want %q", got, want)
+			break
+		}
+	}
+
+	if !archives[2].Installed() || archives[3].Installed() || archives[2].Size != 7 {
+		t.Errorf("unexpected installed state or size: %+v", archives[2:])
+	}
+}
+
+func TestFixtureRepository_InstallFiles(t *testing.T) {
+	repo, err := NewFixtureRepository("testdata/fixture.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	out := make(chan string, 16)
+	if err := repo.InstallFiles([]string{"/var/cache/pacman/pkg/bash-5.2.015-5-x86_64.pkg.tar.zst"}, "", out); err != nil {
+		t.Fatalf("InstallFiles: %v", err)
+	}
+	if line := <-out; line != "downgrading bash (5.2.026-2 => 5.2.015-5)..." {
+		t.Errorf("unexpected output %q", line)
+	}
+
+	pkgs, _ := repo.GetInstalled()
+	if bash := findPackage(pkgs, "bash"); bash.Version != "5.2.015-5" || bash.InstallReason != domain.ReasonDependency {
+		t.Errorf("bash after downgrade: %s %v", bash.Version, bash.InstallReason)
+	}
+}
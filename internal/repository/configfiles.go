@@ -0,0 +1,87 @@
+package repository
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/sjsanc/pacviz/v3/internal/domain"
+)
+
+// configBackupSuffix is appended to a live configuration file that is
+// replaced with its .pacnew or .pacsave file.
+const configBackupSuffix = ".orig"
+
+// pendingConfigs returns the .pacnew and .pacsave files pacman left next to
+// a package's backup files, which are relative to root.
+func pendingConfigs(root string, backups []string) []string {
+	var pending []string
+	for _, backup := range backups {
+		for _, suffix := range []string{".pacnew", ".pacsave"} {
+			path := filepath.Join(root, backup+suffix)
+			if _, err := os.Lstat(path); err == nil {
+				pending = append(pending, path)
+			}
+		}
+	}
+	return pending
+}
+
+// configDirs are the directories, relative to root, searched for the
+// .pacsave files of removed packages.
+var configDirs = []string{"etc"}
+
+// orphanedConfigs returns the .pacsave files under root's configuration
+// directories that are not next to one of backups, the absolute paths of
+// the installed packages' backup files. pacman leaves them behind when it
+// removes a package whose configuration was modified. Unreadable
+// directories are skipped.
+func orphanedConfigs(root string, backups map[string]bool) []domain.ConfigFile {
+	var files []domain.ConfigFile
+	for _, dir := range configDirs {
+		filepath.WalkDir(filepath.Join(root, dir), func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				if d != nil && d.IsDir() {
+					return fs.SkipDir
+				}
+				return nil
+			}
+			if d.IsDir() || !strings.HasSuffix(path, ".pacsave") {
+				return nil
+			}
+			if !backups[strings.TrimSuffix(path, ".pacsave")] {
+				files = append(files, domain.NewConfigFile("", path))
+			}
+			return nil
+		})
+	}
+	return files
+}
+
+// configResolveCommand returns the command that replaces the live file with
+// the .pacnew or .pacsave file, or deletes the latter. Like pacdiff, a
+// replaced live file is kept next to it with the .orig suffix.
+func configResolveCommand(file domain.ConfigFile, replace bool) (string, []string) {
+	if replace {
+		return "mv", []string{"-v", "-f", "--backup=simple", "--suffix=" + configBackupSuffix, "--", file.New, file.Path}
+	}
+	return "rm", []string{"-v", "-f", "--", file.New}
+}
+
+// readConfigPrivileged reads a file only root may read, through sudo without
+// prompting, so it works when pacviz runs as root or sudo has cached
+// credentials.
+func readConfigPrivileged(path string) ([]byte, error) {
+	cmd := exec.Command("cat", "--", path)
+	if os.Geteuid() != 0 {
+		cmd = exec.Command("sudo", "-n", "cat", "--", path)
+	}
+	data, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("%s is only readable by root; run pacviz as root or authenticate with sudo first: %w", path, os.ErrPermission)
+	}
+	return data, nil
+}
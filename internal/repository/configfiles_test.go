@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/sjsanc/pacviz/v3/internal/domain"
+)
+
+func TestPendingConfigs(t *testing.T) {
+	root := t.TempDir()
+	for _, name := range []string{"etc/pacman.conf", "etc/pacman.conf.pacnew", "etc/makepkg.conf.pacsave", "etc/fstab"} {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got := pendingConfigs(root, []string{"etc/pacman.conf", "etc/makepkg.conf", "etc/fstab"})
+	want := []string{filepath.Join(root, "etc/pacman.conf.pacnew"), filepath.Join(root, "etc/makepkg.conf.pacsave")}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("pendingConfigs() = %q, want %q", got, want)
+	}
+}
+
+func TestOrphanedConfigs(t *testing.T) {
+	root := t.TempDir()
+	for _, name := range []string{"etc/makepkg.conf.pacsave", "etc/oldtool/oldtool.conf.pacsave", "etc/fstab", "usr/share/x.pacsave"} {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got := orphanedConfigs(root, map[string]bool{filepath.Join(root, "etc/makepkg.conf"): true})
+	orphan := filepath.Join(root, "etc/oldtool/oldtool.conf")
+	want := []domain.ConfigFile{{Path: orphan, New: orphan + ".pacsave"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("orphanedConfigs() = %+v, want %+v", got, want)
+	}
+}
+
+func TestConfigResolveCommand_KeepsBackup(t *testing.T) {
+	dir := t.TempDir()
+	live := filepath.Join(dir, "pacman.conf")
+	file := domain.ConfigFile{Path: live, New: live + ".pacnew"}
+	if err := os.WriteFile(live, []byte("old\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(file.New, []byte("new\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	name, args := configResolveCommand(file, true)
+	if out, err := exec.Command(name, args...).CombinedOutput(); err != nil {
+		t.Fatalf("%s failed: %v: %s", name, err, out)
+	}
+
+	for path, want := range map[string]string{live: "new\n", live + ".orig": "old\n"} {
+		if data, err := os.ReadFile(path); err != nil || string(data) != want {
+			t.Errorf("%s = %q, %v; want %q", path, data, err, want)
+		}
+	}
+	if _, err := os.Lstat(file.New); !os.IsNotExist(err) {
+		t.Errorf("expected %s to be moved, got %v", file.New, err)
+	}
+}
@@ -0,0 +1,147 @@
+package repository
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Jguer/go-alpm/v2"
+	"github.com/sjsanc/pacviz/v3/internal/domain"
+)
+
+// depGraph resolves dependency strings such as "sh" or "glibc>=2.38" to the
+// installed packages that satisfy them, either by name or through provides.
+type depGraph struct {
+	byName    map[string]*domain.Package
+	providers map[string][]provider
+}
+
+// provider is a package that provides a name, optionally at a version.
+type provider struct {
+	pkg     *domain.Package
+	version string
+}
+
+func newDepGraph(packages []*domain.Package) *depGraph {
+	g := &depGraph{
+		byName:    make(map[string]*domain.Package, len(packages)),
+		providers: make(map[string][]provider),
+	}
+	for _, pkg := range packages {
+		g.byName[pkg.Name] = pkg
+		for _, provide := range pkg.Provides {
+			name, _, version := parseDep(provide)
+			g.providers[name] = append(g.providers[name], provider{pkg: pkg, version: version})
+		}
+	}
+	return g
+}
+
+// satisfiers returns the installed packages that satisfy dep. A package
+// with the dependency's name wins outright; otherwise every provider that
+// meets the constraint counts, as pacman lists each of them as required.
+func (g *depGraph) satisfiers(dep string) []*domain.Package {
+	name, op, version := parseDep(dep)
+
+	if pkg, ok := g.byName[name]; ok && satisfiesVersion(pkg.Version, op, version) {
+		return []*domain.Package{pkg}
+	}
+
+	var result []*domain.Package
+	for _, p := range g.providers[name] {
+		if satisfiesVersion(p.version, op, version) {
+			result = append(result, p.pkg)
+		}
+	}
+	return result
+}
+
+// parseDep splits a dependency or provision string ("glibc>=2.38") into its
+// name, comparison operator and version. op and version are empty when the
+// string carries no constraint.
+func parseDep(dep string) (name, op, version string) {
+	i := strings.IndexAny(dep, "<>=")
+	if i < 0 {
+		return dep, "", ""
+	}
+	j := i
+	for j < len(dep) && strings.ContainsRune("<>=", rune(dep[j])) {
+		j++
+	}
+	return dep[:i], dep[i:j], dep[j:]
+}
+
+// satisfiesVersion reports whether version meets the constraint "op want".
+// An unversioned provision only satisfies unversioned dependencies.
+func satisfiesVersion(version, op, want string) bool {
+	if op == "" {
+		return true
+	}
+	if version == "" {
+		return false
+	}
+
+	cmp := alpm.VerCmp(version, want)
+	switch op {
+	case "=":
+		return cmp == 0
+	case ">=":
+		return cmp >= 0
+	case "<=":
+		return cmp <= 0
+	case ">":
+		return cmp > 0
+	case "<":
+		return cmp < 0
+	}
+	return false
+}
+
+// computeOrphans populates Required and DependencyCount from the resolved
+// dependency graph and marks dependency packages nothing requires as orphans.
+func computeOrphans(packages []*domain.Package) {
+	graph := newDepGraph(packages)
+
+	reverseDeps := make(map[string][]string)
+	for _, pkg := range packages {
+		seen := make(map[string]bool)
+		for _, dep := range pkg.Dependencies {
+			for _, satisfier := range graph.satisfiers(dep) {
+				if satisfier == pkg || seen[satisfier.Name] {
+					continue
+				}
+				seen[satisfier.Name] = true
+				reverseDeps[satisfier.Name] = append(reverseDeps[satisfier.Name], pkg.Name)
+			}
+		}
+	}
+
+	for _, pkg := range packages {
+		if pkg.InstallReason == domain.ReasonDependency {
+			pkg.IsOrphan = len(reverseDeps[pkg.Name]) == 0
+		}
+		pkg.Required = reverseDeps[pkg.Name]
+		pkg.DependencyCount = len(reverseDeps[pkg.Name])
+	}
+}
+
+// computeBroken records, like pacman -Dk, every dependency of each package
+// that no installed package satisfies, noting the installed version when a
+// package of that name exists but is too old or too new.
+func computeBroken(packages []*domain.Package) {
+	graph := newDepGraph(packages)
+
+	for _, pkg := range packages {
+		pkg.UnsatisfiedDeps = nil
+		for _, dep := range pkg.Dependencies {
+			if len(graph.satisfiers(dep)) > 0 {
+				continue
+			}
+			name, _, _ := parseDep(dep)
+			if installed, ok := graph.byName[name]; ok {
+				pkg.UnsatisfiedDeps = append(pkg.UnsatisfiedDeps, fmt.Sprintf("%s (installed: %s)", dep, installed.Version))
+			} else {
+				pkg.UnsatisfiedDeps = append(pkg.UnsatisfiedDeps, dep+" (missing)")
+			}
+		}
+	}
+}
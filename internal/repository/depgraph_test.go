@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/sjsanc/pacviz/v3/internal/domain"
+)
+
+func TestParseDep(t *testing.T) {
+	tests := []struct {
+		dep                       string
+		wantName, wantOp, wantVer string
+	}{
+		{"glibc", "glibc", "", ""},
+		{"glibc>=2.38", "glibc", ">=", "2.38"},
+		{"sh=5.2", "sh", "=", "5.2"},
+		{"python<3.13", "python", "<", "3.13"},
+	}
+
+	for _, tt := range tests {
+		name, op, version := parseDep(tt.dep)
+		if name != tt.wantName || op != tt.wantOp || version != tt.wantVer {
+			t.Errorf("parseDep(%q) = %q, %q, %q", tt.dep, name, op, version)
+		}
+	}
+}
+
+func TestDepGraph_Satisfiers(t *testing.T) {
+	graph := newDepGraph([]*domain.Package{
+		{Name: "glibc", Version: "2.39-1"},
+		{Name: "bash", Version: "5.2.026-2", Provides: []string{"sh"}},
+		{Name: "jdk-openjdk", Version: "22.0.1-1", Provides: []string{"java-runtime=22", "java-environment=22"}},
+		{Name: "jre17-openjdk", Version: "17.0.11-1", Provides: []string{"java-runtime=17"}},
+	})
+
+	tests := []struct {
+		dep  string
+		want []string
+	}{
+		{"glibc", []string{"glibc"}},
+		{"glibc>=2.38", []string{"glibc"}},
+		{"glibc>=2.40", nil},
+		{"sh", []string{"bash"}},
+		{"sh>=5", nil}, // unversioned provides only satisfy unversioned deps
+		{"java-runtime", []string{"jdk-openjdk", "jre17-openjdk"}},
+		{"java-runtime>=21", []string{"jdk-openjdk"}},
+		{"missing", nil},
+	}
+
+	for _, tt := range tests {
+		var got []string
+		for _, pkg := range graph.satisfiers(tt.dep) {
+			got = append(got, pkg.Name)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("satisfiers(%q) = %v, want %v", tt.dep, got, tt.want)
+		}
+	}
+}
+
+func TestComputeOrphans_Provides(t *testing.T) {
+	dep := domain.ReasonDependency
+	packages := []*domain.Package{
+		{Name: "base", Dependencies: []string{"sh", "bash"}},
+		{Name: "bash", Version: "5.2.026-2", InstallReason: dep, Provides: []string{"sh"}},
+		{Name: "app", Dependencies: []string{"java-runtime>=21"}},
+		{Name: "jdk-openjdk", Version: "22.0.1-1", InstallReason: dep, Provides: []string{"java-runtime=22"}},
+		{Name: "jre17-openjdk", Version: "17.0.11-1", InstallReason: dep, Provides: []string{"java-runtime=17"}},
+	}
+
+	computeOrphans(packages)
+
+	bash := findPackage(packages, "bash")
+	if bash.IsOrphan || !reflect.DeepEqual(bash.Required, []string{"base"}) || bash.DependencyCount != 1 {
+		t.Errorf("bash: orphan %v, required %v, count %d", bash.IsOrphan, bash.Required, bash.DependencyCount)
+	}
+	if jdk := findPackage(packages, "jdk-openjdk"); jdk.IsOrphan {
+		t.Error("jdk-openjdk satisfies java-runtime>=21 and should not be an orphan")
+	}
+	if jre := findPackage(packages, "jre17-openjdk"); !jre.IsOrphan {
+		t.Error("jre17-openjdk is too old for java-runtime>=21 and should be an orphan")
+	}
+}
+
+func TestComputeBroken(t *testing.T) {
+	packages := []*domain.Package{
+		{Name: "app", Dependencies: []string{"glibc>=2.40", "sh", "libgone"}},
+		{Name: "glibc", Version: "2.39-1"},
+		{Name: "bash", Version: "5.2.026-2", Provides: []string{"sh"}},
+		{Name: "tool", Dependencies: []string{"glibc>=2.38"}},
+	}
+
+	computeBroken(packages)
+
+	want := []string{"glibc>=2.40 (installed: 2.39-1)", "libgone (missing)"}
+	if got := findPackage(packages, "app").UnsatisfiedDeps; !reflect.DeepEqual(got, want) {
+		t.Errorf("app UnsatisfiedDeps = %q, want %q", got, want)
+	}
+	if got := findPackage(packages, "tool").UnsatisfiedDeps; got != nil {
+		t.Errorf("tool should be satisfied, got %q", got)
+	}
+}
@@ -0,0 +1,97 @@
+package repository
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"os"
+	"os/exec"
+)
+
+// pacmanCommand builds a pacman invocation, wrapped in sudo when not running
+// as root. The password is fed to sudo on stdin and the prompt is suppressed
+// so it does not appear in the streamed output. The configuration, root and
+// database from opts are passed on, so that transactions change the system
+// pacviz shows rather than the live host.
+func pacmanCommand(opts Options, args []string, password string) *exec.Cmd {
+	return privilegedCommand("pacman", append(pacmanGlobalArgs(opts), args...), password)
+}
+
+// pacmanGlobalArgs returns the pacman options selecting the configuration,
+// root and database set in opts.
+func pacmanGlobalArgs(opts Options) []string {
+	var args []string
+	if opts.ConfigPath != "" {
+		args = append(args, "--config", opts.ConfigPath)
+	}
+	if opts.RootDir != "" {
+		args = append(args, "--root", opts.RootDir)
+	}
+	if opts.DBPath != "" {
+		args = append(args, "--dbpath", opts.DBPath)
+	}
+	return args
+}
+
+// privilegedCommand runs name as root, through sudo like pacmanCommand.
+func privilegedCommand(name string, args []string, password string) *exec.Cmd {
+	if os.Geteuid() == 0 {
+		return exec.Command(name, args...)
+	}
+
+	cmd := exec.Command("sudo", append([]string{"-S", "-p", "", name}, args...)...)
+
+	var stdin bytes.Buffer
+	stdin.WriteString(password + "\n")
+	cmd.Stdin = &stdin
+
+	return cmd
+}
+
+// runStreaming runs cmd and sends each line of combined stdout/stderr to out
+// as it is produced. Carriage returns are treated as line breaks so progress
+// updates arrive as separate lines.
+func runStreaming(cmd *exec.Cmd, out chan<- string) error {
+	pr, pw := io.Pipe()
+	cmd.Stdout = pw
+	cmd.Stderr = pw
+
+	if err := cmd.Start(); err != nil {
+		pw.Close()
+		return err
+	}
+
+	scanDone := make(chan struct{})
+	go func() {
+		defer close(scanDone)
+		scanner := bufio.NewScanner(pr)
+		scanner.Split(scanLinesOrCR)
+		for scanner.Scan() {
+			if line := scanner.Text(); line != "" {
+				out <- line
+			}
+		}
+		// Drain anything left so the writer never blocks
+		io.Copy(io.Discard, pr)
+	}()
+
+	err := cmd.Wait()
+	pw.Close()
+	<-scanDone
+
+	return err
+}
+
+// scanLinesOrCR is a bufio.SplitFunc that splits on '\n' or '\r'.
+func scanLinesOrCR(data []byte, atEOF bool) (advance int, token []byte, err error) {
+	if atEOF && len(data) == 0 {
+		return 0, nil, nil
+	}
+	if i := bytes.IndexAny(data, "\r\n"); i >= 0 {
+		return i + 1, data[:i], nil
+	}
+	if atEOF {
+		return len(data), data, nil
+	}
+	return 0, nil, nil
+}
@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"os/exec"
+	"reflect"
+	"testing"
+)
+
+func TestRunStreaming(t *testing.T) {
+	out := make(chan string, 16)
+	cmd := exec.Command("sh", "-c", `printf 'resolving\n'; printf 'downloading 10%%\rdownloading 100%%\n' >&2; exit 3`)
+
+	err := runStreaming(cmd, out)
+	close(out)
+
+	if err == nil {
+		t.Error("expected non-zero exit to be reported")
+	}
+
+	var lines []string
+	for line := range out {
+		lines = append(lines, line)
+	}
+
+	want := []string{"resolving", "downloading 10%", "downloading 100%"}
+	if !reflect.DeepEqual(lines, want) {
+		t.Errorf("lines = %q, want %q", lines, want)
+	}
+}
+
+func TestPacmanCommand(t *testing.T) {
+	tests := []struct {
+		name string
+		opts Options
+		want []string
+	}{
+		{
+			name: "system",
+			want: []string{"pacman", "-S", "--noconfirm", "bash"},
+		},
+		{
+			name: "alternate root and database",
+			opts: Options{ConfigPath: "/mnt/etc/pacman.conf", RootDir: "/mnt", DBPath: "/mnt/var/lib/pacman"},
+			want: []string{"pacman", "--config", "/mnt/etc/pacman.conf", "--root", "/mnt", "--dbpath", "/mnt/var/lib/pacman", "-S", "--noconfirm", "bash"},
+		},
+		{
+			name: "database only",
+			opts: Options{DBPath: "/tmp/db"},
+			want: []string{"pacman", "--dbpath", "/tmp/db", "-S", "--noconfirm", "bash"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := pacmanCommand(tt.opts, []string{"-S", "--noconfirm", "bash"}, "secret")
+
+			// Without root the command runs through sudo; compare from pacman on.
+			args := cmd.Args
+			for i, arg := range args {
+				if arg == "pacman" {
+					args = args[i:]
+					break
+				}
+			}
+			if !reflect.DeepEqual(args, tt.want) {
+				t.Errorf("args = %q, want %q", cmd.Args, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,48 @@
+package repository
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/sjsanc/pacviz/v3/internal/domain"
+)
+
+// statFiles turns database file entries (relative paths such as
+// "usr/bin/bash" or "usr/bin/") into package files, reading sizes from disk
+// below root. An empty root skips the disk lookups.
+func statFiles(root string, names []string) []domain.PackageFile {
+	files := make([]domain.PackageFile, 0, len(names))
+	for _, name := range names {
+		file := domain.PackageFile{
+			Path:  "/" + name,
+			IsDir: strings.HasSuffix(name, "/"),
+		}
+		if root != "" {
+			info, err := os.Lstat(filepath.Join(root, name))
+			if err != nil {
+				file.Missing = true
+			} else if !file.IsDir {
+				file.Size = info.Size()
+			}
+		}
+		files = append(files, file)
+	}
+	return files
+}
+
+// readLocalFiles reads the %FILES% list of a local database entry directory.
+func readLocalFiles(dir string) ([]string, error) {
+	f, err := os.Open(filepath.Join(dir, "files"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file list: %w", err)
+	}
+	defer f.Close()
+
+	entry, err := parseDesc(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse file list: %w", err)
+	}
+	return entry.list("FILES"), nil
+}
@@ -0,0 +1,158 @@
+package repository
+
+import (
+	"archive/tar"
+	"bufio"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/sjsanc/pacviz/v3/internal/domain"
+)
+
+// errNoFileDBs is returned by file searches when no .files database exists yet.
+var errNoFileDBs = errors.New("no file databases found, run :sync files first")
+
+// fileQuery matches file list entries the way pacman -F does: a bare name
+// ("rg", "libfoo.so.3") matches the last path element, a query containing a
+// slash matches the whole path. Either form may be a glob.
+type fileQuery struct {
+	pattern  string
+	fullPath bool
+	glob     bool
+}
+
+func newFileQuery(query string) fileQuery {
+	query = strings.TrimPrefix(query, "/")
+	return fileQuery{
+		pattern:  query,
+		fullPath: strings.Contains(query, "/"),
+		glob:     isGlob(query),
+	}
+}
+
+// match reports whether a database entry ("usr/bin/rg") matches. Directory
+// entries never match.
+func (q fileQuery) match(entry string) bool {
+	if q.pattern == "" || strings.HasSuffix(entry, "/") {
+		return false
+	}
+
+	subject := entry
+	if !q.fullPath {
+		subject = path.Base(entry)
+	}
+	if q.glob {
+		ok, _ := path.Match(q.pattern, subject)
+		return ok
+	}
+	return subject == q.pattern
+}
+
+// matchFiles returns the entries matching q as absolute paths.
+func (q fileQuery) matchFiles(entries []string) []string {
+	var matched []string
+	for _, entry := range entries {
+		if q.match(entry) {
+			matched = append(matched, "/"+entry)
+		}
+	}
+	return matched
+}
+
+// searchFilesDB scans a sync .files database, a tar archive holding a desc
+// and a files entry per package, and returns the packages owning files that
+// match q, sorted by name, with MatchedFiles set.
+func searchFilesDB(r io.Reader, q fileQuery) ([]*domain.Package, error) {
+	br := bufio.NewReader(r)
+	if magic, _ := br.Peek(2); len(magic) == 2 && magic[0] == 0x1f && magic[1] == 0x8b {
+		gz, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decompress file database: %w", err)
+		}
+		defer gz.Close()
+		r = gz
+	} else {
+		r = br
+	}
+
+	descs := make(map[string]*domain.Package)
+	matches := make(map[string][]string)
+
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read file database: %w", err)
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		dir := path.Dir(hdr.Name)
+		switch path.Base(hdr.Name) {
+		case "desc":
+			desc, err := parseDesc(tr)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse %s: %w", hdr.Name, err)
+			}
+			descs[dir] = desc.toPackage()
+		case "files":
+			entry, err := parseDesc(tr)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse %s: %w", hdr.Name, err)
+			}
+			if matched := q.matchFiles(entry.list("FILES")); len(matched) > 0 {
+				matches[dir] = matched
+			}
+		}
+	}
+
+	result := make([]*domain.Package, 0, len(matches))
+	for dir, matched := range matches {
+		pkg, ok := descs[dir]
+		if !ok {
+			continue
+		}
+		pkg.MatchedFiles = matched
+		result = append(result, pkg)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+
+	return result, nil
+}
+
+// filesDBPath returns the first <dir>/<repo>.files that exists, or "".
+func filesDBPath(dirs []string, repo string) string {
+	for _, dir := range dirs {
+		path := filepath.Join(dir, repo+".files")
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+	}
+	return ""
+}
+
+// searchFilesDBFile runs searchFilesDB on the database at path.
+func searchFilesDBFile(path string, q fileQuery) ([]*domain.Package, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file database: %w", err)
+	}
+	defer f.Close()
+
+	pkgs, err := searchFilesDB(f, q)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search %s: %w", filepath.Base(path), err)
+	}
+	return pkgs, nil
+}
@@ -0,0 +1,152 @@
+package repository
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestFileQuery_Match(t *testing.T) {
+	tests := []struct {
+		query string
+		entry string
+		want  bool
+	}{
+		{"rg", "usr/bin/rg", true},
+		{"rg", "usr/share/man/man1/rg.1.gz", false},
+		{"libfoo.so.3", "usr/lib/libfoo.so.3", true},
+		{"/usr/bin/rg", "usr/bin/rg", true},
+		{"usr/bin/rg", "usr/bin/rg", true},
+		{"bin/rg", "usr/bin/rg", false},
+		{"libfoo.so*", "usr/lib/libfoo.so.3", true},
+		{"/usr/lib/libfoo*", "usr/lib/libfoo.so", true},
+		{"/usr/lib/*", "usr/lib/sub/libfoo.so", false},
+		{"bin", "usr/bin/", false},
+		{"", "usr/bin/rg", false},
+	}
+
+	for _, tt := range tests {
+		if got := newFileQuery(tt.query).match(tt.entry); got != tt.want {
+			t.Errorf("newFileQuery(%q).match(%q) = %v, want %v", tt.query, tt.entry, got, tt.want)
+		}
+	}
+}
+
+// writeFilesDB writes a .files database with a desc and a files entry for
+// each package, given as package name -> file list.
+func writeFilesDB(t *testing.T, path string, pkgs map[string][]string) {
+	t.Helper()
+
+	dirs := make(map[string]map[string]string, len(pkgs))
+	for name, files := range pkgs {
+		list := "%FILES%\n"
+		for _, file := range files {
+			list += file + "\n"
+		}
+		dirs[name+"-1.0-1"] = map[string]string{
+			"desc":  "%NAME%\n" + name + "\n\n%VERSION%\n1.0-1\n",
+			"files": list,
+		}
+	}
+	writeDBArchive(t, path, dirs)
+}
+
+func TestSearchFilesDB(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "extra.files")
+	writeFilesDB(t, path, map[string][]string{
+		"ripgrep": {"usr/", "usr/bin/", "usr/bin/rg"},
+		"libfoo":  {"usr/", "usr/lib/", "usr/lib/libfoo.so", "usr/lib/libfoo.so.3"},
+		"nano":    {"usr/", "usr/bin/", "usr/bin/nano"},
+	})
+
+	pkgs, err := searchFilesDBFile(path, newFileQuery("rg"))
+	if err != nil {
+		t.Fatalf("searchFilesDBFile: %v", err)
+	}
+	if len(pkgs) != 1 || pkgs[0].Name != "ripgrep" || pkgs[0].Version != "1.0-1" ||
+		!reflect.DeepEqual(pkgs[0].MatchedFiles, []string{"/usr/bin/rg"}) {
+		t.Errorf("unexpected result for rg: %+v", pkgs)
+	}
+
+	pkgs, err = searchFilesDBFile(path, newFileQuery("/usr/lib/libfoo.so*"))
+	if err != nil {
+		t.Fatalf("searchFilesDBFile: %v", err)
+	}
+	if len(pkgs) != 1 || len(pkgs[0].MatchedFiles) != 2 {
+		t.Errorf("unexpected result for the libfoo glob: %+v", pkgs)
+	}
+
+	if pkgs, _ := searchFilesDBFile(path, newFileQuery("usr")); len(pkgs) != 0 {
+		t.Errorf("directories should not match, got %+v", pkgs)
+	}
+}
+
+func TestDownloadSyncDBs_Files(t *testing.T) {
+	mirror := t.TempDir()
+	writeFilesDB(t, filepath.Join(mirror, "extra", "os", "x86_64", "extra.files"), map[string][]string{
+		"ripgrep": {"usr/", "usr/bin/", "usr/bin/rg"},
+	})
+
+	server := httptest.NewServer(http.FileServer(http.Dir(mirror)))
+	defer server.Close()
+
+	dir := t.TempDir()
+	repos := []syncRepo{{
+		Name:    "extra",
+		Servers: []string{expandMirror(server.URL+"/$repo/os/$arch", "extra", "x86_64")},
+	}}
+
+	out := make(chan string, 16)
+	if err := downloadSyncDBs(server.Client(), dir, repos, ".files", out); err != nil {
+		t.Fatalf("downloadSyncDBs: %v", err)
+	}
+	close(out)
+
+	path := filesDBPath([]string{filepath.Join(dir, "missing"), dir}, "extra")
+	if path != filepath.Join(dir, "extra.files") {
+		t.Fatalf("filesDBPath = %q", path)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "extra.db")); err == nil {
+		t.Error("a file database sync should not write extra.db")
+	}
+
+	pkgs, err := searchFilesDBFile(path, newFileQuery("/usr/bin/rg"))
+	if err != nil || len(pkgs) != 1 || pkgs[0].Name != "ripgrep" {
+		t.Errorf("search after download = %+v, %v", pkgs, err)
+	}
+}
+
+func TestFixtureRepository_SearchFiles(t *testing.T) {
+	dbPath := t.TempDir()
+	if err := os.CopyFS(dbPath, os.DirFS("testdata/dbpath")); err != nil {
+		t.Fatal(err)
+	}
+	writeSyncDB(t, filepath.Join(dbPath, "sync", "core.db"), map[string]string{
+		"bash-5.2.026-2": "%NAME%\nbash\n\n%VERSION%\n5.2.026-2\n",
+	})
+
+	repo, err := NewFixtureRepository(dbPath)
+	if err != nil {
+		t.Fatalf("NewFixtureRepository: %v", err)
+	}
+	if _, err := repo.SearchFiles("bash"); !errors.Is(err, errNoFileDBs) {
+		t.Errorf("expected errNoFileDBs before a file sync, got %v", err)
+	}
+
+	writeFilesDB(t, filepath.Join(dbPath, "sync", "core.files"), map[string][]string{
+		"bash": {"usr/", "usr/bin/", "usr/bin/bash", "usr/bin/sh"},
+	})
+
+	pkgs, err := repo.SearchFiles("sh")
+	if err != nil {
+		t.Fatalf("SearchFiles: %v", err)
+	}
+	if len(pkgs) != 1 || pkgs[0].Repository != "core" || !pkgs[0].Installed ||
+		!reflect.DeepEqual(pkgs[0].MatchedFiles, []string{"/usr/bin/sh"}) {
+		t.Errorf("unexpected results: %+v", pkgs)
+	}
+}
@@ -0,0 +1,798 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Jguer/go-alpm/v2"
+	"github.com/sjsanc/pacviz/v3/internal/domain"
+)
+
+// FixtureRepository serves packages from a copied pacman database directory
+// or a JSON fixture instead of the live system. Install and Remove only
+// modify the in-memory package set, so it is safe for demos and tests.
+type FixtureRepository struct {
+	mu        sync.Mutex
+	installed map[string]*domain.Package
+	syncDBs   map[string][]*domain.Package
+	repoOrder []string
+
+	localDir  string                       // local database directory, when loaded from one
+	syncDir   string                       // sync database directory holding any .files databases
+	files     map[string][]string          // file lists from a JSON fixture
+	syncFiles map[string][]string          // sync file lists from a JSON fixture, keyed "repo/name"
+	cacheDir  string                       // package cache directory, when loaded from a database directory
+	cache     []fixtureArchive             // package cache from a JSON fixture
+	cleaned   map[string]bool              // cached archives removed in memory
+	logFile   string                       // pacman.log, when loaded from a database directory
+	history   pacmanLogCache               // parsed logFile
+	log       []string                     // pacman.log lines from a JSON fixture
+	backups   map[string][]string          // backup files from a JSON fixture, relative to /
+	configs   map[string]string            // configuration file contents from a JSON fixture, by absolute path
+	altered   map[string]map[string]string // verification problems from a JSON fixture, by package and path
+}
+
+// fixtureFile is the on-disk JSON fixture layout.
+type fixtureFile struct {
+	Installed []fixturePackage            `json:"installed"`
+	Sync      map[string][]fixturePackage `json:"sync"`
+	Cache     []fixtureArchive            `json:"cache"`
+	Log       []string                    `json:"log"`
+	Configs   map[string]string           `json:"configs"`
+}
+
+// fixtureArchive is a package archive in a JSON fixture's simulated cache.
+type fixtureArchive struct {
+	File     string    `json:"file"`
+	Size     int64     `json:"size"`
+	Modified time.Time `json:"modified"`
+}
+
+type fixturePackage struct {
+	Name         string            `json:"name"`
+	Version      string            `json:"version"`
+	Description  string            `json:"description"`
+	Architecture string            `json:"arch"`
+	URL          string            `json:"url"`
+	Licenses     []string          `json:"licenses"`
+	Groups       []string          `json:"groups"`
+	Depends      []string          `json:"depends"`
+	OptDepends   map[string]string `json:"optdepends"`
+	Conflicts    []string          `json:"conflicts"`
+	Provides     []string          `json:"provides"`
+	Replaces     []string          `json:"replaces"`
+	Reason       string            `json:"reason"` // "explicit" (default) or "dependency"
+	InstallDate  time.Time         `json:"install_date"`
+	Size         int64             `json:"size"`
+	DownloadSize int64             `json:"download_size"`
+	Packager     string            `json:"packager"`
+	BuildDate    time.Time         `json:"build_date"`
+	Files        []string          `json:"files"`
+	Backup       []string          `json:"backup"`
+	Altered      map[string]string `json:"altered"` // problems :verify reports, by path relative to /
+}
+
+func (fp fixturePackage) toPackage() *domain.Package {
+	reason := domain.ReasonExplicit
+	if fp.Reason == "dependency" || fp.Reason == "dep" {
+		reason = domain.ReasonDependency
+	}
+
+	deps := make([]string, len(fp.Depends))
+	copy(deps, fp.Depends)
+
+	return &domain.Package{
+		Name:          fp.Name,
+		Version:       fp.Version,
+		Description:   fp.Description,
+		Architecture:  fp.Architecture,
+		URL:           fp.URL,
+		Licenses:      fp.Licenses,
+		Groups:        fp.Groups,
+		Dependencies:  deps,
+		OptDepends:    fp.OptDepends,
+		Conflicts:     fp.Conflicts,
+		Provides:      fp.Provides,
+		Replaces:      fp.Replaces,
+		InstallDate:   fp.InstallDate,
+		InstallReason: reason,
+		InstalledSize: fp.Size,
+		DownloadSize:  fp.DownloadSize,
+		Packager:      fp.Packager,
+		BuildDate:     fp.BuildDate,
+	}
+}
+
+// NewFixtureRepository loads packages from path, which may be a pacman
+// database directory (containing local/ and optionally sync/*.db, cache/
+// and pacman.log), a bare local database directory, or a JSON fixture file.
+func NewFixtureRepository(path string) (*FixtureRepository, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open fixture: %w", err)
+	}
+
+	if !info.IsDir() {
+		return loadJSONFixture(path)
+	}
+
+	localDir := filepath.Join(path, "local")
+	if _, err := os.Stat(localDir); err != nil {
+		localDir = path
+	}
+
+	installed, err := readLocalDB(localDir)
+	if err != nil {
+		return nil, err
+	}
+
+	syncDBs := make(map[string][]*domain.Package)
+	dbFiles, _ := filepath.Glob(filepath.Join(path, "sync", "*.db"))
+	for _, dbFile := range dbFiles {
+		pkgs, err := readSyncDB(dbFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load %s: %w", filepath.Base(dbFile), err)
+		}
+		syncDBs[strings.TrimSuffix(filepath.Base(dbFile), ".db")] = pkgs
+	}
+
+	r := newFixtureRepository(installed, syncDBs)
+	r.localDir = localDir
+	r.syncDir = filepath.Join(path, "sync")
+	r.cacheDir = filepath.Join(path, "cache")
+	if _, err := os.Stat(filepath.Join(path, "pacman.log")); err == nil {
+		r.logFile = filepath.Join(path, "pacman.log")
+	}
+	return r, nil
+}
+
+func loadJSONFixture(path string) (*FixtureRepository, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read fixture: %w", err)
+	}
+
+	var fixture fixtureFile
+	if err := json.Unmarshal(data, &fixture); err != nil {
+		return nil, fmt.Errorf("failed to parse fixture: %w", err)
+	}
+
+	installed := make([]*domain.Package, 0, len(fixture.Installed))
+	files := make(map[string][]string)
+	backups := make(map[string][]string)
+	altered := make(map[string]map[string]string)
+	for _, fp := range fixture.Installed {
+		pkg := fp.toPackage()
+		pkg.Installed = true
+		installed = append(installed, pkg)
+		files[fp.Name] = fp.Files
+		backups[fp.Name] = fp.Backup
+		if len(fp.Altered) > 0 {
+			altered[fp.Name] = fp.Altered
+		}
+	}
+
+	syncDBs := make(map[string][]*domain.Package)
+	syncFiles := make(map[string][]string)
+	for repo, fps := range fixture.Sync {
+		for _, fp := range fps {
+			syncDBs[repo] = append(syncDBs[repo], fp.toPackage())
+			if len(fp.Files) > 0 {
+				syncFiles[repo+"/"+fp.Name] = fp.Files
+			}
+		}
+	}
+
+	r := newFixtureRepository(installed, syncDBs)
+	r.files = files
+	r.syncFiles = syncFiles
+	r.cache = fixture.Cache
+	r.log = fixture.Log
+	r.backups = backups
+	r.configs = fixture.Configs
+	r.altered = altered
+	return r, nil
+}
+
+func newFixtureRepository(installed []*domain.Package, syncDBs map[string][]*domain.Package) *FixtureRepository {
+	r := &FixtureRepository{
+		installed: make(map[string]*domain.Package, len(installed)),
+		syncDBs:   syncDBs,
+	}
+	for _, pkg := range installed {
+		r.installed[pkg.Name] = pkg
+	}
+	for repo := range syncDBs {
+		r.repoOrder = append(r.repoOrder, repo)
+	}
+	sort.Strings(r.repoOrder)
+	return r
+}
+
+func (r *FixtureRepository) GetInstalled() ([]*domain.Package, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	result := r.installedPackages()
+
+	pkgToRepo := make(map[string]string)
+	pkgToVersion := make(map[string]string)
+	for _, repo := range r.repoOrder {
+		for _, pkg := range r.syncDBs[repo] {
+			if _, seen := pkgToRepo[pkg.Name]; seen {
+				continue
+			}
+			pkgToRepo[pkg.Name] = repo
+			pkgToVersion[pkg.Name] = pkg.Version
+		}
+	}
+
+	markForeign(result, pkgToRepo, pkgToVersion)
+
+	for _, pkg := range result {
+		for _, backup := range r.backups[pkg.Name] {
+			for _, suffix := range []string{".pacnew", ".pacsave"} {
+				if _, ok := r.configs["/"+backup+suffix]; ok {
+					pkg.PendingConfigs = append(pkg.PendingConfigs, "/"+backup+suffix)
+				}
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// installedPackages returns sorted copies of the installed packages with
+// reverse dependencies computed. The caller must hold r.mu.
+func (r *FixtureRepository) installedPackages() []*domain.Package {
+	result := make([]*domain.Package, 0, len(r.installed))
+	for _, pkg := range r.installed {
+		clone := *pkg
+		result = append(result, &clone)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+
+	computeOrphans(result)
+	computeUnneeded(result)
+	computeBroken(result)
+	return result
+}
+
+func (r *FixtureRepository) Search(query string) ([]*domain.Package, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	result := make([]*domain.Package, 0)
+	queryLower := strings.ToLower(query)
+
+	for _, repo := range r.repoOrder {
+		for _, pkg := range r.syncDBs[repo] {
+			if !strings.Contains(strings.ToLower(pkg.Name), queryLower) &&
+				!strings.Contains(strings.ToLower(pkg.Description), queryLower) {
+				continue
+			}
+
+			result = append(result, r.syncResult(repo, pkg))
+		}
+	}
+
+	return result, nil
+}
+
+// syncResult returns a copy of a sync package carrying its repository and
+// the install state of the local package with the same name.
+func (r *FixtureRepository) syncResult(repo string, pkg *domain.Package) *domain.Package {
+	p := *pkg
+	p.Repository = repo
+	if local, ok := r.installed[pkg.Name]; ok {
+		p.Installed = true
+		p.InstallDate = local.InstallDate
+		p.InstallReason = local.InstallReason
+	}
+	return &p
+}
+
+// SearchFiles searches the .files databases of a database directory fixture,
+// or the sync file lists of a JSON fixture.
+func (r *FixtureRepository) SearchFiles(query string) ([]*domain.Package, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	q := newFileQuery(query)
+	result := make([]*domain.Package, 0)
+	found := len(r.syncFiles) > 0
+	for _, repo := range r.repoOrder {
+		if path := filesDBPath([]string{r.syncDir}, repo); r.syncDir != "" && path != "" {
+			found = true
+			pkgs, err := searchFilesDBFile(path, q)
+			if err != nil {
+				return nil, err
+			}
+			for _, pkg := range pkgs {
+				result = append(result, r.syncResult(repo, pkg))
+			}
+			continue
+		}
+
+		for _, pkg := range r.syncDBs[repo] {
+			if matched := q.matchFiles(r.syncFiles[repo+"/"+pkg.Name]); len(matched) > 0 {
+				p := r.syncResult(repo, pkg)
+				p.MatchedFiles = matched
+				result = append(result, p)
+			}
+		}
+	}
+
+	if !found {
+		return nil, errNoFileDBs
+	}
+	return result, nil
+}
+
+// findSync returns the first sync package with the given name, in repo order.
+func (r *FixtureRepository) findSync(name string) *domain.Package {
+	for _, repo := range r.repoOrder {
+		for _, pkg := range r.syncDBs[repo] {
+			if pkg.Name == name {
+				return pkg
+			}
+		}
+	}
+	return nil
+}
+
+func (r *FixtureRepository) Install(names []string, password string, out chan<- string) error {
+	if len(names) == 0 {
+		return fmt.Errorf("no packages specified for installation")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, name := range names {
+		if r.findSync(name) == nil {
+			out <- "error: target not found: " + name
+			return fmt.Errorf("failed to install packages: target not found: %s", name)
+		}
+	}
+
+	var install func(name string, reason domain.InstallReason)
+	install = func(name string, reason domain.InstallReason) {
+		syncPkg := r.findSync(name)
+		if syncPkg == nil {
+			return
+		}
+		existing, isInstalled := r.installed[name]
+		if isInstalled && reason == domain.ReasonDependency {
+			return
+		}
+
+		pkg := *syncPkg
+		pkg.Installed = true
+		pkg.InstallDate = time.Now()
+		pkg.InstallReason = reason
+		if isInstalled {
+			pkg.InstallReason = existing.InstallReason
+		}
+		r.installed[name] = &pkg
+		out <- fmt.Sprintf("installing %s (%s)...", pkg.Name, pkg.Version)
+
+		for _, dep := range pkg.Dependencies {
+			install(depName(dep), domain.ReasonDependency)
+		}
+	}
+
+	for _, name := range names {
+		install(name, domain.ReasonExplicit)
+	}
+
+	return nil
+}
+
+// InstallFiles installs cached archives by name and version only, replacing
+// the installed version of the package if there is one.
+func (r *FixtureRepository) InstallFiles(paths []string, password string, out chan<- string) error {
+	if len(paths) == 0 {
+		return fmt.Errorf("no package files specified for installation")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, path := range paths {
+		name, version, _, ok := parseArchiveName(filepath.Base(path))
+		if !ok {
+			out <- "error: invalid package file: " + path
+			return fmt.Errorf("failed to install package files: invalid package file: %s", path)
+		}
+
+		var pkg domain.Package
+		if existing, ok := r.installed[name]; ok {
+			pkg = *existing
+			verb := "reinstalling"
+			switch cmp := alpm.VerCmp(version, existing.Version); {
+			case cmp < 0:
+				verb = "downgrading"
+			case cmp > 0:
+				verb = "upgrading"
+			}
+			out <- fmt.Sprintf("%s %s (%s => %s)...", verb, name, existing.Version, version)
+		} else {
+			if syncPkg := r.findSync(name); syncPkg != nil {
+				pkg = *syncPkg
+			}
+			pkg.Name = name
+			pkg.InstallReason = domain.ReasonExplicit
+			out <- fmt.Sprintf("installing %s (%s)...", name, version)
+		}
+
+		pkg.Version = version
+		pkg.Installed = true
+		pkg.InstallDate = time.Now()
+		r.installed[name] = &pkg
+	}
+
+	return nil
+}
+
+func (r *FixtureRepository) Remove(names []string, opts domain.RemoveOptions, password string, out chan<- string) error {
+	if len(names) == 0 {
+		return fmt.Errorf("no packages specified for removal")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	plan, err := domain.PlanRemoval(r.installedPackages(), names, opts)
+	if err != nil {
+		out <- "error: " + err.Error()
+		return fmt.Errorf("failed to remove packages: %w", err)
+	}
+
+	if len(plan.Broken) > 0 {
+		broken := plan.Broken[0]
+		out <- "error: failed to prepare transaction (could not satisfy dependencies)"
+		for _, b := range plan.Broken {
+			for _, dependent := range b.RequiredBy {
+				out <- fmt.Sprintf(":: removing %s breaks dependency '%s' required by %s", b.Name, b.Name, dependent)
+			}
+		}
+		return fmt.Errorf("failed to remove packages: %s is required by %s", broken.Name, broken.RequiredBy[0])
+	}
+
+	for _, pkg := range plan.Removed {
+		out <- fmt.Sprintf("removing %s (%s)...", pkg.Name, pkg.Version)
+		delete(r.installed, pkg.Name)
+	}
+
+	return nil
+}
+
+func (r *FixtureRepository) SetInstallReason(names []string, reason domain.InstallReason, password string, out chan<- string) error {
+	if len(names) == 0 {
+		return fmt.Errorf("no packages specified")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, name := range names {
+		if _, ok := r.installed[name]; !ok {
+			out <- fmt.Sprintf("error: could not set install reason for package %s (could not find or read package)", name)
+			return fmt.Errorf("failed to set install reason: target not found: %s", name)
+		}
+	}
+
+	description := "explicitly installed"
+	if reason == domain.ReasonDependency {
+		description = "installed as dependency"
+	}
+
+	for _, name := range names {
+		r.installed[name].InstallReason = reason
+		out <- fmt.Sprintf("%s: install reason has been set to '%s'", name, description)
+	}
+
+	return nil
+}
+
+func (r *FixtureRepository) PendingUpgrades() ([]domain.Upgrade, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.pendingUpgrades(), nil
+}
+
+func (r *FixtureRepository) pendingUpgrades() []domain.Upgrade {
+	upgrades := make([]domain.Upgrade, 0)
+	for name, local := range r.installed {
+		syncPkg := r.findSync(name)
+		if syncPkg == nil || alpm.VerCmp(syncPkg.Version, local.Version) <= 0 {
+			continue
+		}
+		upgrades = append(upgrades, domain.Upgrade{
+			Name:         name,
+			Repository:   syncPkg.Repository,
+			OldVersion:   local.Version,
+			NewVersion:   syncPkg.Version,
+			DownloadSize: syncPkg.DownloadSize,
+		})
+	}
+
+	sort.Slice(upgrades, func(i, j int) bool { return upgrades[i].Name < upgrades[j].Name })
+	return upgrades
+}
+
+func (r *FixtureRepository) Upgrade(password string, out chan<- string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	upgrades := r.pendingUpgrades()
+	if len(upgrades) == 0 {
+		out <- " there is nothing to do"
+		return nil
+	}
+
+	for _, upgrade := range upgrades {
+		out <- fmt.Sprintf("upgrading %s (%s -> %s)...", upgrade.Name, upgrade.OldVersion, upgrade.NewVersion)
+
+		local := r.installed[upgrade.Name]
+		pkg := *r.findSync(upgrade.Name)
+		pkg.Installed = true
+		pkg.InstallDate = time.Now()
+		pkg.InstallReason = local.InstallReason
+		r.installed[upgrade.Name] = &pkg
+	}
+
+	return nil
+}
+
+// SyncDatabases only reports success: fixture sync databases are static.
+func (r *FixtureRepository) SyncDatabases(out chan<- string) error {
+	out <- ":: Synchronizing package databases..."
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, repo := range r.repoOrder {
+		out <- fmt.Sprintf(" %s is up to date", repo)
+	}
+	return nil
+}
+
+// SyncFileDatabases only reports success: fixture file lists are static.
+func (r *FixtureRepository) SyncFileDatabases(out chan<- string) error {
+	out <- ":: Synchronizing package file databases..."
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, repo := range r.repoOrder {
+		out <- fmt.Sprintf(" %s is up to date", repo)
+	}
+	return nil
+}
+
+func (r *FixtureRepository) Owns(targets []string) ([]domain.FileOwner, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	names := make([]string, 0, len(r.installed))
+	for name := range r.installed {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	q := newOwnsQuery(targets)
+	for _, name := range names {
+		pkg := r.installed[name]
+		files, err := r.fileNames(pkg)
+		if err != nil {
+			return nil, err
+		}
+		q.add(pkg.Name, pkg.Version, files)
+	}
+	return q.result(), nil
+}
+
+// fileNames returns the raw file list entries of an installed package.
+// The caller must hold r.mu.
+func (r *FixtureRepository) fileNames(pkg *domain.Package) ([]string, error) {
+	if r.localDir == "" {
+		return r.files[pkg.Name], nil
+	}
+
+	names, err := readLocalFiles(filepath.Join(r.localDir, pkg.Name+"-"+pkg.Version))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	return names, nil
+}
+
+// Files returns the fixture's file list for name. Sizes are not read from
+// disk because the paths describe another system.
+func (r *FixtureRepository) Files(name string) ([]domain.PackageFile, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	pkg, ok := r.installed[name]
+	if !ok {
+		return nil, fmt.Errorf("package not found: %s", name)
+	}
+
+	names, err := r.fileNames(pkg)
+	if err != nil {
+		return nil, err
+	}
+	return statFiles("", names), nil
+}
+
+func (r *FixtureRepository) CachedPackages() ([]domain.CachedPackage, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	installed := make(map[string]string, len(r.installed))
+	for name, pkg := range r.installed {
+		installed[name] = pkg.Version
+	}
+
+	var archives []domain.CachedPackage
+	if r.cacheDir != "" {
+		var err error
+		if archives, err = readCache([]string{r.cacheDir}, installed); err != nil {
+			return nil, err
+		}
+	} else {
+		for _, entry := range r.cache {
+			name, version, arch, ok := parseArchiveName(entry.File)
+			if !ok {
+				continue
+			}
+			archives = append(archives, domain.CachedPackage{
+				Name:             name,
+				Version:          version,
+				Architecture:     arch,
+				Path:             filepath.Join(DefaultCacheDir, entry.File),
+				Size:             entry.Size,
+				ModTime:          entry.Modified,
+				InstalledVersion: installed[name],
+			})
+		}
+		sortCache(archives)
+	}
+
+	result := make([]domain.CachedPackage, 0, len(archives))
+	for _, archive := range archives {
+		if !r.cleaned[archive.Path] {
+			result = append(result, archive)
+		}
+	}
+	return result, nil
+}
+
+// RemoveCachedPackages only hides the archives, leaving any files on disk.
+func (r *FixtureRepository) RemoveCachedPackages(paths []string, password string, out chan<- string) error {
+	if len(paths) == 0 {
+		return fmt.Errorf("no package files specified for removal")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.cleaned == nil {
+		r.cleaned = make(map[string]bool)
+	}
+	for _, path := range paths {
+		r.cleaned[path] = true
+		out <- fmt.Sprintf("removed '%s'", path)
+	}
+	return nil
+}
+
+func (r *FixtureRepository) OrphanedConfigs() ([]domain.ConfigFile, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	backups := make(map[string]bool)
+	for name := range r.installed {
+		for _, backup := range r.backups[name] {
+			backups["/"+backup] = true
+		}
+	}
+
+	var files []domain.ConfigFile
+	for path := range r.configs {
+		if strings.HasSuffix(path, ".pacsave") && !backups[strings.TrimSuffix(path, ".pacsave")] {
+			files = append(files, domain.NewConfigFile("", path))
+		}
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].New < files[j].New })
+	return files, nil
+}
+
+func (r *FixtureRepository) ReadConfigFile(path string) (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	content, ok := r.configs[path]
+	if !ok {
+		return "", fmt.Errorf("failed to read %s: %w", path, os.ErrNotExist)
+	}
+	return content, nil
+}
+
+// ResolveConfigFile only changes the fixture's in-memory configuration files.
+func (r *FixtureRepository) ResolveConfigFile(file domain.ConfigFile, replace bool, password string, out chan<- string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	content, ok := r.configs[file.New]
+	if !ok {
+		return fmt.Errorf("failed to resolve %s: %w", file.New, os.ErrNotExist)
+	}
+
+	delete(r.configs, file.New)
+	if replace {
+		if live, ok := r.configs[file.Path]; ok {
+			backup := file.Path + configBackupSuffix
+			r.configs[backup] = live
+			out <- fmt.Sprintf("renamed '%s' -> '%s' (backup: '%s')", file.New, file.Path, backup)
+		} else {
+			out <- fmt.Sprintf("renamed '%s' -> '%s'", file.New, file.Path)
+		}
+		r.configs[file.Path] = content
+	} else {
+		out <- fmt.Sprintf("removed '%s'", file.New)
+	}
+	return nil
+}
+
+func (r *FixtureRepository) History() ([]domain.Transaction, error) {
+	if r.logFile != "" {
+		return r.history.read(r.logFile)
+	}
+	return parsePacmanLog(strings.NewReader(strings.Join(r.log, "\n")))
+}
+
+// VerifyPackage reports the problems listed in a JSON fixture's "altered"
+// entries. Nothing is read from disk because the paths describe another
+// system.
+func (r *FixtureRepository) VerifyPackage(ctx context.Context, name string) (domain.VerifyResult, error) {
+	if err := ctx.Err(); err != nil {
+		return domain.VerifyResult{}, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	pkg, ok := r.installed[name]
+	if !ok {
+		return domain.VerifyResult{}, fmt.Errorf("package not found: %s", name)
+	}
+
+	names, err := r.fileNames(pkg)
+	if err != nil {
+		return domain.VerifyResult{}, err
+	}
+
+	result := domain.VerifyResult{Package: name, Files: len(names)}
+	for path, problem := range r.altered[name] {
+		result.Issues = append(result.Issues, domain.VerifyIssue{Path: "/" + path, Problem: problem})
+	}
+	sort.Slice(result.Issues, func(i, j int) bool {
+		return result.Issues[i].Path < result.Issues[j].Path
+	})
+	return result, nil
+}
+
+// Refresh is a no-op: the fixture is only read once, so simulated
+// transactions survive reloads.
+func (r *FixtureRepository) Refresh() error {
+	return nil
+}
+
+var _ Repository = (*FixtureRepository)(nil)
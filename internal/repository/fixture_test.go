@@ -0,0 +1,248 @@
+package repository
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/sjsanc/pacviz/v3/internal/domain"
+)
+
+func findPackage(pkgs []*domain.Package, name string) *domain.Package {
+	for _, pkg := range pkgs {
+		if pkg.Name == name {
+			return pkg
+		}
+	}
+	return nil
+}
+
+// writeSyncDB writes a gzip-compressed sync database containing the given desc files.
+func writeSyncDB(t *testing.T, path string, descs map[string]string) {
+	t.Helper()
+
+	entries := make(map[string]map[string]string, len(descs))
+	for dir, desc := range descs {
+		entries[dir] = map[string]string{"desc": desc}
+	}
+	writeDBArchive(t, path, entries)
+}
+
+// writeDBArchive writes a gzip-compressed database archive holding, for each
+// package directory, the given entries ("desc", "files").
+func writeDBArchive(t *testing.T, path string, dirs map[string]map[string]string) {
+	t.Helper()
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for dir, entries := range dirs {
+		if err := tw.WriteHeader(&tar.Header{Name: dir + "/", Typeflag: tar.TypeDir, Mode: 0755}); err != nil {
+			t.Fatal(err)
+		}
+		for name, content := range entries {
+			if err := tw.WriteHeader(&tar.Header{Name: dir + "/" + name, Typeflag: tar.TypeReg, Mode: 0644, Size: int64(len(content))}); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := tw.Write([]byte(content)); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	tw.Close()
+	gz.Close()
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestParseDesc(t *testing.T) {
+	f, err := os.Open("testdata/dbpath/local/bash-5.2.026-2/desc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	desc, err := parseDesc(f)
+	if err != nil {
+		t.Fatalf("parseDesc failed: %v", err)
+	}
+
+	pkg := desc.toPackage()
+	if pkg.Name != "bash" || pkg.Version != "5.2.026-2" {
+		t.Errorf("got %s %s, want bash 5.2.026-2", pkg.Name, pkg.Version)
+	}
+	if pkg.InstallReason != domain.ReasonDependency {
+		t.Error("expected dependency install reason")
+	}
+	if pkg.InstalledSize != 9437184 {
+		t.Errorf("InstalledSize = %d, want 9437184", pkg.InstalledSize)
+	}
+	if len(pkg.Dependencies) != 2 || pkg.Dependencies[0] != "glibc>=2.38" {
+		t.Errorf("Dependencies = %v, want [glibc>=2.38 readline]", pkg.Dependencies)
+	}
+	if pkg.OptDepends["bash-completion"] != "for tab completion" {
+		t.Errorf("OptDepends = %v", pkg.OptDepends)
+	}
+	if len(pkg.Provides) != 1 || pkg.Provides[0] != "sh" {
+		t.Errorf("Provides = %v, want [sh]", pkg.Provides)
+	}
+}
+
+func TestFixtureRepository_LocalDB(t *testing.T) {
+	dbPath := t.TempDir()
+	if err := os.CopyFS(dbPath, os.DirFS("testdata/dbpath")); err != nil {
+		t.Fatal(err)
+	}
+	writeSyncDB(t, filepath.Join(dbPath, "sync", "core.db"), map[string]string{
+		"glibc-2.39-1":   "%NAME%\nglibc\n\n%VERSION%\n2.39-1\n\n%ISIZE%\n48500000\n",
+		"bash-5.2.026-2": "%NAME%\nbash\n\n%VERSION%\n5.2.026-2\n\n%DEPENDS%\nglibc\nreadline\n",
+	})
+
+	repo, err := NewFixtureRepository(dbPath)
+	if err != nil {
+		t.Fatalf("NewFixtureRepository failed: %v", err)
+	}
+
+	pkgs, err := repo.GetInstalled()
+	if err != nil {
+		t.Fatalf("GetInstalled failed: %v", err)
+	}
+	if len(pkgs) != 3 {
+		t.Fatalf("expected 3 installed packages, got %d", len(pkgs))
+	}
+
+	glibc := findPackage(pkgs, "glibc")
+	if glibc.Repository != "core" || !glibc.HasUpdate || glibc.NewVersion != "2.39-1" {
+		t.Errorf("glibc: repo=%q hasUpdate=%v new=%q", glibc.Repository, glibc.HasUpdate, glibc.NewVersion)
+	}
+	if glibc.DependencyCount != 2 {
+		t.Errorf("glibc DependencyCount = %d, want 2", glibc.DependencyCount)
+	}
+
+	nano := findPackage(pkgs, "nano")
+	if !nano.IsForeign || nano.Repository != "foreign" {
+		t.Errorf("nano should be foreign, got repo %q", nano.Repository)
+	}
+
+	results, err := repo.Search("glibc")
+	if err != nil || len(results) != 1 || !results[0].Installed {
+		t.Errorf("Search(glibc) = %v, %v", results, err)
+	}
+}
+
+func TestFixtureRepository_JSON(t *testing.T) {
+	repo, err := NewFixtureRepository("testdata/fixture.json")
+	if err != nil {
+		t.Fatalf("NewFixtureRepository failed: %v", err)
+	}
+
+	pkgs, err := repo.GetInstalled()
+	if err != nil {
+		t.Fatalf("GetInstalled failed: %v", err)
+	}
+
+	if pkg := findPackage(pkgs, "libfoo"); pkg == nil || !pkg.IsOrphan {
+		t.Error("expected libfoo to be an orphan")
+	}
+	if pkg := findPackage(pkgs, "yay-bin"); pkg == nil || !pkg.IsForeign {
+		t.Error("expected yay-bin to be foreign")
+	}
+	if pkg := findPackage(pkgs, "bash"); pkg == nil || pkg.Dependencies[0] != "glibc>=2.38" {
+		t.Error("expected bash dependency constraint to be kept")
+	}
+	if pkg := findPackage(pkgs, "yay-bin"); pkg == nil || len(pkg.UnsatisfiedDeps) != 1 || pkg.UnsatisfiedDeps[0] != "pacman (missing)" {
+		t.Errorf("expected yay-bin to miss pacman, got %v", pkg.UnsatisfiedDeps)
+	}
+}
+
+// discard returns an output channel whose lines are dropped.
+func discard(t *testing.T) chan<- string {
+	out := make(chan string)
+	go func() {
+		for range out {
+		}
+	}()
+	t.Cleanup(func() { close(out) })
+	return out
+}
+
+func TestFixtureRepository_InstallRemove(t *testing.T) {
+	repo, err := NewFixtureRepository("testdata/fixture.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := discard(t)
+
+	if err := repo.Install([]string{"missing"}, "", out); err == nil {
+		t.Error("expected error installing unknown package")
+	}
+
+	if err := repo.Install([]string{"ripgrep"}, "", out); err != nil {
+		t.Fatalf("Install failed: %v", err)
+	}
+	pkgs, _ := repo.GetInstalled()
+	if pkg := findPackage(pkgs, "ripgrep"); pkg == nil || pkg.InstallReason != domain.ReasonExplicit {
+		t.Error("expected ripgrep installed explicitly")
+	}
+	if pkg := findPackage(pkgs, "pcre2"); pkg == nil || pkg.InstallReason != domain.ReasonDependency {
+		t.Error("expected pcre2 pulled in as a dependency")
+	}
+
+	if err := repo.Remove([]string{"pcre2"}, domain.RemoveOptions{}, "", out); err == nil {
+		t.Error("expected error removing a required package without cascade")
+	}
+	if err := repo.Remove([]string{"pcre2"}, domain.RemoveOptions{Cascade: true}, "", out); err != nil {
+		t.Fatalf("cascade Remove failed: %v", err)
+	}
+	pkgs, _ = repo.GetInstalled()
+	if findPackage(pkgs, "ripgrep") != nil || findPackage(pkgs, "pcre2") != nil {
+		t.Error("expected ripgrep and pcre2 to be removed")
+	}
+}
+
+func TestFixtureRepository_Files(t *testing.T) {
+	repo, err := NewFixtureRepository("testdata/dbpath")
+	if err != nil {
+		t.Fatalf("NewFixtureRepository: %v", err)
+	}
+
+	files, err := repo.Files("bash")
+	if err != nil {
+		t.Fatalf("Files: %v", err)
+	}
+	if len(files) != 6 || files[4].Path != "/usr/bin/bash" || files[4].IsDir || !files[3].IsDir {
+		t.Errorf("unexpected files: %+v", files)
+	}
+
+	if files, err := repo.Files("nano"); err != nil || len(files) != 0 {
+		t.Errorf("expected no files for nano, got %v, %v", files, err)
+	}
+	if _, err := repo.Files("missing"); err == nil {
+		t.Error("expected an error for a package that is not installed")
+	}
+}
+
+func TestStatFiles(t *testing.T) {
+	root := t.TempDir()
+	os.MkdirAll(filepath.Join(root, "usr", "bin"), 0o755)
+	os.WriteFile(filepath.Join(root, "usr", "bin", "tool"), []byte("12345"), 0o755)
+
+	files := statFiles(root, []string{"usr/bin/", "usr/bin/tool", "usr/bin/gone"})
+	want := []domain.PackageFile{
+		{Path: "/usr/bin/", IsDir: true},
+		{Path: "/usr/bin/tool", Size: 5},
+		{Path: "/usr/bin/gone", Missing: true},
+	}
+	if !reflect.DeepEqual(files, want) {
+		t.Errorf("statFiles = %+v, want %+v", files, want)
+	}
+}
@@ -0,0 +1,213 @@
+package repository
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sjsanc/pacviz/v3/internal/domain"
+)
+
+// descEntry holds the %SECTION% values of a pacman database desc file.
+type descEntry map[string][]string
+
+// parseDesc parses the %SECTION%-delimited format used by both the local
+// database (local/<pkg>/desc) and the sync database archives.
+func parseDesc(r io.Reader) (descEntry, error) {
+	entry := make(descEntry)
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	section := ""
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			section = ""
+			continue
+		}
+		if section == "" && strings.HasPrefix(line, "%") && strings.HasSuffix(line, "%") && len(line) > 2 {
+			section = strings.Trim(line, "%")
+			if _, ok := entry[section]; !ok {
+				entry[section] = []string{}
+			}
+			continue
+		}
+		if section != "" {
+			entry[section] = append(entry[section], line)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return entry, nil
+}
+
+func (e descEntry) value(section string) string {
+	if values := e[section]; len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+func (e descEntry) list(section string) []string {
+	values := e[section]
+	if values == nil {
+		return []string{}
+	}
+	result := make([]string, len(values))
+	copy(result, values)
+	return result
+}
+
+func (e descEntry) int64(section string) int64 {
+	n, _ := strconv.ParseInt(e.value(section), 10, 64)
+	return n
+}
+
+func (e descEntry) time(section string) time.Time {
+	n := e.int64(section)
+	if n == 0 {
+		return time.Time{}
+	}
+	return time.Unix(n, 0)
+}
+
+// toPackage converts a desc entry into a domain package. Local entries store
+// the installed size in %SIZE%, sync entries in %ISIZE%.
+func (e descEntry) toPackage() *domain.Package {
+	installReason := domain.ReasonExplicit
+	if e.value("REASON") == "1" {
+		installReason = domain.ReasonDependency
+	}
+
+	size := e.int64("SIZE")
+	if _, ok := e["ISIZE"]; ok {
+		size = e.int64("ISIZE")
+	}
+
+	optDeps := make(map[string]string)
+	for _, od := range e["OPTDEPENDS"] {
+		parts := strings.SplitN(od, ": ", 2)
+		if len(parts) == 2 {
+			optDeps[depName(parts[0])] = parts[1]
+		} else {
+			optDeps[depName(parts[0])] = ""
+		}
+	}
+
+	return &domain.Package{
+		Name:          e.value("NAME"),
+		Version:       e.value("VERSION"),
+		Description:   e.value("DESC"),
+		Architecture:  e.value("ARCH"),
+		URL:           e.value("URL"),
+		Licenses:      e.list("LICENSE"),
+		Groups:        e.list("GROUPS"),
+		Dependencies:  e.list("DEPENDS"),
+		OptDepends:    optDeps,
+		Conflicts:     e.list("CONFLICTS"),
+		Provides:      e.list("PROVIDES"),
+		Replaces:      e.list("REPLACES"),
+		InstallDate:   e.time("INSTALLDATE"),
+		InstallReason: installReason,
+		InstalledSize: size,
+		DownloadSize:  e.int64("CSIZE"),
+		Packager:      e.value("PACKAGER"),
+		BuildDate:     e.time("BUILDDATE"),
+	}
+}
+
+// depName strips the version constraint from a dependency string ("glibc>=2.38" -> "glibc").
+func depName(dep string) string {
+	name, _, _ := parseDep(dep)
+	return name
+}
+
+// readLocalDB reads every package entry in a pacman local database directory.
+func readLocalDB(dir string) ([]*domain.Package, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read local database: %w", err)
+	}
+
+	packages := make([]*domain.Package, 0, len(entries))
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+
+		f, err := os.Open(filepath.Join(dir, entry.Name(), "desc"))
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, fmt.Errorf("failed to open %s: %w", entry.Name(), err)
+		}
+		desc, err := parseDesc(f)
+		f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", entry.Name(), err)
+		}
+
+		pkg := desc.toPackage()
+		pkg.Installed = true
+		packages = append(packages, pkg)
+	}
+
+	return packages, nil
+}
+
+// readSyncDB reads the package entries of a sync database archive (<repo>.db),
+// which is a tar archive, optionally gzip-compressed.
+func readSyncDB(path string) ([]*domain.Package, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read sync database: %w", err)
+	}
+	return parseSyncDB(data)
+}
+
+func parseSyncDB(data []byte) ([]*domain.Package, error) {
+	var r io.Reader = bytes.NewReader(data)
+	if len(data) >= 2 && data[0] == 0x1f && data[1] == 0x8b {
+		gz, err := gzip.NewReader(r)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decompress sync database: %w", err)
+		}
+		defer gz.Close()
+		r = gz
+	}
+
+	packages := make([]*domain.Package, 0)
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read sync database: %w", err)
+		}
+		if hdr.Typeflag != tar.TypeReg || filepath.Base(hdr.Name) != "desc" {
+			continue
+		}
+
+		desc, err := parseDesc(tr)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", hdr.Name, err)
+		}
+		packages = append(packages, desc.toPackage())
+	}
+
+	return packages, nil
+}
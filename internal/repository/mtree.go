@@ -0,0 +1,279 @@
+package repository
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/sjsanc/pacviz/v3/internal/domain"
+)
+
+// mtreeEntry is one path of a package's mtree file with its keywords
+// ("type", "mode", "uid", "sha256digest", ...), /set defaults included.
+type mtreeEntry struct {
+	path     string // relative to the root, e.g. "usr/bin/bash"
+	keywords map[string]string
+}
+
+// parseMtree reads the mtree data pacman stores for each installed package
+// (local/<pkg>/mtree), which is usually gzip-compressed. Package metadata
+// such as .PKGINFO is skipped.
+func parseMtree(r io.Reader) ([]mtreeEntry, error) {
+	br := bufio.NewReader(r)
+	if magic, _ := br.Peek(2); len(magic) == 2 && magic[0] == 0x1f && magic[1] == 0x8b {
+		gz, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decompress mtree: %w", err)
+		}
+		defer gz.Close()
+		r = gz
+	} else {
+		r = br
+	}
+
+	var entries []mtreeEntry
+	defaults := make(map[string]string)
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+
+		switch fields[0] {
+		case "/set":
+			for _, field := range fields[1:] {
+				if key, value, ok := strings.Cut(field, "="); ok {
+					defaults[key] = value
+				}
+			}
+			continue
+		case "/unset":
+			for _, key := range fields[1:] {
+				delete(defaults, key)
+			}
+			continue
+		}
+
+		path := strings.TrimPrefix(unescapeMtree(fields[0]), "./")
+		if path == "." || strings.HasPrefix(path, ".") {
+			continue
+		}
+
+		keywords := make(map[string]string, len(defaults)+len(fields)-1)
+		for key, value := range defaults {
+			keywords[key] = value
+		}
+		for _, field := range fields[1:] {
+			if key, value, ok := strings.Cut(field, "="); ok {
+				keywords[key] = value
+			}
+		}
+		entries = append(entries, mtreeEntry{path: path, keywords: keywords})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read mtree: %w", err)
+	}
+
+	return entries, nil
+}
+
+// unescapeMtree decodes the octal escapes ("\040") mtree uses in paths.
+func unescapeMtree(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+3 < len(s) {
+			if n, err := strconv.ParseUint(s[i+1:i+4], 8, 8); err == nil {
+				b.WriteByte(byte(n))
+				i += 3
+				continue
+			}
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+// readMtree parses the mtree file of a local database entry directory.
+func readMtree(dir string) ([]mtreeEntry, error) {
+	f, err := os.Open(filepath.Join(dir, "mtree"))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("no mtree file")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open mtree: %w", err)
+	}
+	defer f.Close()
+
+	return parseMtree(f)
+}
+
+// verifyMtree checks the files below root against their mtree entries,
+// reporting problems in pacman -Qkk's words. Backup files are expected to
+// be edited, so only their type, permissions and ownership are checked.
+// Cancelling ctx stops the check before the next file.
+func verifyMtree(ctx context.Context, root string, entries []mtreeEntry, backups map[string]bool) (domain.VerifyResult, error) {
+	var result domain.VerifyResult
+	for _, entry := range entries {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+		result.Files++
+		for _, problem := range verifyMtreeEntry(root, entry, backups[entry.path]) {
+			result.Issues = append(result.Issues, domain.VerifyIssue{Path: "/" + entry.path, Problem: problem})
+		}
+	}
+	return result, nil
+}
+
+func verifyMtreeEntry(root string, entry mtreeEntry, backup bool) []string {
+	path := filepath.Join(root, entry.path)
+	info, err := os.Lstat(path)
+	if err != nil {
+		return []string{describeFileError(err)}
+	}
+
+	kw := entry.keywords
+	kind := kw["type"]
+	if kind == "" {
+		kind = "file"
+	}
+	if mtreeType(info) != kind {
+		return []string{"File type mismatch"}
+	}
+
+	var problems []string
+	if mode, ok := kw["mode"]; ok && kind != "link" {
+		if want, err := strconv.ParseUint(mode, 8, 32); err == nil && uint64(unixPermissions(info.Mode())) != want {
+			problems = append(problems, "Permissions mismatch")
+		}
+	}
+	if stat, ok := info.Sys().(*syscall.Stat_t); ok {
+		if uid, ok := kw["uid"]; ok && uid != strconv.FormatUint(uint64(stat.Uid), 10) {
+			problems = append(problems, "UID mismatch")
+		}
+		if gid, ok := kw["gid"]; ok && gid != strconv.FormatUint(uint64(stat.Gid), 10) {
+			problems = append(problems, "GID mismatch")
+		}
+	}
+	if kind == "link" {
+		if target, err := os.Readlink(path); err != nil || target != unescapeMtree(kw["link"]) {
+			problems = append(problems, "Symlink path mismatch")
+		}
+	}
+	if kind != "file" || backup {
+		return problems
+	}
+
+	if t, ok := kw["time"]; ok {
+		seconds, _, _ := strings.Cut(t, ".")
+		if seconds != strconv.FormatInt(info.ModTime().Unix(), 10) {
+			problems = append(problems, "Modification time mismatch")
+		}
+	}
+	if size, ok := kw["size"]; ok && size != strconv.FormatInt(info.Size(), 10) {
+		// A file of the wrong size cannot have the right checksum.
+		return append(problems, "Size mismatch")
+	}
+
+	var h hash.Hash
+	var want, name string
+	switch {
+	case kw["sha256digest"] != "":
+		h, want, name = sha256.New(), kw["sha256digest"], "SHA256"
+	case kw["md5digest"] != "":
+		h, want, name = md5.New(), kw["md5digest"], "MD5"
+	default:
+		return problems
+	}
+	sum, err := hashFile(path, h)
+	if err != nil {
+		return append(problems, describeFileError(err))
+	}
+	if sum != want {
+		problems = append(problems, name+" checksum mismatch")
+	}
+	return problems
+}
+
+// mtreeType returns the mtree type keyword for a file.
+func mtreeType(info fs.FileInfo) string {
+	switch {
+	case info.Mode().IsRegular():
+		return "file"
+	case info.IsDir():
+		return "dir"
+	case info.Mode()&fs.ModeSymlink != 0:
+		return "link"
+	case info.Mode()&fs.ModeNamedPipe != 0:
+		return "fifo"
+	case info.Mode()&fs.ModeSocket != 0:
+		return "socket"
+	case info.Mode()&fs.ModeCharDevice != 0:
+		return "char"
+	default:
+		return "block"
+	}
+}
+
+// unixPermissions converts a Go file mode to its octal permission bits,
+// setuid, setgid and sticky included.
+func unixPermissions(mode fs.FileMode) uint32 {
+	perm := uint32(mode.Perm())
+	if mode&fs.ModeSetuid != 0 {
+		perm |= 0o4000
+	}
+	if mode&fs.ModeSetgid != 0 {
+		perm |= 0o2000
+	}
+	if mode&fs.ModeSticky != 0 {
+		perm |= 0o1000
+	}
+	return perm
+}
+
+func hashFile(path string, h hash.Hash) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// describeFileError words a stat or read error like pacman's strerror
+// output, e.g. "No such file or directory".
+func describeFileError(err error) string {
+	var pathErr *fs.PathError
+	if errors.As(err, &pathErr) {
+		err = pathErr.Err
+	}
+	msg := err.Error()
+	if msg == "" {
+		return msg
+	}
+	return strings.ToUpper(msg[:1]) + msg[1:]
+}
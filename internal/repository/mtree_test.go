@@ -0,0 +1,127 @@
+package repository
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sjsanc/pacviz/v3/internal/domain"
+)
+
+func TestParseMtree(t *testing.T) {
+	data := strings.Join([]string{
+		"#mtree",
+		"/set type=file uid=0 gid=0 mode=644",
+		"./.BUILDINFO time=1700000000.0 size=100",
+		"./usr time=1700000000.0 mode=755 type=dir",
+		"./usr/share/my\\040file time=1700000000.5 size=3",
+		"/unset uid",
+		"./usr/bin/sh time=1700000000.0 mode=777 type=link link=bash",
+	}, "\n")
+
+	var compressed bytes.Buffer
+	gz := gzip.NewWriter(&compressed)
+	gz.Write([]byte(data))
+	gz.Close()
+
+	want := []mtreeEntry{
+		{path: "usr", keywords: map[string]string{"type": "dir", "uid": "0", "gid": "0", "mode": "755", "time": "1700000000.0"}},
+		{path: "usr/share/my file", keywords: map[string]string{"type": "file", "uid": "0", "gid": "0", "mode": "644", "time": "1700000000.5", "size": "3"}},
+		{path: "usr/bin/sh", keywords: map[string]string{"type": "link", "gid": "0", "mode": "777", "time": "1700000000.0", "link": "bash"}},
+	}
+
+	for name, input := range map[string][]byte{"plain": []byte(data), "gzip": compressed.Bytes()} {
+		got, err := parseMtree(bytes.NewReader(input))
+		if err != nil {
+			t.Fatalf("%s: parseMtree() error = %v", name, err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: parseMtree() = %+v, want %+v", name, got, want)
+		}
+	}
+}
+
+func TestVerifyMtree(t *testing.T) {
+	root := t.TempDir()
+	mtime := time.Unix(1700000000, 0)
+	write := func(name, content string, mode os.FileMode) {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), mode); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chmod(path, mode); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(path, mtime, mtime); err != nil {
+			t.Fatal(err)
+		}
+	}
+	digest := func(content string) string {
+		sum := sha256.Sum256([]byte(content))
+		return hex.EncodeToString(sum[:])
+	}
+
+	write("usr/bin/tool", "tool\n", 0o755)
+	write("usr/bin/script", "script\n", 0o644)
+	write("usr/lib/libtool.so", "edited\n", 0o644)
+	write("usr/share/doc", "longer than packaged\n", 0o644)
+	write("etc/tool.conf", "edited\n", 0o644)
+	if err := os.Symlink("tool", filepath.Join(root, "usr/bin/t")); err != nil {
+		t.Fatal(err)
+	}
+
+	file := func(path, content, mode string) mtreeEntry {
+		return mtreeEntry{path: path, keywords: map[string]string{
+			"mode": mode, "time": "1700000000.0", "size": "7", "sha256digest": digest(content),
+		}}
+	}
+	entries := []mtreeEntry{
+		{path: "usr", keywords: map[string]string{"type": "dir"}},
+		{path: "usr/bin/tool", keywords: map[string]string{"mode": "755", "time": "1700000000.0", "size": "5", "sha256digest": digest("tool\n")}},
+		{path: "usr/bin/t", keywords: map[string]string{"type": "link", "link": "tool"}},
+		file("usr/bin/script", "script\n", "755"),
+		file("usr/lib/libtool.so", "packed\n", "644"),
+		file("usr/share/doc", "packed\n", "644"),
+		file("etc/tool.conf", "packed\n", "644"),
+		file("usr/bin/gone", "packed\n", "644"),
+	}
+
+	got, err := verifyMtree(context.Background(), root, entries, map[string]bool{"etc/tool.conf": true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := domain.VerifyResult{
+		Files: len(entries),
+		Issues: []domain.VerifyIssue{
+			{Path: "/usr/bin/script", Problem: "Permissions mismatch"},
+			{Path: "/usr/lib/libtool.so", Problem: "SHA256 checksum mismatch"},
+			{Path: "/usr/share/doc", Problem: "Size mismatch"},
+			{Path: "/usr/bin/gone", Problem: "No such file or directory"},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("verifyMtree() = %+v, want %+v", got, want)
+	}
+}
+
+func TestVerifyMtree_Cancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	entries := []mtreeEntry{{path: "usr/bin/gone", keywords: map[string]string{}}}
+	if _, err := verifyMtree(ctx, t.TempDir(), entries, nil); !errors.Is(err, context.Canceled) {
+		t.Errorf("verifyMtree() error = %v, want context.Canceled", err)
+	}
+}
@@ -0,0 +1,102 @@
+package repository
+
+import (
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/sjsanc/pacviz/v3/internal/domain"
+)
+
+// ownsQuery matches the file lists of installed packages against the
+// paths and glob patterns given to :owns, like pacman -Qo.
+type ownsQuery struct {
+	queries []string
+	owners  []domain.FileOwner
+}
+
+// newOwnsQuery resolves each target to an absolute path or pattern. Bare
+// names without a slash are looked up in $PATH, and symlinked directories
+// such as /bin are resolved, so "bash" finds /usr/bin/bash.
+func newOwnsQuery(targets []string) *ownsQuery {
+	q := &ownsQuery{}
+	seen := make(map[string]bool, len(targets))
+	for _, target := range targets {
+		query := resolveOwnsTarget(target)
+		if !seen[query] {
+			seen[query] = true
+			q.queries = append(q.queries, query)
+		}
+	}
+	return q
+}
+
+func resolveOwnsTarget(target string) string {
+	if isGlob(target) {
+		if abs, err := filepath.Abs(target); err == nil {
+			return abs
+		}
+		return target
+	}
+
+	if !strings.Contains(target, "/") {
+		if found, err := exec.LookPath(target); err == nil {
+			target = found
+		}
+	}
+
+	abs, err := filepath.Abs(target)
+	if err != nil {
+		return target
+	}
+	if dir, err := filepath.EvalSymlinks(filepath.Dir(abs)); err == nil {
+		abs = filepath.Join(dir, filepath.Base(abs))
+	}
+	return abs
+}
+
+func isGlob(s string) bool {
+	return strings.ContainsAny(s, "*?[")
+}
+
+// add checks one package's file list, given as database entries relative
+// to the root ("usr/bin/bash", "usr/bin/").
+func (q *ownsQuery) add(pkgName, version string, names []string) {
+	for _, name := range names {
+		entry := "/" + strings.TrimSuffix(name, "/")
+		for _, query := range q.queries {
+			matched := entry == query
+			if !matched && isGlob(query) {
+				matched, _ = path.Match(query, entry)
+			}
+			if matched {
+				q.owners = append(q.owners, domain.FileOwner{
+					Query:   query,
+					Path:    "/" + name,
+					Package: pkgName,
+					Version: version,
+				})
+			}
+		}
+	}
+}
+
+// result returns the matches grouped by query, in the order queried. A query
+// nothing matched yields a single entry with an empty Package.
+func (q *ownsQuery) result() []domain.FileOwner {
+	owners := make([]domain.FileOwner, 0, len(q.owners))
+	for _, query := range q.queries {
+		found := false
+		for _, owner := range q.owners {
+			if owner.Query == query {
+				owners = append(owners, owner)
+				found = true
+			}
+		}
+		if !found {
+			owners = append(owners, domain.FileOwner{Query: query})
+		}
+	}
+	return owners
+}
@@ -0,0 +1,47 @@
+package repository
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/sjsanc/pacviz/v3/internal/domain"
+)
+
+func TestOwnsQuery(t *testing.T) {
+	q := newOwnsQuery([]string{"/usr/bin/bash", "/usr/bin/b*", "/usr/bin", "/usr/bin/nothing"})
+	q.add("bash", "5.2.026-2", []string{"usr/", "usr/bin/", "usr/bin/bash"})
+	q.add("busybox", "1.36.1-1", []string{"usr/", "usr/bin/", "usr/bin/busybox"})
+
+	want := []domain.FileOwner{
+		{Query: "/usr/bin/bash", Path: "/usr/bin/bash", Package: "bash", Version: "5.2.026-2"},
+		{Query: "/usr/bin/b*", Path: "/usr/bin/bash", Package: "bash", Version: "5.2.026-2"},
+		{Query: "/usr/bin/b*", Path: "/usr/bin/busybox", Package: "busybox", Version: "1.36.1-1"},
+		{Query: "/usr/bin", Path: "/usr/bin/", Package: "bash", Version: "5.2.026-2"},
+		{Query: "/usr/bin", Path: "/usr/bin/", Package: "busybox", Version: "1.36.1-1"},
+		{Query: "/usr/bin/nothing"},
+	}
+	if got := q.result(); !reflect.DeepEqual(got, want) {
+		t.Errorf("result = %+v, want %+v", got, want)
+	}
+}
+
+func TestResolveOwnsTarget(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "mytool"), []byte("#!/bin/sh\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir)
+
+	realDir, err := filepath.EvalSymlinks(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := resolveOwnsTarget("mytool"), filepath.Join(realDir, "mytool"); got != want {
+		t.Errorf("resolveOwnsTarget(mytool) = %q, want %q", got, want)
+	}
+	if got := resolveOwnsTarget("/usr/lib/libz*"); got != "/usr/lib/libz*" {
+		t.Errorf("globs should be kept as patterns, got %q", got)
+	}
+}
@@ -0,0 +1,159 @@
+package repository
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sjsanc/pacviz/v3/internal/domain"
+)
+
+// DefaultLogFile is pacman's log when pacman.conf sets no LogFile.
+const DefaultLogFile = "/var/log/pacman.log"
+
+var (
+	// logLinePattern splits "[time] [source] message". Logs written before
+	// pacman 4.1 have no source.
+	logLinePattern = regexp.MustCompile(`^\[([^\]]+)\] (?:\[([^\]]+)\] )?(.*)$`)
+
+	// logEventPattern matches "upgraded mesa (24.0.5-1 -> 24.1.2-1)" and
+	// "installed ripgrep (14.1.0-1)".
+	logEventPattern = regexp.MustCompile(`^(installed|upgraded|downgraded|removed|reinstalled) (\S+) \((.+?)(?: -> (.+))?\)$`)
+)
+
+// logTimeLayouts are the timestamp formats pacman has written: ISO 8601
+// with an offset since pacman 5.1, local minutes before.
+var logTimeLayouts = []string{"2006-01-02T15:04:05-0700", "2006-01-02 15:04"}
+
+func parseLogTime(s string) (time.Time, bool) {
+	for _, layout := range logTimeLayouts {
+		if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// parsePacmanLog reads the package transactions in a pacman log, oldest
+// first. A transaction runs from "transaction started" to its completion,
+// and takes its command from the "Running" line before it; older logs
+// without these markers group events by command. Transactions that changed
+// no packages are dropped.
+func parsePacmanLog(r io.Reader) ([]domain.Transaction, error) {
+	var (
+		transactions []domain.Transaction
+		current      *domain.Transaction
+		command      string
+	)
+
+	finish := func() {
+		if current != nil && len(current.Events) > 0 {
+			transactions = append(transactions, *current)
+		}
+		current = nil
+	}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		m := logLinePattern.FindStringSubmatch(scanner.Text())
+		if m == nil {
+			continue
+		}
+		t, ok := parseLogTime(m[1])
+		if !ok {
+			continue
+		}
+		source, message := m[2], m[3]
+		if source != "" && source != "ALPM" && source != "PACMAN" {
+			continue
+		}
+
+		switch {
+		case strings.HasPrefix(message, "Running '"):
+			finish()
+			command = strings.TrimSuffix(strings.TrimPrefix(message, "Running '"), "'")
+		case message == "transaction started":
+			finish()
+			current = &domain.Transaction{Start: t, Command: command}
+		case message == "transaction completed", message == "transaction failed", message == "transaction interrupted":
+			finish()
+		default:
+			event := logEventPattern.FindStringSubmatch(message)
+			if event == nil {
+				continue
+			}
+			if current == nil {
+				current = &domain.Transaction{Start: t, Command: command}
+			}
+			current.Events = append(current.Events, newHistoryEvent(t, event[1], event[2], event[3], event[4]))
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read pacman log: %w", err)
+	}
+	finish()
+
+	return transactions, nil
+}
+
+func newHistoryEvent(t time.Time, action, name, version, newVersion string) domain.HistoryEvent {
+	event := domain.HistoryEvent{Time: t, Action: domain.HistoryAction(action), Package: name}
+	switch event.Action {
+	case domain.ActionUpgraded, domain.ActionDowngraded:
+		event.OldVersion, event.NewVersion = version, newVersion
+	case domain.ActionRemoved:
+		event.OldVersion = version
+	default:
+		event.NewVersion = version
+	}
+	return event
+}
+
+// readPacmanLog parses the pacman log at path.
+func readPacmanLog(path string) ([]domain.Transaction, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open pacman log: %w", err)
+	}
+	defer f.Close()
+
+	return parsePacmanLog(f)
+}
+
+// pacmanLogCache keeps the last parsed pacman log, so that reloads only
+// parse it again once its size or modification time changes.
+type pacmanLogCache struct {
+	mu           sync.Mutex
+	path         string
+	size         int64
+	modTime      time.Time
+	transactions []domain.Transaction
+}
+
+// read returns the transactions in the pacman log at path. The result is
+// shared between calls and must not be modified.
+func (c *pacmanLogCache) read(path string) ([]domain.Transaction, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open pacman log: %w", err)
+	}
+	if path == c.path && info.Size() == c.size && info.ModTime().Equal(c.modTime) {
+		return c.transactions, nil
+	}
+
+	transactions, err := readPacmanLog(path)
+	if err != nil {
+		return nil, err
+	}
+	c.path, c.size, c.modTime, c.transactions = path, info.Size(), info.ModTime(), transactions
+	return transactions, nil
+}
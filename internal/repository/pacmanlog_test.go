@@ -0,0 +1,115 @@
+package repository
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sjsanc/pacviz/v3/internal/domain"
+)
+
+func TestParsePacmanLog(t *testing.T) {
+	log := `[2013-04-01 10:00] upgraded glibc (2.17-3 -> 2.17-5)
+[2013-04-01 10:00] installed ripgrep (0.1-1)
+[2024-01-10T12:00:00+0100] [PACMAN] Running 'pacman -Syu'
+[2024-01-10T12:00:00+0100] [PACMAN] synchronizing package lists
+[2024-01-10T12:00:05+0100] [ALPM] transaction started
+[2024-01-10T12:00:06+0100] [ALPM] upgraded mesa (24.0.5-1 -> 24.1.2-1)
+[2024-01-10T12:00:06+0100] [ALPM] downgraded zstd (1.5.6-1 -> 1.5.5-1)
+[2024-01-10T12:00:06+0100] [ALPM-SCRIPTLET] installed something (1.0)
+[2024-01-10T12:00:07+0100] [ALPM] transaction completed
+[2024-01-10T12:30:00+0100] [PACMAN] Running 'pacman -Sy'
+[2024-01-10T12:30:00+0100] [PACMAN] synchronizing package lists
+[2024-01-11T09:00:00+0100] [PACMAN] Running 'pacman -Rns ripgrep'
+[2024-01-11T09:00:01+0100] [ALPM] transaction started
+[2024-01-11T09:00:01+0100] [ALPM] removed ripgrep (14.1.0-1)
+[2024-01-11T09:00:01+0100] [ALPM] reinstalled bash (5.2.026-2)
+[2024-01-11T09:00:02+0100] [ALPM] transaction failed
+not a log line
+`
+
+	transactions, err := parsePacmanLog(strings.NewReader(log))
+	if err != nil {
+		t.Fatalf("parsePacmanLog() error: %v", err)
+	}
+	if len(transactions) != 3 {
+		t.Fatalf("expected 3 transactions, got %+v", transactions)
+	}
+
+	if tx := transactions[0]; tx.Command != "" || len(tx.Events) != 2 || tx.Events[0].String() != "upgraded glibc (2.17-3 -> 2.17-5)" {
+		t.Errorf("old-style transaction = %+v", tx)
+	}
+
+	tx := transactions[1]
+	if tx.Command != "pacman -Syu" || len(tx.Events) != 2 || tx.Start.UTC().Hour() != 11 {
+		t.Fatalf("upgrade transaction = %+v", tx)
+	}
+	want := domain.HistoryEvent{Time: tx.Events[1].Time, Action: domain.ActionDowngraded, Package: "zstd", OldVersion: "1.5.6-1", NewVersion: "1.5.5-1"}
+	if tx.Events[1] != want {
+		t.Errorf("downgrade = %+v, want %+v", tx.Events[1], want)
+	}
+
+	tx = transactions[2]
+	if tx.Command != "pacman -Rns ripgrep" || tx.Summary() != "1 reinstalled, 1 removed" {
+		t.Errorf("removal transaction = %+v", tx)
+	}
+	if removed := tx.Events[0]; removed.OldVersion != "14.1.0-1" || removed.NewVersion != "" {
+		t.Errorf("removal = %+v", removed)
+	}
+}
+
+func TestFixtureRepository_History(t *testing.T) {
+	dbPath := t.TempDir()
+	if err := os.CopyFS(dbPath, os.DirFS("testdata/dbpath")); err != nil {
+		t.Fatal(err)
+	}
+	log := "[2024-01-10T12:00:05+0000] [ALPM] transaction started\n" +
+		"[2024-01-10T12:00:06+0000] [ALPM] installed bash (5.2.026-2)\n" +
+		"[2024-01-10T12:00:07+0000] [ALPM] transaction completed\n"
+	if err := os.WriteFile(filepath.Join(dbPath, "pacman.log"), []byte(log), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	repo, err := NewFixtureRepository(dbPath)
+	if err != nil {
+		t.Fatalf("NewFixtureRepository failed: %v", err)
+	}
+
+	transactions, err := repo.History()
+	if err != nil {
+		t.Fatalf("History() error: %v", err)
+	}
+	if len(transactions) != 1 || transactions[0].Events[0].String() != "installed bash (5.2.026-2)" {
+		t.Errorf("History() = %+v", transactions)
+	}
+}
+
+func TestPacmanLogCache(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "pacman.log")
+	log := "[2024-01-10T12:00:05+0000] [ALPM] transaction started\n" +
+		"[2024-01-10T12:00:06+0000] [ALPM] installed bash (5.2.026-2)\n" +
+		"[2024-01-10T12:00:07+0000] [ALPM] transaction completed\n"
+	if err := os.WriteFile(path, []byte(log), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var cache pacmanLogCache
+	first, err := cache.read(path)
+	if err != nil || len(first) != 1 {
+		t.Fatalf("read() = %+v, %v", first, err)
+	}
+	if again, _ := cache.read(path); &again[0] != &first[0] {
+		t.Error("expected an unchanged log to be served from the cache")
+	}
+
+	log += "[2024-01-20T08:15:00+0000] [ALPM] transaction started\n" +
+		"[2024-01-20T08:15:01+0000] [ALPM] installed zstd (1.5.5-1)\n" +
+		"[2024-01-20T08:15:02+0000] [ALPM] transaction completed\n"
+	if err := os.WriteFile(path, []byte(log), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if updated, err := cache.read(path); err != nil || len(updated) != 2 {
+		t.Errorf("expected the appended transaction after the log changed, got %+v, %v", updated, err)
+	}
+}
@@ -1,6 +1,10 @@
 package repository
 
-import "github.com/sjsanc/pacviz/v3/internal/domain"
+import (
+	"context"
+
+	"github.com/sjsanc/pacviz/v3/internal/domain"
+)
 
 // Repository defines the interface for package data access.
 type Repository interface {
@@ -10,11 +14,81 @@ type Repository interface {
 	// Search searches sync databases for packages matching the query.
 	Search(query string) ([]*domain.Package, error)
 
-	// Install installs the specified packages and returns the command output.
-	Install(names []string, password string) (string, error)
+	// Install installs the specified packages, sending each line of command
+	// output to out as it is produced. The caller owns out and closes it.
+	Install(names []string, password string, out chan<- string) error
+
+	// InstallFiles installs package archives from disk (pacman -U), such as
+	// an older version from the package cache, streaming output to out.
+	InstallFiles(paths []string, password string, out chan<- string) error
+
+	// Remove removes the specified packages with the given -R modifiers,
+	// streaming command output to out.
+	Remove(names []string, opts domain.RemoveOptions, password string, out chan<- string) error
+
+	// SetInstallReason marks installed packages as explicitly installed or as
+	// dependencies (pacman -D), streaming command output to out.
+	SetInstallReason(names []string, reason domain.InstallReason, password string, out chan<- string) error
+
+	// PendingUpgrades lists installed packages that have a newer version in
+	// the sync databases.
+	PendingUpgrades() ([]domain.Upgrade, error)
+
+	// Upgrade performs a full system upgrade to the versions PendingUpgrades
+	// listed, streaming command output to out.
+	Upgrade(password string, out chan<- string) error
+
+	// SyncDatabases refreshes copies of the sync databases without root so
+	// update detection is current, streaming progress to out.
+	SyncDatabases(out chan<- string) error
+
+	// SyncFileDatabases downloads the repos' .files databases for
+	// SearchFiles, streaming progress to out.
+	SyncFileDatabases(out chan<- string) error
+
+	// SearchFiles finds sync packages containing a file whose name, or whole
+	// path when query has a slash, matches query (pacman -F). Globs are
+	// allowed. MatchedFiles lists the matching paths of each package.
+	SearchFiles(query string) ([]*domain.Package, error)
+
+	// Files lists the files an installed package owns, with sizes from disk.
+	Files(name string) ([]domain.PackageFile, error)
+
+	// Owns finds the installed packages owning each path or glob pattern
+	// (pacman -Qo). Targets without a slash are looked up in $PATH. A target
+	// nothing owns yields one FileOwner with an empty Package.
+	Owns(targets []string) ([]domain.FileOwner, error)
+
+	// CachedPackages lists the archives in the package cache directories,
+	// sorted by name and newest version first.
+	CachedPackages() ([]domain.CachedPackage, error)
+
+	// RemoveCachedPackages deletes archives from the package cache, with
+	// their signatures, streaming output to out.
+	RemoveCachedPackages(paths []string, password string, out chan<- string) error
+
+	// OrphanedConfigs lists the .pacsave files in the configuration
+	// directories that no installed package's backup files account for,
+	// such as those left by removed packages. Their Package is empty.
+	OrphanedConfigs() ([]domain.ConfigFile, error)
+
+	// ReadConfigFile returns the contents of a configuration file, for
+	// comparing a backup file with its .pacnew or .pacsave.
+	ReadConfigFile(path string) (string, error)
+
+	// ResolveConfigFile replaces a live configuration file with its .pacnew
+	// or .pacsave file, or deletes the latter when replace is false,
+	// streaming output to out.
+	ResolveConfigFile(file domain.ConfigFile, replace bool, password string, out chan<- string) error
+
+	// History reads the package transactions recorded in pacman.log, oldest
+	// first.
+	History() ([]domain.Transaction, error)
 
-	// Remove removes the specified packages and returns the command output.
-	Remove(names []string, cascade bool, password string) (string, error)
+	// VerifyPackage checks an installed package's files against the mtree
+	// data recorded when it was installed, like pacman -Qkk. Cancelling ctx
+	// stops it between files with ctx's error.
+	VerifyPackage(ctx context.Context, name string) (domain.VerifyResult, error)
 
 	// Refresh refreshes the package database.
 	Refresh() error
@@ -0,0 +1,174 @@
+package repository
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// syncRepo is a sync database and the servers it can be downloaded from.
+type syncRepo struct {
+	Name    string
+	Servers []string
+}
+
+// defaultSyncDBPath returns the private dbpath used for unprivileged sync refreshes.
+func defaultSyncDBPath() (string, error) {
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate cache directory: %w", err)
+	}
+	return filepath.Join(cacheDir, "pacviz", "db"), nil
+}
+
+// prepareSyncDBPath creates dbPath/sync and links dbPath/local to the system's
+// local database, the same layout checkupdates uses. Only a stale link is
+// replaced: a real dbPath/local means dbPath is a pacman database, such as
+// the system's own, and is refused rather than deleted.
+func prepareSyncDBPath(dbPath, localDir string) error {
+	link := filepath.Join(dbPath, "local")
+	info, lstatErr := os.Lstat(link)
+	switch {
+	case lstatErr == nil && info.Mode()&os.ModeSymlink == 0:
+		return fmt.Errorf("sync dbpath %s already holds a local database; use a directory of its own", dbPath)
+	case lstatErr != nil && !os.IsNotExist(lstatErr):
+		return fmt.Errorf("failed to check local database link: %w", lstatErr)
+	}
+
+	if err := os.MkdirAll(filepath.Join(dbPath, "sync"), 0o755); err != nil {
+		return fmt.Errorf("failed to create sync database directory: %w", err)
+	}
+
+	if lstatErr == nil {
+		if target, err := os.Readlink(link); err == nil && target == localDir {
+			return nil
+		}
+		if err := os.Remove(link); err != nil {
+			return fmt.Errorf("failed to replace local database link: %w", err)
+		}
+	}
+	if err := os.Symlink(localDir, link); err != nil {
+		return fmt.Errorf("failed to link local database: %w", err)
+	}
+
+	return nil
+}
+
+// expandMirror substitutes $repo and $arch in a mirror URL, as in a pacman mirrorlist.
+func expandMirror(mirror, repo, arch string) string {
+	return strings.NewReplacer("$repo", repo, "$arch", arch).Replace(mirror)
+}
+
+// hostArch maps GOARCH to the pacman architecture name for "Architecture = auto".
+func hostArch() string {
+	switch runtime.GOARCH {
+	case "amd64":
+		return "x86_64"
+	case "arm64":
+		return "aarch64"
+	case "386":
+		return "i686"
+	default:
+		return runtime.GOARCH
+	}
+}
+
+// downloadSyncDBs fetches <server>/<repo><ext> for every repo into dir, trying
+// each repo's servers in order. ext is ".db" for package databases or
+// ".files" for file databases. Progress is reported on out.
+func downloadSyncDBs(client *http.Client, dir string, repos []syncRepo, ext string, out chan<- string) error {
+	if ext == ".files" {
+		out <- ":: Synchronizing package file databases..."
+	} else {
+		out <- ":: Synchronizing package databases..."
+	}
+
+	for _, repo := range repos {
+		if len(repo.Servers) == 0 {
+			return fmt.Errorf("no servers configured for %s", repo.Name)
+		}
+
+		dest := filepath.Join(dir, repo.Name+ext)
+
+		var lastErr error
+		for _, server := range repo.Servers {
+			url := strings.TrimSuffix(server, "/") + "/" + repo.Name + ext
+
+			updated, err := downloadSyncDB(client, url, dest)
+			if err != nil {
+				lastErr = err
+				out <- fmt.Sprintf("error: failed retrieving file '%s%s' from %s: %v", repo.Name, ext, server, err)
+				continue
+			}
+
+			if updated {
+				out <- fmt.Sprintf(" %s downloaded", repo.Name)
+			} else {
+				out <- fmt.Sprintf(" %s is up to date", repo.Name)
+			}
+			lastErr = nil
+			break
+		}
+
+		if lastErr != nil {
+			return fmt.Errorf("failed to download %s%s: %w", repo.Name, ext, lastErr)
+		}
+	}
+
+	return nil
+}
+
+// downloadSyncDB downloads url to dest unless dest is already current. It
+// reports whether dest was replaced.
+func downloadSyncDB(client *http.Client, url, dest string) (bool, error) {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return false, err
+	}
+	if info, err := os.Stat(dest); err == nil {
+		req.Header.Set("If-Modified-Since", info.ModTime().UTC().Format(http.TimeFormat))
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusNotModified:
+		return false, nil
+	case http.StatusOK:
+	default:
+		return false, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(dest), filepath.Base(dest)+".part")
+	if err != nil {
+		return false, err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, resp.Body); err != nil {
+		tmp.Close()
+		return false, err
+	}
+	if err := tmp.Close(); err != nil {
+		return false, err
+	}
+
+	if modified, err := http.ParseTime(resp.Header.Get("Last-Modified")); err == nil {
+		os.Chtimes(tmp.Name(), time.Now(), modified)
+	}
+
+	if err := os.Rename(tmp.Name(), dest); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
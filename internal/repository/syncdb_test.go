@@ -0,0 +1,115 @@
+package repository
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDownloadSyncDBs(t *testing.T) {
+	mirror := t.TempDir()
+	os.MkdirAll(filepath.Join(mirror, "core", "os", "x86_64"), 0o755)
+	writeSyncDB(t, filepath.Join(mirror, "core", "os", "x86_64", "core.db"), map[string]string{
+		"glibc-2.39-1": "%NAME%\nglibc\n\n%VERSION%\n2.39-1\n\n%CSIZE%\n10485760\n",
+	})
+
+	server := httptest.NewServer(http.FileServer(http.Dir(mirror)))
+	defer server.Close()
+
+	dbPath := t.TempDir()
+	localDir := filepath.Join(t.TempDir(), "local")
+	if err := prepareSyncDBPath(dbPath, localDir); err != nil {
+		t.Fatalf("prepareSyncDBPath: %v", err)
+	}
+	if target, _ := os.Readlink(filepath.Join(dbPath, "local")); target != localDir {
+		t.Errorf("local link points to %q, want %q", target, localDir)
+	}
+
+	repos := []syncRepo{{
+		Name: "core",
+		Servers: []string{
+			server.URL + "/missing",
+			expandMirror(server.URL+"/$repo/os/$arch", "core", "x86_64"),
+		},
+	}}
+
+	run := func() []string {
+		out := make(chan string, 16)
+		if err := downloadSyncDBs(server.Client(), filepath.Join(dbPath, "sync"), repos, ".db", out); err != nil {
+			t.Fatalf("downloadSyncDBs: %v", err)
+		}
+		close(out)
+
+		var lines []string
+		for line := range out {
+			lines = append(lines, line)
+		}
+		return lines
+	}
+
+	lines := run()
+	if got := lines[len(lines)-1]; got != " core downloaded" {
+		t.Errorf("first sync: last line %q, lines %q", got, lines)
+	}
+
+	pkgs, err := readSyncDB(filepath.Join(dbPath, "sync", "core.db"))
+	if err != nil {
+		t.Fatalf("readSyncDB: %v", err)
+	}
+	if len(pkgs) != 1 || pkgs[0].Version != "2.39-1" || pkgs[0].DownloadSize != 10485760 {
+		t.Errorf("unexpected packages after sync: %+v", pkgs)
+	}
+
+	lines = run()
+	if got := lines[len(lines)-1]; got != " core is up to date" {
+		t.Errorf("second sync: last line %q, lines %q", got, lines)
+	}
+}
+
+func TestDownloadSyncDBs_NoServer(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+
+	out := make(chan string, 16)
+	repos := []syncRepo{{Name: "core", Servers: []string{server.URL}}}
+	if err := downloadSyncDBs(server.Client(), t.TempDir(), repos, ".db", out); err == nil {
+		t.Error("expected an error when no server has the database")
+	}
+}
+
+func TestPrepareSyncDBPath(t *testing.T) {
+	localDir := filepath.Join(t.TempDir(), "local")
+
+	t.Run("replaces a stale link", func(t *testing.T) {
+		dbPath := t.TempDir()
+		if err := os.Symlink("/nonexistent", filepath.Join(dbPath, "local")); err != nil {
+			t.Fatal(err)
+		}
+		if err := prepareSyncDBPath(dbPath, localDir); err != nil {
+			t.Fatalf("prepareSyncDBPath: %v", err)
+		}
+		if target, _ := os.Readlink(filepath.Join(dbPath, "local")); target != localDir {
+			t.Errorf("local link points to %q, want %q", target, localDir)
+		}
+	})
+
+	t.Run("refuses a real local database", func(t *testing.T) {
+		dbPath := t.TempDir()
+		desc := filepath.Join(dbPath, "local", "bash-5.2.026-2", "desc")
+		if err := os.MkdirAll(filepath.Dir(desc), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(desc, []byte("%NAME%\nbash\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := prepareSyncDBPath(dbPath, localDir); err == nil {
+			t.Fatal("expected a real local directory to be refused")
+		}
+		if _, err := os.Stat(desc); err != nil {
+			t.Errorf("local database was touched: %v", err)
+		}
+	})
+}
@@ -0,0 +1,56 @@
+package repository
+
+import (
+	"sort"
+
+	"github.com/sjsanc/pacviz/v3/internal/domain"
+)
+
+// computeUnneeded marks dependency packages that no explicitly installed
+// package needs, directly or through a chain of dependencies, as unneeded.
+// This is the recursive equivalent of pacman -Qdtt: optional dependencies
+// do not keep a package, but OptionalFor records who would miss it.
+func computeUnneeded(packages []*domain.Package) {
+	graph := newDepGraph(packages)
+
+	needed := make(map[string]bool, len(packages))
+	var queue []*domain.Package
+	for _, pkg := range packages {
+		pkg.OptionalFor = nil
+		if pkg.InstallReason == domain.ReasonExplicit {
+			needed[pkg.Name] = true
+			queue = append(queue, pkg)
+		}
+	}
+
+	for len(queue) > 0 {
+		pkg := queue[0]
+		queue = queue[1:]
+		for _, dep := range pkg.Dependencies {
+			for _, satisfier := range graph.satisfiers(dep) {
+				if !needed[satisfier.Name] {
+					needed[satisfier.Name] = true
+					queue = append(queue, satisfier)
+				}
+			}
+		}
+	}
+
+	for _, pkg := range packages {
+		for optDep := range pkg.OptDepends {
+			for _, satisfier := range graph.satisfiers(optDep) {
+				// A package listing several optional dependencies the same
+				// package satisfies appends itself consecutively.
+				if n := len(satisfier.OptionalFor); n > 0 && satisfier.OptionalFor[n-1] == pkg.Name {
+					continue
+				}
+				satisfier.OptionalFor = append(satisfier.OptionalFor, pkg.Name)
+			}
+		}
+	}
+
+	for _, pkg := range packages {
+		sort.Strings(pkg.OptionalFor)
+		pkg.IsUnneeded = pkg.InstallReason == domain.ReasonDependency && !needed[pkg.Name]
+	}
+}
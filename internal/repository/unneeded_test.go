@@ -0,0 +1,114 @@
+package repository
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Jguer/go-alpm/v2"
+	"github.com/sjsanc/pacviz/v3/internal/domain"
+)
+
+func TestComputeUnneeded(t *testing.T) {
+	dep := domain.ReasonDependency
+	packages := []*domain.Package{
+		{Name: "app", Dependencies: []string{"libapp", "sh"}},
+		{Name: "libapp", InstallReason: dep},
+		{Name: "bash", InstallReason: dep, Provides: []string{"sh=5.2"}},
+		// chain only needed by an orphan
+		{Name: "orphan", InstallReason: dep, Dependencies: []string{"libchain"}, OptDepends: map[string]string{"libopt": "extras"}},
+		{Name: "libchain", InstallReason: dep},
+		// cycle with no explicit root
+		{Name: "cycle-a", InstallReason: dep, Dependencies: []string{"cycle-b"}},
+		{Name: "cycle-b", InstallReason: dep, Dependencies: []string{"cycle-a"}},
+		// only optionally required
+		{Name: "libopt", InstallReason: dep},
+	}
+
+	computeOrphans(packages)
+	computeUnneeded(packages)
+
+	want := map[string]bool{
+		"app":      false,
+		"libapp":   false,
+		"bash":     false,
+		"orphan":   true,
+		"libchain": true,
+		"cycle-a":  true,
+		"cycle-b":  true,
+		"libopt":   true,
+	}
+	for _, pkg := range packages {
+		if pkg.IsUnneeded != want[pkg.Name] {
+			t.Errorf("%s: IsUnneeded = %v, want %v", pkg.Name, pkg.IsUnneeded, want[pkg.Name])
+		}
+	}
+
+	if pkg := findPackage(packages, "libchain"); pkg.IsOrphan {
+		t.Error("libchain has a dependent, so it is not a direct orphan")
+	}
+	if pkg := findPackage(packages, "libopt"); len(pkg.OptionalFor) != 1 || pkg.OptionalFor[0] != "orphan" {
+		t.Errorf("libopt OptionalFor = %v, want [orphan]", pkg.OptionalFor)
+	}
+}
+
+func TestComputeUnneeded_ConvertedOptDepends(t *testing.T) {
+	r := &AlpmRepository{}
+	packages := []*domain.Package{
+		r.convertPackage(&fakePackage{
+			name:       "bash",
+			version:    "5.2.026-2",
+			reason:     alpm.PkgReasonExplicit,
+			optDepends: dependList{{Name: "bash-completion", Description: "for tab completion"}},
+		}),
+		r.convertPackage(&fakePackage{
+			name:    "bash-completion",
+			version: "2.14.0-2",
+			reason:  alpm.PkgReasonDepend,
+		}),
+	}
+
+	computeUnneeded(packages)
+
+	pkg := findPackage(packages, "bash-completion")
+	if len(pkg.OptionalFor) != 1 || pkg.OptionalFor[0] != "bash" {
+		t.Errorf("bash-completion OptionalFor = %v, want [bash]", pkg.OptionalFor)
+	}
+	if !pkg.IsUnneeded {
+		t.Error("bash-completion is only optionally required, so it should be unneeded")
+	}
+}
+
+func TestComputeUnneeded_OptionalForSorted(t *testing.T) {
+	r := &AlpmRepository{}
+	packages := []*domain.Package{
+		r.convertPackage(&fakePackage{
+			name:    "zsh",
+			version: "5.9-5",
+			reason:  alpm.PkgReasonExplicit,
+			optDepends: dependList{
+				{Name: "bash-completion"},
+				{Name: "completions", Description: "for shell completion"},
+			},
+		}),
+		r.convertPackage(&fakePackage{
+			name:       "bash",
+			version:    "5.2.026-2",
+			reason:     alpm.PkgReasonExplicit,
+			optDepends: dependList{{Name: "bash-completion"}},
+		}),
+		r.convertPackage(&fakePackage{
+			name:     "bash-completion",
+			version:  "2.14.0-2",
+			reason:   alpm.PkgReasonDepend,
+			provides: dependList{{Name: "completions"}},
+		}),
+	}
+
+	for range 20 {
+		computeUnneeded(packages)
+		pkg := findPackage(packages, "bash-completion")
+		if want := []string{"bash", "zsh"}; !reflect.DeepEqual(pkg.OptionalFor, want) {
+			t.Fatalf("bash-completion OptionalFor = %q, want %q", pkg.OptionalFor, want)
+		}
+	}
+}
@@ -27,6 +27,10 @@ const (
 	ColInstallReason   Type = "install_reason"
 	ColRequired        Type = "required"
 	ColIsOrphan        Type = "is_orphan"
+	ColUnneeded        Type = "unneeded"
+	ColUnsatisfied     Type = "unsatisfied"
+	ColMatchedFiles    Type = "matched_files"
+	ColPendingConfigs  Type = "pending_configs"
 	ColIsForeign       Type = "is_foreign"
 	ColHasUpdate       Type = "has_update"
 	ColNewVersion      Type = "new_version"
@@ -127,6 +131,38 @@ func DefaultColumns() []*Column {
 			Searchable: false,
 			Visible:    true,
 		},
+		{
+			Type:       ColUnneeded,
+			Name:       "Unneeded",
+			Width:      ColumnWidth{Type: WidthFixed, Size: 10}, // Fits "Optional"
+			Sortable:   true,
+			Searchable: false,
+			Visible:    false, // Hidden by default, shown by the Unneeded preset
+		},
+		{
+			Type:       ColUnsatisfied,
+			Name:       "Unsatisfied",
+			Width:      ColumnWidth{Type: WidthPercent, Size: 25, Min: 20},
+			Sortable:   false,
+			Searchable: true,
+			Visible:    false, // Hidden by default, shown by the Broken preset
+		},
+		{
+			Type:       ColMatchedFiles,
+			Name:       "Matched Files",
+			Width:      ColumnWidth{Type: WidthPercent, Size: 25, Min: 20},
+			Sortable:   false,
+			Searchable: true,
+			Visible:    false, // Hidden by default, shown for file search results
+		},
+		{
+			Type:       ColPendingConfigs,
+			Name:       "Pacnew",
+			Width:      ColumnWidth{Type: WidthPercent, Size: 25, Min: 20},
+			Sortable:   false,
+			Searchable: true,
+			Visible:    false, // Hidden by default, shown by the Pacnew preset
+		},
 		{
 			Type:       ColInstallDate,
 			Name:       "InstalledOn",
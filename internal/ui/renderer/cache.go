@@ -0,0 +1,100 @@
+package renderer
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/sjsanc/pacviz/v3/internal/domain"
+	"github.com/sjsanc/pacviz/v3/internal/ui/styles"
+)
+
+// RenderCachePane renders the package cache grouped by package, with the
+// installed archive marked and the cursor line highlighted. It always
+// returns exactly height rows.
+func RenderCachePane(list *domain.CacheList, cursor, offset, height, width int) (string, int) {
+	if list == nil || height < 2 {
+		return "", 0
+	}
+
+	titleStyle := lipgloss.NewStyle().
+		Foreground(styles.Current.Background).
+		Background(styles.Current.Accent1).
+		Bold(true).
+		Width(width)
+
+	rowStyle := lipgloss.NewStyle().
+		Foreground(styles.Current.Foreground).
+		Background(styles.Current.Selected).
+		Width(width)
+
+	headerStyle := rowStyle.
+		Foreground(styles.Current.Accent1).
+		Bold(true)
+
+	cursorStyle := rowStyle.
+		Foreground(styles.Current.Background).
+		Background(styles.Current.Accent2).
+		Bold(true)
+
+	bodyRows := height - 1
+
+	title := fmt.Sprintf("  Package cache: %d archives of %d packages (%s)",
+		list.ArchiveCount(), len(list.Groups), domain.FormatSize(list.TotalSize()))
+	if list.Filter != "" {
+		title += fmt.Sprintf(" matching %q", list.Filter)
+	}
+	if len(list.Lines) > 0 {
+		end := min(offset+bodyRows, len(list.Lines))
+		title += fmt.Sprintf(" [%d-%d/%d]", offset+1, end, len(list.Lines))
+	}
+
+	rendered := []string{titleStyle.Render(title)}
+	for i := 0; i < bodyRows; i++ {
+		idx := offset + i
+		if idx < 0 || idx >= len(list.Lines) {
+			rendered = append(rendered, rowStyle.Render(""))
+			continue
+		}
+
+		line := list.Lines[idx]
+		group := list.Groups[line.Group]
+		var text string
+		if line.Archive < 0 {
+			text = formatCacheGroup(group, width)
+		} else {
+			text = formatCachedPackage(group.Archives[line.Archive], width)
+		}
+
+		switch {
+		case idx == cursor:
+			rendered = append(rendered, cursorStyle.Render(text))
+		case line.Archive < 0:
+			rendered = append(rendered, headerStyle.Render(text))
+		default:
+			rendered = append(rendered, rowStyle.Render(text))
+		}
+	}
+
+	return lipgloss.JoinVertical(lipgloss.Left, rendered...), height
+}
+
+func formatCacheGroup(group domain.CacheGroup, width int) string {
+	installed := "not installed"
+	if group.InstalledVersion != "" {
+		installed = "installed " + group.InstalledVersion
+	}
+	return truncateLine(fmt.Sprintf("  %s (%s, %d cached)", group.Name, installed, len(group.Archives)), width)
+}
+
+func formatCachedPackage(archive domain.CachedPackage, width int) string {
+	marker := "  "
+	if archive.Installed() {
+		marker = "● "
+	}
+
+	size := domain.FormatSize(archive.Size)
+	name := truncateLine("    "+marker+archive.Version+"  "+archive.Architecture, max(width-len(size)-3, 0))
+	padding := max(width-len([]rune(name))-len(size)-2, 1)
+	return name + strings.Repeat(" ", padding) + size
+}
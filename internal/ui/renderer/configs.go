@@ -0,0 +1,61 @@
+package renderer
+
+import (
+	"fmt"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/sjsanc/pacviz/v3/internal/domain"
+	"github.com/sjsanc/pacviz/v3/internal/ui/styles"
+)
+
+// RenderConfigsPane renders the .pacnew and .pacsave files waiting to be
+// merged, with the cursor line highlighted. It always returns exactly
+// height rows.
+func RenderConfigsPane(files []domain.ConfigFile, cursor, offset, height, width int) (string, int) {
+	if height < 2 {
+		return "", 0
+	}
+
+	titleStyle := lipgloss.NewStyle().
+		Foreground(styles.Current.Background).
+		Background(styles.Current.Accent1).
+		Bold(true).
+		Width(width)
+
+	rowStyle := lipgloss.NewStyle().
+		Foreground(styles.Current.Foreground).
+		Background(styles.Current.Selected).
+		Width(width)
+
+	cursorStyle := rowStyle.
+		Foreground(styles.Current.Background).
+		Background(styles.Current.Accent2).
+		Bold(true)
+
+	bodyRows := height - 1
+
+	title := fmt.Sprintf("  Configuration files to merge: %d", len(files))
+	if len(files) > 0 {
+		end := min(offset+bodyRows, len(files))
+		title += fmt.Sprintf(" [%d-%d/%d]", offset+1, end, len(files))
+	}
+
+	rendered := []string{titleStyle.Render(title)}
+	for i := 0; i < bodyRows; i++ {
+		idx := offset + i
+		if idx < 0 || idx >= len(files) {
+			rendered = append(rendered, rowStyle.Render(""))
+			continue
+		}
+
+		file := files[idx]
+		text := truncateLine(fmt.Sprintf("  %s  (%s)", file.New, file.Owner()), width)
+		if idx == cursor {
+			rendered = append(rendered, cursorStyle.Render(text))
+		} else {
+			rendered = append(rendered, rowStyle.Render(text))
+		}
+	}
+
+	return lipgloss.JoinVertical(lipgloss.Left, rendered...), height
+}
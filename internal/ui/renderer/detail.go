@@ -23,6 +23,7 @@ var detailPanelFields = []detailField{
 	{label: "Install Date", colType: column.ColInstallDate},
 	{label: "Install Reason", colType: column.ColInstallReason},
 	{label: "Is Orphan", colType: column.ColIsOrphan},
+	{label: "Unneeded", colType: column.ColUnneeded},
 	{label: "Is Foreign", colType: column.ColIsForeign},
 	{label: "Latest Version", colType: column.ColNewVersion},
 	{label: "Description", colType: column.ColDescription},
@@ -33,6 +34,9 @@ var detailPanelFields = []detailField{
 	{label: "Size", colType: column.ColSize},
 	{label: "Groups", colType: column.ColGroups},
 	{label: "Dependencies", colType: column.ColDependencies},
+	{label: "Unsatisfied Dependencies", colType: column.ColUnsatisfied},
+	{label: "Matched Files", colType: column.ColMatchedFiles},
+	{label: "Pending Configs", colType: column.ColPendingConfigs},
 	{label: "Optional Dependencies", colType: column.ColOptDepends},
 	{label: "Required By", colType: column.ColRequired},
 	{label: "Provides", colType: column.ColProvides},
@@ -41,7 +45,9 @@ var detailPanelFields = []detailField{
 }
 
 // RenderDetailPanel renders the package detail panel as an overlay above the status bar.
-func RenderDetailPanel(pkg *domain.Package, columns []*column.Column, colWidths []int, width int, _ bool, isRemote bool) string {
+// why lists the dependency chains explaining why the package is installed,
+// and history its most recent changes from pacman.log.
+func RenderDetailPanel(pkg *domain.Package, columns []*column.Column, colWidths []int, width int, _ bool, isRemote bool, why, history []string) string {
 	if pkg == nil {
 		return ""
 	}
@@ -105,6 +111,23 @@ func RenderDetailPanel(pkg *domain.Package, columns []*column.Column, colWidths
 
 	content := lipgloss.JoinHorizontal(lipgloss.Top, leftCol, rightCol)
 
+	for _, section := range []struct {
+		title string
+		lines []string
+	}{
+		{"Why Installed", why},
+		{"History", history},
+	} {
+		if len(section.lines) == 0 {
+			continue
+		}
+		sectionLines := []string{labelStyle.Render(section.title + ":")}
+		for _, line := range section.lines {
+			sectionLines = append(sectionLines, "  "+valueStyle.Render(line))
+		}
+		content = content + "\n\n" + strings.Join(sectionLines, "\n")
+	}
+
 	if isRemote {
 		content = content + "\n\n" + renderInstallCommands(pkg.Name)
 	}
@@ -0,0 +1,100 @@
+package renderer
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/sjsanc/pacviz/v3/internal/domain"
+	"github.com/sjsanc/pacviz/v3/internal/ui/styles"
+)
+
+// usageNameWidth is the width of the name column of the disk usage view.
+const usageNameWidth = 30
+
+// RenderDiskUsagePane renders one section of the disk usage view: the
+// explicit packages ranked by exclusive footprint, or the totals per
+// repository, group or preset, with the cursor line highlighted. It always
+// returns exactly height rows.
+func RenderDiskUsagePane(usage *domain.DiskUsage, section domain.UsageSection, cursor, offset, height, width int) (string, int) {
+	if usage == nil || height < 2 {
+		return "", 0
+	}
+
+	titleStyle := lipgloss.NewStyle().
+		Foreground(styles.Current.Background).
+		Background(styles.Current.Accent1).
+		Bold(true).
+		Width(width)
+
+	rowStyle := lipgloss.NewStyle().
+		Foreground(styles.Current.Foreground).
+		Background(styles.Current.Selected).
+		Width(width)
+
+	cursorStyle := rowStyle.
+		Foreground(styles.Current.Background).
+		Background(styles.Current.Accent2).
+		Bold(true)
+
+	bodyRows := height - 1
+	count := usage.Len(section)
+
+	title := fmt.Sprintf("  Disk usage: %d packages (%s) · %s", usage.Packages, domain.FormatSize(usage.Size), section)
+	if count > 0 {
+		end := min(offset+bodyRows, count)
+		title += fmt.Sprintf(" [%d-%d/%d]", offset+1, end, count)
+	}
+
+	totals := usage.Totals(section)
+	rendered := []string{titleStyle.Render(title)}
+	for i := 0; i < bodyRows; i++ {
+		idx := offset + i
+		if idx < 0 || idx >= count {
+			rendered = append(rendered, rowStyle.Render(""))
+			continue
+		}
+
+		var text string
+		if section == domain.UsageLargest {
+			text = formatFootprint(idx+1, usage.Footprints[idx], usage.Size, width)
+		} else {
+			text = formatUsageTotal(totals[idx], usage.Size, width)
+		}
+
+		if idx == cursor {
+			rendered = append(rendered, cursorStyle.Render(text))
+		} else {
+			rendered = append(rendered, rowStyle.Render(text))
+		}
+	}
+
+	return lipgloss.JoinVertical(lipgloss.Left, rendered...), height
+}
+
+// formatFootprint shows a package's rank, exclusive footprint and share of
+// the installed size, then its own size and how many dependencies would go
+// with it.
+func formatFootprint(rank int, fp domain.Footprint, total int64, width int) string {
+	detail := fmt.Sprintf("%s + %d deps", domain.FormatSize(fp.Package.InstalledSize), len(fp.Deps))
+	if len(fp.RequiredBy) > 0 {
+		detail += " · required by " + strings.Join(fp.RequiredBy, ", ")
+	}
+
+	name := truncateLine(fmt.Sprintf("%4d. %s", rank, fp.Package.Name), usageNameWidth)
+	return truncateLine(fmt.Sprintf("  %-*s %10s %6s  %s",
+		usageNameWidth, name, domain.FormatSize(fp.Size), formatShare(fp.Size, total), detail), width)
+}
+
+func formatUsageTotal(total domain.UsageTotal, size int64, width int) string {
+	return truncateLine(fmt.Sprintf("  %-*s %10s %6s  %d packages",
+		usageNameWidth, truncateLine(total.Name, usageNameWidth), domain.FormatSize(total.Size), formatShare(total.Size, size), total.Packages), width)
+}
+
+// formatShare formats size as a percentage of total, e.g. "12.5%".
+func formatShare(size, total int64) string {
+	if total <= 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%.1f%%", float64(size)*100/float64(total))
+}
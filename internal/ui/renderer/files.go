@@ -0,0 +1,82 @@
+package renderer
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/sjsanc/pacviz/v3/internal/domain"
+	"github.com/sjsanc/pacviz/v3/internal/ui/styles"
+)
+
+// RenderFilesPane renders a package's file list with sizes right-aligned
+// and the cursor line highlighted. It always returns exactly height rows.
+func RenderFilesPane(list *domain.FileTree, cursor, offset, height, width int) (string, int) {
+	if list == nil || height < 2 {
+		return "", 0
+	}
+
+	titleStyle := lipgloss.NewStyle().
+		Foreground(styles.Current.Background).
+		Background(styles.Current.Accent1).
+		Bold(true).
+		Width(width)
+
+	rowStyle := lipgloss.NewStyle().
+		Foreground(styles.Current.Foreground).
+		Background(styles.Current.Selected).
+		Width(width)
+
+	cursorStyle := rowStyle.
+		Foreground(styles.Current.Background).
+		Background(styles.Current.Accent2).
+		Bold(true)
+
+	bodyRows := height - 1
+
+	title := fmt.Sprintf("  Files: %s (%d files)", list.Package, list.FileCount())
+	if list.Filter != "" {
+		title += fmt.Sprintf(" matching %q", list.Filter)
+	}
+	if len(list.Nodes) > 0 {
+		end := min(offset+bodyRows, len(list.Nodes))
+		title += fmt.Sprintf(" [%d-%d/%d]", offset+1, end, len(list.Nodes))
+	}
+
+	rendered := []string{titleStyle.Render(title)}
+	for i := 0; i < bodyRows; i++ {
+		idx := offset + i
+		if idx < 0 || idx >= len(list.Nodes) {
+			rendered = append(rendered, rowStyle.Render(""))
+			continue
+		}
+
+		line := formatFileNode(list.Nodes[idx], width)
+		if idx == cursor {
+			rendered = append(rendered, cursorStyle.Render(line))
+		} else {
+			rendered = append(rendered, rowStyle.Render(line))
+		}
+	}
+
+	return lipgloss.JoinVertical(lipgloss.Left, rendered...), height
+}
+
+func formatFileNode(node domain.FileNode, width int) string {
+	marker := "  "
+	if node.HasChildren {
+		marker = "▸ "
+		if node.Expanded {
+			marker = "▾ "
+		}
+	}
+
+	size := domain.FormatSize(node.File.Size)
+	if node.File.Missing {
+		size = "missing"
+	}
+
+	name := truncateLine("  "+strings.Repeat("  ", node.Depth)+marker+node.Name, max(width-len(size)-3, 0))
+	padding := max(width-len([]rune(name))-len(size)-2, 1)
+	return name + strings.Repeat(" ", padding) + size
+}
@@ -0,0 +1,90 @@
+package renderer
+
+import (
+	"fmt"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/sjsanc/pacviz/v3/internal/domain"
+	"github.com/sjsanc/pacviz/v3/internal/ui/styles"
+)
+
+// RenderHistoryPane renders the package history as transactions, newest
+// first, each followed by its events, with the cursor line highlighted. It
+// always returns exactly height rows.
+func RenderHistoryPane(list *domain.HistoryList, cursor, offset, height, width int) (string, int) {
+	if list == nil || height < 2 {
+		return "", 0
+	}
+
+	titleStyle := lipgloss.NewStyle().
+		Foreground(styles.Current.Background).
+		Background(styles.Current.Accent1).
+		Bold(true).
+		Width(width)
+
+	rowStyle := lipgloss.NewStyle().
+		Foreground(styles.Current.Foreground).
+		Background(styles.Current.Selected).
+		Width(width)
+
+	headerStyle := rowStyle.
+		Foreground(styles.Current.Accent1).
+		Bold(true)
+
+	cursorStyle := rowStyle.
+		Foreground(styles.Current.Background).
+		Background(styles.Current.Accent2).
+		Bold(true)
+
+	bodyRows := height - 1
+
+	title := fmt.Sprintf("  Package history: %d transactions", len(list.Transactions))
+	if list.Filter != "" {
+		title += fmt.Sprintf(", %d changes matching %q", list.EventCount(), list.Filter)
+	}
+	if len(list.Lines) > 0 {
+		end := min(offset+bodyRows, len(list.Lines))
+		title += fmt.Sprintf(" [%d-%d/%d]", offset+1, end, len(list.Lines))
+	}
+
+	rendered := []string{titleStyle.Render(title)}
+	for i := 0; i < bodyRows; i++ {
+		idx := offset + i
+		if idx < 0 || idx >= len(list.Lines) {
+			rendered = append(rendered, rowStyle.Render(""))
+			continue
+		}
+
+		line := list.Lines[idx]
+		tx := list.Transactions[line.Transaction]
+		var text string
+		if line.Event < 0 {
+			text = formatTransaction(tx, width)
+		} else {
+			text = formatHistoryEvent(tx.Events[line.Event], width)
+		}
+
+		switch {
+		case idx == cursor:
+			rendered = append(rendered, cursorStyle.Render(text))
+		case line.Event < 0:
+			rendered = append(rendered, headerStyle.Render(text))
+		default:
+			rendered = append(rendered, rowStyle.Render(text))
+		}
+	}
+
+	return lipgloss.JoinVertical(lipgloss.Left, rendered...), height
+}
+
+func formatTransaction(tx domain.Transaction, width int) string {
+	command := tx.Command
+	if command == "" {
+		command = "unknown command"
+	}
+	return truncateLine(fmt.Sprintf("  %s  %s (%s)", tx.Start.Format("2006-01-02 15:04"), command, tx.Summary()), width)
+}
+
+func formatHistoryEvent(event domain.HistoryEvent, width int) string {
+	return truncateLine(fmt.Sprintf("    %-11s %s %s", event.Action, event.Package, event.Versions()), width)
+}
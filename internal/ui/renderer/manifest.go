@@ -0,0 +1,101 @@
+package renderer
+
+import (
+	"fmt"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/sjsanc/pacviz/v3/internal/domain"
+	"github.com/sjsanc/pacviz/v3/internal/ui/styles"
+)
+
+// RenderManifestPane renders how the installed packages differ from an
+// imported manifest, one section per kind of difference, with the cursor
+// line highlighted. It always returns exactly height rows.
+func RenderManifestPane(path string, diff domain.ManifestDiff, lines []domain.ManifestLine, cursor, offset, height, width int) (string, int) {
+	if height < 2 {
+		return "", 0
+	}
+
+	titleStyle := lipgloss.NewStyle().
+		Foreground(styles.Current.Background).
+		Background(styles.Current.Accent1).
+		Bold(true).
+		Width(width)
+
+	rowStyle := lipgloss.NewStyle().
+		Foreground(styles.Current.Foreground).
+		Background(styles.Current.Selected).
+		Width(width)
+
+	headerStyle := rowStyle.
+		Foreground(styles.Current.Accent1).
+		Bold(true)
+
+	cursorStyle := rowStyle.
+		Foreground(styles.Current.Background).
+		Background(styles.Current.Accent2).
+		Bold(true)
+
+	bodyRows := height - 1
+
+	title := fmt.Sprintf("  Manifest %s: %d missing, %d not in the manifest, %d installed as dependencies",
+		path, len(diff.Missing), len(diff.Extra), len(diff.Reason))
+	if len(lines) > 0 {
+		end := min(offset+bodyRows, len(lines))
+		title += fmt.Sprintf(" [%d-%d/%d]", offset+1, end, len(lines))
+	}
+
+	rendered := []string{titleStyle.Render(truncateLine(title, width))}
+	for i := 0; i < bodyRows; i++ {
+		idx := offset + i
+		if idx < 0 || idx >= len(lines) {
+			rendered = append(rendered, rowStyle.Render(""))
+			continue
+		}
+
+		line := lines[idx]
+		var text string
+		if line.Index < 0 {
+			text = truncateLine(fmt.Sprintf("  %s (%d)", line.Section, diff.Len(line.Section)), width)
+		} else {
+			text = truncateLine("    "+formatManifestEntry(diff, line), width)
+		}
+
+		switch {
+		case idx == cursor:
+			rendered = append(rendered, cursorStyle.Render(text))
+		case line.Index < 0:
+			rendered = append(rendered, headerStyle.Render(text))
+		default:
+			rendered = append(rendered, rowStyle.Render(text))
+		}
+	}
+
+	return lipgloss.JoinVertical(lipgloss.Left, rendered...), height
+}
+
+func formatManifestEntry(diff domain.ManifestDiff, line domain.ManifestLine) string {
+	switch line.Section {
+	case domain.SectionMissing:
+		missing := diff.Missing[line.Index]
+		text := missing.Name
+		if missing.Version != "" {
+			text += " " + missing.Version
+		}
+		if missing.Foreign {
+			text += " (foreign)"
+		}
+		return text
+	case domain.SectionExtra:
+		pkg := diff.Extra[line.Index]
+		return pkg.Name + " " + pkg.Version
+	case domain.SectionReason:
+		pkg := diff.Reason[line.Index]
+		return pkg.Name + " " + pkg.Version
+	case domain.SectionVersions:
+		v := diff.Versions[line.Index]
+		return fmt.Sprintf("%s %s (manifest: %s)", v.Package.Name, v.Package.Version, v.Want)
+	default:
+		return ""
+	}
+}
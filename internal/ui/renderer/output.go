@@ -0,0 +1,58 @@
+package renderer
+
+import (
+	"fmt"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/sjsanc/pacviz/v3/internal/ui/styles"
+)
+
+// RenderOutputPane renders a scrollable pane of command output with a title row.
+// It always returns exactly height rows so the table layout stays stable.
+func RenderOutputPane(title string, lines []string, offset, height, width int) (string, int) {
+	if height < 2 {
+		return "", 0
+	}
+
+	titleStyle := lipgloss.NewStyle().
+		Foreground(styles.Current.Background).
+		Background(styles.Current.Accent1).
+		Bold(true).
+		Width(width)
+
+	rowStyle := lipgloss.NewStyle().
+		Foreground(styles.Current.Foreground).
+		Background(styles.Current.Selected).
+		Width(width)
+
+	bodyRows := height - 1
+
+	position := ""
+	if len(lines) > 0 {
+		end := min(offset+bodyRows, len(lines))
+		position = fmt.Sprintf(" [%d-%d/%d]", offset+1, end, len(lines))
+	}
+
+	rendered := []string{titleStyle.Render("  " + title + position)}
+	for i := 0; i < bodyRows; i++ {
+		content := ""
+		if idx := offset + i; idx >= 0 && idx < len(lines) {
+			content = truncateLine("  "+lines[idx], width)
+		}
+		rendered = append(rendered, rowStyle.Render(content))
+	}
+
+	return lipgloss.JoinVertical(lipgloss.Left, rendered...), height
+}
+
+// truncateLine cuts a plain-text line to at most width runes.
+func truncateLine(line string, width int) string {
+	runes := []rune(line)
+	if len(runes) <= width {
+		return line
+	}
+	if width > 3 {
+		return string(runes[:width-3]) + "..."
+	}
+	return string(runes[:max(width, 0)])
+}
@@ -119,9 +119,10 @@ func RenderWithDetailPanel(
 	selectedPackage *domain.Package,
 	offset int,
 	remoteMode bool,
+	why, history []string,
 ) string {
 	// Render the detail panel
-	detailPanel := RenderDetailPanel(selectedPackage, columns, colWidths, width, true, remoteMode)
+	detailPanel := RenderDetailPanel(selectedPackage, columns, colWidths, width, true, remoteMode, why, history)
 
 	detailLines := strings.Count(detailPanel, "\n") + 1
 	header := RenderHeader(columns, colWidths, selectedCol, sortCol, sortReverse)
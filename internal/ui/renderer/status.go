@@ -7,7 +7,7 @@ import (
 	"github.com/sjsanc/pacviz/v3/internal/ui/styles"
 )
 
-func RenderStatus(preset string, totalRows, visibleRows, offset int, filter string, width int) string {
+func RenderStatus(preset string, totalRows, visibleRows, offset int, filter string, selected int, message string, width int) string {
 	start := offset + 1
 	end := min(offset+visibleRows, totalRows)
 	status := fmt.Sprintf("Preset: %s | Showing %d-%d of %d",
@@ -20,6 +20,20 @@ func RenderStatus(preset string, totalRows, visibleRows, offset int, filter stri
 		status += fmt.Sprintf(" | Filter: \"%s\"", filter)
 	}
 
+	if selected > 0 {
+		status += fmt.Sprintf(" | Selected: %d", selected)
+	}
+
+	if message != "" {
+		padding := width - len(status) - len(message) - 4
+
+		if padding > 0 {
+			status = status + strings.Repeat(" ", padding) + "| " + message
+		} else {
+			status = message
+		}
+	}
+
 	return styles.Current.StatusBar.Width(width).Render(status)
 }
 
@@ -27,7 +41,24 @@ func RenderStatusWithBuffer(buffer string, width int) string {
 	return styles.Current.StatusBar.Width(width).Render(buffer)
 }
 
-func RenderRemoteStatus(query string, totalRows, visibleRows, offset int, filter string, loading bool, spinner string, errorMsg string, installing bool, installingPkg string, width int) string {
+// RenderFilterStatus shows the filter being typed, with the reason it does
+// not parse on the right.
+func RenderFilterStatus(buffer, errorMsg string, width int) string {
+	if errorMsg == "" {
+		return RenderStatusWithBuffer(buffer, width)
+	}
+
+	status := buffer
+	padding := width - len(status) - len(errorMsg) - 4
+	if padding > 0 {
+		status += strings.Repeat(" ", padding) + "| " + errorMsg
+	} else {
+		status = truncateLine(status+"  | "+errorMsg, width)
+	}
+	return styles.Current.WarningStatusBar.Width(width).Render(status)
+}
+
+func RenderRemoteStatus(query string, totalRows, visibleRows, offset int, filter string, selected int, loading bool, spinner string, errorMsg string, installing bool, installingPkg string, width int) string {
 	var status string
 
 	if installing {
@@ -46,6 +77,10 @@ func RenderRemoteStatus(query string, totalRows, visibleRows, offset int, filter
 		if filter != "" {
 			status += fmt.Sprintf(" | Filter: \"%s\"", filter)
 		}
+
+		if selected > 0 {
+			status += fmt.Sprintf(" | Selected: %d", selected)
+		}
 	}
 
 	if errorMsg != "" {
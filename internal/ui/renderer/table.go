@@ -53,6 +53,9 @@ func RenderTableWithMode(rows []*domain.Row, columns []*column.Column, colWidths
 				if len(content) < contentWidth {
 					content = strings.Repeat(" ", contentWidth-len(content)) + content
 				}
+				if row.Selected && len(content) > 0 && content[0] == ' ' {
+					content = "•" + content[1:]
+				}
 			} else {
 				if len(content) > contentWidth {
 					if contentWidth > 3 {
@@ -137,6 +140,11 @@ func RenderTableWithMode(rows []*domain.Row, columns []*column.Column, colWidths
 				}
 			}
 
+			// Marked rows are highlighted in the index and plain text columns
+			if row.Selected && col.Type != column.ColRepo && col.Type != column.ColHasUpdate {
+				style = style.Foreground(styles.Current.Accent1).Bold(true)
+			}
+
 			cells = append(cells, style.Render(content))
 		}
 
@@ -0,0 +1,82 @@
+package renderer
+
+import (
+	"fmt"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/sjsanc/pacviz/v3/internal/domain"
+	"github.com/sjsanc/pacviz/v3/internal/ui/styles"
+)
+
+// RenderTreePane renders a dependency tree with the cursor line highlighted.
+// Like the output pane it always returns exactly height rows.
+func RenderTreePane(tree *domain.DependencyTree, cursor, offset, height, width int) (string, int) {
+	if tree == nil || height < 2 {
+		return "", 0
+	}
+
+	titleStyle := lipgloss.NewStyle().
+		Foreground(styles.Current.Background).
+		Background(styles.Current.Accent1).
+		Bold(true).
+		Width(width)
+
+	rowStyle := lipgloss.NewStyle().
+		Foreground(styles.Current.Foreground).
+		Background(styles.Current.Selected).
+		Width(width)
+
+	cursorStyle := rowStyle.
+		Foreground(styles.Current.Background).
+		Background(styles.Current.Accent2).
+		Bold(true)
+
+	bodyRows := height - 1
+
+	title := fmt.Sprintf("  %s: %s", tree.Direction, tree.Root)
+	if tree.MaxDepth > 0 {
+		title += fmt.Sprintf(" (depth ≤ %d)", tree.MaxDepth)
+	}
+	if len(tree.Nodes) > 0 {
+		end := min(offset+bodyRows, len(tree.Nodes))
+		title += fmt.Sprintf(" [%d-%d/%d]", offset+1, end, len(tree.Nodes))
+	}
+
+	rendered := []string{titleStyle.Render(title)}
+	for i := 0; i < bodyRows; i++ {
+		idx := offset + i
+		if idx < 0 || idx >= len(tree.Nodes) {
+			rendered = append(rendered, rowStyle.Render(""))
+			continue
+		}
+
+		line := truncateLine("  "+formatTreeNode(tree.Nodes[idx]), width)
+		if idx == cursor {
+			rendered = append(rendered, cursorStyle.Render(line))
+		} else {
+			rendered = append(rendered, rowStyle.Render(line))
+		}
+	}
+
+	return lipgloss.JoinVertical(lipgloss.Left, rendered...), height
+}
+
+func formatTreeNode(node domain.TreeNode) string {
+	marker := "  "
+	switch {
+	case node.Expanded:
+		marker = "▾ "
+	case node.HasChildren && !node.Cycle && !node.Repeat:
+		marker = "▸ "
+	}
+
+	line := node.Prefix + marker + node.Name
+	if node.Cycle {
+		line += " ↻ (cycle)"
+	} else if node.Repeat {
+		line += " (shown above)"
+	} else if node.Truncated {
+		line += " …"
+	}
+	return line
+}
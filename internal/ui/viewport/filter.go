@@ -1,28 +1,28 @@
 package viewport
 
 import (
-	"strings"
+	"time"
 
 	"github.com/sjsanc/pacviz/v3/internal/domain"
-	"github.com/sjsanc/pacviz/v3/internal/ui/column"
 )
 
-// ApplyFilter filters rows by a simple text term (case-insensitive substring match).
-func (v *Viewport) ApplyFilter(term string) {
+// ApplyFilter filters rows by a query as described by domain.ParseQuery.
+// An invalid query leaves the rows as they were and returns the parse error.
+func (v *Viewport) ApplyFilter(term string) error {
 	if term == "" {
 		v.VisibleRows = v.AllRows
 		v.Filter = domain.FilterState{Active: false}
-		return
+		return nil
 	}
 
-	filtered := make([]*domain.Row, 0, len(v.AllRows))
-	lowerTerm := strings.ToLower(term)
+	query, err := domain.ParseQuery(term, time.Local)
+	if err != nil {
+		return err
+	}
 
+	filtered := make([]*domain.Row, 0, len(v.AllRows))
 	for _, row := range v.AllRows {
-		name := strings.ToLower(row.Cells[column.ColName])
-		desc := strings.ToLower(row.Cells[column.ColDescription])
-
-		if strings.Contains(name, lowerTerm) || strings.Contains(desc, lowerTerm) {
+		if query.Match(row) {
 			filtered = append(filtered, row)
 		}
 	}
@@ -30,11 +30,14 @@ func (v *Viewport) ApplyFilter(term string) {
 	v.VisibleRows = filtered
 	v.Filter = domain.FilterState{
 		Active: true,
-		Terms:  []string{term},
+		Query:  term,
+		Terms:  query.Terms,
+		Column: query.Column,
 	}
 
 	v.SelectedRow = 0
 	v.Offset = 0
+	return nil
 }
 
 // ClearFilter removes all filters and restores all rows.
@@ -1,5 +1,7 @@
 package viewport
 
+import "github.com/sjsanc/pacviz/v3/internal/domain"
+
 func (v *Viewport) SelectRow(index int) {
 	if len(v.VisibleRows) == 0 {
 		v.SelectedRow = 0
@@ -114,3 +116,69 @@ func (v *Viewport) PrevColumn() {
 		v.SelectedCol = len(v.Columns) - 1
 	}
 }
+
+// ToggleMark flips the selection mark on the row under the cursor.
+func (v *Viewport) ToggleMark() {
+	if v.SelectedRow < 0 || v.SelectedRow >= len(v.VisibleRows) {
+		return
+	}
+	row := v.VisibleRows[v.SelectedRow]
+	row.Selected = !row.Selected
+}
+
+// MarkAllVisible marks every row that passes the current preset and filter.
+func (v *Viewport) MarkAllVisible() {
+	for _, row := range v.VisibleRows {
+		row.Selected = true
+	}
+}
+
+// InvertMarks flips the mark on every visible row.
+func (v *Viewport) InvertMarks() {
+	for _, row := range v.VisibleRows {
+		row.Selected = !row.Selected
+	}
+}
+
+// ClearMarks unmarks all rows, including those hidden by filters.
+func (v *Viewport) ClearMarks() {
+	for _, row := range v.AllRows {
+		row.Selected = false
+	}
+}
+
+// MarkedPackages returns the packages of the marked visible rows in sort
+// order. Marks on rows a filter or preset hides are kept but ignored until
+// the rows are shown again, so actions never reach packages out of sight.
+func (v *Viewport) MarkedPackages() []*domain.Package {
+	var packages []*domain.Package
+	for _, row := range v.VisibleRows {
+		if row.Selected && row.Package != nil {
+			packages = append(packages, row.Package)
+		}
+	}
+	return packages
+}
+
+// MarkedCount returns the number of marked visible rows.
+func (v *Viewport) MarkedCount() int {
+	count := 0
+	for _, row := range v.VisibleRows {
+		if row.Selected {
+			count++
+		}
+	}
+	return count
+}
+
+// SelectPackage moves the selection to the visible row holding the named
+// package and centers it. It reports whether such a row exists.
+func (v *Viewport) SelectPackage(name string) bool {
+	for i, row := range v.VisibleRows {
+		if row.Package != nil && row.Package.Name == name {
+			v.ScrollToLine(i)
+			return true
+		}
+	}
+	return false
+}
@@ -209,3 +209,50 @@ func TestPageUpDown(t *testing.T) {
 		t.Errorf("Expected SelectedRow to be 20 after PageUp, got %d", v.SelectedRow)
 	}
 }
+
+func TestMarks(t *testing.T) {
+	v := New()
+	v.Height = 10
+
+	rows := make([]*domain.Row, 5)
+	for i := range rows {
+		rows[i] = &domain.Row{
+			Package: &domain.Package{Name: string(rune('a' + i))},
+			Cells:   make(map[column.Type]string),
+		}
+	}
+	v.SetRows(rows)
+
+	v.ToggleMark()
+	if v.MarkedCount() != 1 || !v.VisibleRows[0].Selected {
+		t.Errorf("Expected first row to be marked, got %d marked", v.MarkedCount())
+	}
+
+	v.InvertMarks()
+	if v.MarkedCount() != 4 || v.VisibleRows[0].Selected {
+		t.Errorf("Expected 4 marked rows after invert, got %d", v.MarkedCount())
+	}
+
+	// Only rows passing the filter are marked
+	v.VisibleRows = v.VisibleRows[:2]
+	v.ClearMarks()
+	v.MarkAllVisible()
+	if v.MarkedCount() != 2 {
+		t.Errorf("Expected 2 marked rows, got %d", v.MarkedCount())
+	}
+
+	pkgs := v.MarkedPackages()
+	if len(pkgs) != 2 || pkgs[0].Name != "a" || pkgs[1].Name != "b" {
+		t.Errorf("Unexpected marked packages: %v", pkgs)
+	}
+
+	// Marks on hidden rows are ignored until the rows are shown again
+	v.VisibleRows = v.AllRows[1:2]
+	if pkgs := v.MarkedPackages(); len(pkgs) != 1 || pkgs[0].Name != "b" || v.MarkedCount() != 1 {
+		t.Errorf("Expected only the visible mark, got %v", pkgs)
+	}
+	v.VisibleRows = v.AllRows
+	if v.MarkedCount() != 2 {
+		t.Errorf("Expected the hidden mark to come back, got %d marked", v.MarkedCount())
+	}
+}